@@ -11,6 +11,7 @@ const (
 	NotaryName      = "notary"
 	ClairName       = "clair"
 	ChartMuseumName = "chartmuseum"
+	MonitoringName  = "monitoring"
 )
 
 func (h *Harbor) NormalizeComponentName(componentName string) string {
@@ -0,0 +1,144 @@
+package v1alpha1
+
+import (
+	certv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha2"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	DefaultRSAKeySize   = 4096
+	DefaultECDSAKeySize = 256
+
+	MinRSAKeySize = 2048
+	MaxRSAKeySize = 8192
+)
+
+var ecdsaKeySizes = []int{256, 384, 521}
+
+// GetKeyAlgorithm returns the private key algorithm, defaulting to rsa.
+func (c *CertificateSettings) GetKeyAlgorithm() certv1.KeyAlgorithm {
+	if c == nil || c.KeyAlgorithm == "" {
+		return certv1.RSAKeyAlgorithm
+	}
+
+	return c.KeyAlgorithm
+}
+
+// GetKeySize returns the private key size, defaulting according to the algorithm.
+func (c *CertificateSettings) GetKeySize() int {
+	if c != nil && c.KeySize != 0 {
+		return c.KeySize
+	}
+
+	if c.GetKeyAlgorithm() == certv1.ECDSAKeyAlgorithm {
+		return DefaultECDSAKeySize
+	}
+
+	return DefaultRSAKeySize
+}
+
+// ApplyTo sets the certificate settings on the cert-manager certificate spec.
+func (c *CertificateSettings) ApplyTo(spec *certv1.CertificateSpec) {
+	spec.KeyAlgorithm = c.GetKeyAlgorithm()
+	spec.KeySize = c.GetKeySize()
+
+	if c == nil {
+		return
+	}
+
+	spec.Duration = c.Duration
+	spec.RenewBefore = c.RenewBefore
+	spec.DNSNames = append(spec.DNSNames, c.DNSNames...)
+}
+
+// Validate checks the settings against the algorithms supported by the consuming component.
+func (c *CertificateSettings) Validate(path *field.Path, algorithms ...certv1.KeyAlgorithm) field.ErrorList {
+	var errs field.ErrorList
+
+	if c == nil {
+		return errs
+	}
+
+	algorithm := c.GetKeyAlgorithm()
+
+	supported := false
+
+	for _, a := range algorithms {
+		if a == algorithm {
+			supported = true
+		}
+	}
+
+	if !supported {
+		errs = append(errs, field.NotSupported(path.Child("keyAlgorithm"), algorithm, keyAlgorithmsToStrings(algorithms)))
+	}
+
+	switch algorithm {
+	case certv1.RSAKeyAlgorithm:
+		if size := c.GetKeySize(); size < MinRSAKeySize || size > MaxRSAKeySize {
+			errs = append(errs, field.Invalid(path.Child("keySize"), c.KeySize, "must be between 2048 and 8192 for rsa keys"))
+		}
+	case certv1.ECDSAKeyAlgorithm:
+		if !isValidECDSAKeySize(c.GetKeySize()) {
+			errs = append(errs, field.NotSupported(path.Child("keySize"), c.KeySize, []string{"256", "384", "521"}))
+		}
+	}
+
+	if c.Duration != nil && c.Duration.Duration < certv1.MinimumCertificateDuration {
+		errs = append(errs, field.Invalid(path.Child("duration"), c.Duration.Duration.String(), "must be at least 1h"))
+	}
+
+	if c.RenewBefore != nil {
+		if c.RenewBefore.Duration < certv1.MinimumRenewBefore {
+			errs = append(errs, field.Invalid(path.Child("renewBefore"), c.RenewBefore.Duration.String(), "must be at least 5m"))
+		}
+
+		duration := certv1.DefaultCertificateDuration
+		if c.Duration != nil {
+			duration = c.Duration.Duration
+		}
+
+		if c.RenewBefore.Duration >= duration {
+			errs = append(errs, field.Invalid(path.Child("renewBefore"), c.RenewBefore.Duration.String(), "must be shorter than the duration"))
+		}
+	}
+
+	for i, dnsName := range c.DNSNames {
+		for _, msg := range validation.IsDNS1123Subdomain(dnsName) {
+			errs = append(errs, field.Invalid(path.Child("dnsNames").Index(i), dnsName, msg))
+		}
+	}
+
+	return errs
+}
+
+// Validate checks the certificate settings of every certificate.
+func (c *HarborCertificates) Validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	errs = append(errs, c.RegistryToken.Validate(path.Child("registryToken"), certv1.RSAKeyAlgorithm)...)
+	errs = append(errs, c.Notary.Validate(path.Child("notary"), certv1.RSAKeyAlgorithm, certv1.ECDSAKeyAlgorithm)...)
+
+	return errs
+}
+
+func isValidECDSAKeySize(size int) bool {
+	for _, s := range ecdsaKeySizes {
+		if s == size {
+			return true
+		}
+	}
+
+	return false
+}
+
+func keyAlgorithmsToStrings(algorithms []certv1.KeyAlgorithm) []string {
+	result := make([]string, len(algorithms))
+
+	for i, algorithm := range algorithms {
+		result[i] = string(algorithm)
+	}
+
+	return result
+}
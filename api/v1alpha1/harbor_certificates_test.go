@@ -0,0 +1,59 @@
+package v1alpha1
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var _ = Describe("HarborCertificates", func() {
+	var certificates HarborCertificates
+
+	BeforeEach(func() {
+		certificates = HarborCertificates{}
+	})
+
+	validate := func() field.ErrorList {
+		return certificates.Validate(field.NewPath("spec", "certificates"))
+	}
+
+	It("Should accept default settings", func() {
+		Expect(validate()).To(BeEmpty())
+	})
+
+	It("Should reject small rsa keys", func() {
+		certificates.RegistryToken = &CertificateSettings{KeySize: 1024}
+
+		errs := validate()
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("spec.certificates.registryToken.keySize"))
+	})
+
+	It("Should reject short durations", func() {
+		certificates.Notary = &CertificateSettings{Duration: &metav1.Duration{Duration: 30 * time.Minute}}
+
+		errs := validate()
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("spec.certificates.notary.duration"))
+	})
+
+	It("Should reject short renewals", func() {
+		certificates.Notary = &CertificateSettings{RenewBefore: &metav1.Duration{Duration: time.Minute}}
+
+		errs := validate()
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("spec.certificates.notary.renewBefore"))
+	})
+
+	It("Should reject invalid dns names", func() {
+		certificates.Notary = &CertificateSettings{DNSNames: []string{"notary_example"}}
+
+		errs := validate()
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("spec.certificates.notary.dnsNames[0]"))
+	})
+})
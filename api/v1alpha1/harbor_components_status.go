@@ -0,0 +1,37 @@
+package v1alpha1
+
+// GetDesiredStateHash returns the hash of the desired state last applied for the component, empty if never applied.
+func (s *HarborStatus) GetDesiredStateHash(name string) string {
+	for _, component := range s.Components {
+		if component.Name == name {
+			return component.DesiredStateHash
+		}
+	}
+
+	return ""
+}
+
+// SetDesiredStateHash records the hash of the desired state applied for the component.
+func (s *HarborStatus) SetDesiredStateHash(name, hash string) {
+	for i, component := range s.Components {
+		if component.Name == name {
+			s.Components[i].DesiredStateHash = hash
+			return
+		}
+	}
+
+	s.Components = append(s.Components, ComponentStatus{
+		Name:             name,
+		DesiredStateHash: hash,
+	})
+}
+
+// RemoveDesiredStateHash forgets the desired state of a component, once removed.
+func (s *HarborStatus) RemoveDesiredStateHash(name string) {
+	for i, component := range s.Components {
+		if component.Name == name {
+			s.Components = append(s.Components[:i], s.Components[i+1:]...)
+			return
+		}
+	}
+}
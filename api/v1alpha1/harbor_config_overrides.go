@@ -0,0 +1,131 @@
+package v1alpha1
+
+import (
+	"encoding/json"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	ConfigOverridesMergePatchKey = "mergePatch"
+	ConfigOverridesJSONPatchKey  = "jsonPatch"
+)
+
+var jsonPatchOperations = []string{"add", "remove", "replace", "move", "copy", "test"}
+
+// ParseMergePatch converts a merge patch written in YAML or JSON to JSON.
+func ParseMergePatch(patch string) ([]byte, error) {
+	data, err := yaml.YAMLToJSON([]byte(patch))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid yaml")
+	}
+
+	var document map[string]interface{}
+
+	err = json.Unmarshal(data, &document)
+	if err != nil {
+		return nil, errors.Wrap(err, "must be an object")
+	}
+
+	return data, nil
+}
+
+// ParseJSONPatch decodes a JSON patch written in YAML or JSON.
+func ParseJSONPatch(patch string) (jsonpatch.Patch, error) {
+	data, err := yaml.YAMLToJSON([]byte(patch))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid yaml")
+	}
+
+	operations, err := jsonpatch.DecodePatch(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "must be a list of operations")
+	}
+
+	for i, operation := range operations {
+		if !isJSONPatchOperation(operation.Kind()) {
+			return nil, errors.Errorf("operation %d: unsupported op %q", i, operation.Kind())
+		}
+
+		if _, err := operation.Path(); err != nil {
+			return nil, errors.Wrapf(err, "operation %d", i)
+		}
+	}
+
+	return operations, nil
+}
+
+// Validate checks inline patches can be decoded.
+// Patches read from the configmap are checked when rendering the configuration.
+func (o *ConfigOverrides) Validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if o == nil {
+		return errs
+	}
+
+	if o.ConfigMapRef != nil {
+		if o.ConfigMapRef.Name == "" {
+			errs = append(errs, field.Required(path.Child("configMapRef", "name"), ""))
+		}
+
+		if o.MergePatch != "" || o.JSONPatch != "" {
+			errs = append(errs, field.Forbidden(path.Child("configMapRef"), "mergePatch and jsonPatch must be empty when configMapRef is set"))
+		}
+	}
+
+	if o.MergePatch != "" {
+		if _, err := ParseMergePatch(o.MergePatch); err != nil {
+			errs = append(errs, field.Invalid(path.Child("mergePatch"), o.MergePatch, err.Error()))
+		}
+	}
+
+	if o.JSONPatch != "" {
+		if _, err := ParseJSONPatch(o.JSONPatch); err != nil {
+			errs = append(errs, field.Invalid(path.Child("jsonPatch"), o.JSONPatch, err.Error()))
+		}
+	}
+
+	return errs
+}
+
+// ValidateConfigOverrides checks configuration overrides of every component.
+func (c *HarborComponents) ValidateConfigOverrides(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if c.Registry != nil {
+		errs = append(errs, c.Registry.ConfigOverrides.Validate(path.Child("registry", "configOverrides"))...)
+	}
+
+	if c.JobService != nil {
+		errs = append(errs, c.JobService.ConfigOverrides.Validate(path.Child("jobService", "configOverrides"))...)
+	}
+
+	if c.ChartMuseum != nil {
+		errs = append(errs, c.ChartMuseum.ConfigOverrides.Validate(path.Child("chartMuseum", "configOverrides"))...)
+	}
+
+	if c.Clair != nil {
+		errs = append(errs, c.Clair.ConfigOverrides.Validate(path.Child("clair", "configOverrides"))...)
+	}
+
+	if c.Notary != nil {
+		errs = append(errs, c.Notary.Server.ConfigOverrides.Validate(path.Child("notary", "server", "configOverrides"))...)
+		errs = append(errs, c.Notary.Signer.ConfigOverrides.Validate(path.Child("notary", "signer", "configOverrides"))...)
+	}
+
+	return errs
+}
+
+func isJSONPatchOperation(kind string) bool {
+	for _, operation := range jsonPatchOperations {
+		if operation == kind {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,92 @@
+package v1alpha1
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	DefaultMaxIdleConnections int32 = 50
+	DefaultMaxOpenConnections int32 = 1000
+)
+
+var postgresSSLModes = []string{
+	string(DisablePostgresSSLMode),
+	string(AllowPostgresSSLMode),
+	string(PreferPostgresSSLMode),
+	string(RequirePostgresSSLMode),
+	string(VerifyCAPostgresSSLMode),
+	string(VerifyFullPostgresSSLMode),
+}
+
+// GetMaxIdleConnections defaults to 50, within the limit of open connections.
+func (database *CoreDatabaseSpec) GetMaxIdleConnections() int32 {
+	if database.MaxIdleConnections != nil {
+		return *database.MaxIdleConnections
+	}
+
+	if maxOpen := database.GetMaxOpenConnections(); maxOpen > 0 && maxOpen < DefaultMaxIdleConnections {
+		return maxOpen
+	}
+
+	return DefaultMaxIdleConnections
+}
+
+func (database *CoreDatabaseSpec) GetMaxOpenConnections() int32 {
+	if database.MaxOpenConnections == nil {
+		return DefaultMaxOpenConnections
+	}
+
+	return *database.MaxOpenConnections
+}
+
+// GetConnectTimeout returns the connection timeout in seconds, 0 meaning no timeout.
+func (database *CoreDatabaseSpec) GetConnectTimeout() int64 {
+	if database.ConnectTimeout == nil {
+		return 0
+	}
+
+	return int64(database.ConnectTimeout.Duration / time.Second)
+}
+
+// Validate checks pool sizes, the ssl mode and the connection timeout.
+func (database *CoreDatabaseSpec) Validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if database.GetMaxIdleConnections() < 0 {
+		errs = append(errs, field.Invalid(path.Child("maxIdleConns"), database.GetMaxIdleConnections(), "must not be negative"))
+	}
+
+	if database.GetMaxOpenConnections() < 0 {
+		errs = append(errs, field.Invalid(path.Child("maxOpenConns"), database.GetMaxOpenConnections(), "must not be negative"))
+	}
+
+	if database.GetMaxOpenConnections() > 0 && database.GetMaxIdleConnections() > database.GetMaxOpenConnections() {
+		errs = append(errs, field.Invalid(path.Child("maxIdleConns"), database.GetMaxIdleConnections(), "must not exceed maxOpenConns"))
+	}
+
+	if database.SSLMode != "" && !isPostgresSSLMode(database.SSLMode) {
+		errs = append(errs, field.NotSupported(path.Child("sslMode"), database.SSLMode, postgresSSLModes))
+	}
+
+	if database.CASecretName != "" && database.SSLMode == DisablePostgresSSLMode {
+		errs = append(errs, field.Forbidden(path.Child("caSecretName"), "ssl is disabled"))
+	}
+
+	if database.ConnectTimeout != nil && database.ConnectTimeout.Duration < time.Second {
+		errs = append(errs, field.Invalid(path.Child("connectTimeout"), database.ConnectTimeout.Duration.String(), "must be at least 1s"))
+	}
+
+	return errs
+}
+
+func isPostgresSSLMode(mode PostgresSSLMode) bool {
+	for _, m := range postgresSSLModes {
+		if m == string(mode) {
+			return true
+		}
+	}
+
+	return false
+}
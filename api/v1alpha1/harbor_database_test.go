@@ -0,0 +1,88 @@
+package v1alpha1
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var _ = Describe("CoreDatabaseSpec", func() {
+	var database CoreDatabaseSpec
+
+	BeforeEach(func() {
+		database = CoreDatabaseSpec{}
+	})
+
+	validate := func() field.ErrorList {
+		return database.Validate(field.NewPath("spec", "components", "core", "database"))
+	}
+
+	connections := func(count int32) *int32 {
+		return &count
+	}
+
+	It("Should accept default settings", func() {
+		Expect(validate()).To(BeEmpty())
+	})
+
+	It("Should accept unlimited open connections", func() {
+		database.MaxIdleConnections = connections(100)
+		database.MaxOpenConnections = connections(0)
+
+		Expect(validate()).To(BeEmpty())
+	})
+
+	It("Should accept verified connections", func() {
+		database.SSLMode = VerifyFullPostgresSSLMode
+		database.CASecretName = "database-ca"
+		database.ConnectTimeout = &metav1.Duration{Duration: 10 * time.Second}
+
+		Expect(validate()).To(BeEmpty())
+	})
+
+	It("Should reject negative pool sizes", func() {
+		database.MaxOpenConnections = connections(-1)
+
+		errs := validate()
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("spec.components.core.database.maxOpenConns"))
+	})
+
+	It("Should reject more idle than open connections", func() {
+		database.MaxIdleConnections = connections(30)
+		database.MaxOpenConnections = connections(20)
+
+		errs := validate()
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("spec.components.core.database.maxIdleConns"))
+	})
+
+	It("Should reject unsupported ssl modes", func() {
+		database.SSLMode = "strict"
+
+		errs := validate()
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("spec.components.core.database.sslMode"))
+	})
+
+	It("Should reject a CA when ssl is disabled", func() {
+		database.SSLMode = DisablePostgresSSLMode
+		database.CASecretName = "database-ca"
+
+		errs := validate()
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("spec.components.core.database.caSecretName"))
+	})
+
+	It("Should reject short connection timeouts", func() {
+		database.ConnectTimeout = &metav1.Duration{Duration: 500 * time.Millisecond}
+
+		errs := validate()
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("spec.components.core.database.connectTimeout"))
+	})
+})
@@ -0,0 +1,72 @@
+package v1alpha1
+
+import (
+	"net/url"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func (expose *HarborExpose) GetType() ExposeType {
+	if expose.Type == "" {
+		return IngressExposeType
+	}
+
+	return expose.Type
+}
+
+func (expose *HarborExpose) GetRouteTermination() RouteTermination {
+	if expose.Route == nil || expose.Route.Termination == "" {
+		return EdgeRouteTermination
+	}
+
+	return expose.Route.Termination
+}
+
+// ValidateExpose checks that routes and backends agree on TLS:
+// routes re-encrypt connections to backends serving internal TLS, and only to them.
+func (s *HarborSpec) ValidateExpose(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if s.Expose.GetType() != RouteExposeType {
+		return errs
+	}
+
+	termination := s.Expose.GetRouteTermination()
+	terminationPath := path.Child("expose", "route", "termination")
+
+	if !s.InternalTLS.IsEnabled() {
+		if termination == ReencryptRouteTermination {
+			errs = append(errs, field.Invalid(terminationPath, termination, "requires internalTLS.enabled"))
+		}
+
+		return errs
+	}
+
+	if termination != ReencryptRouteTermination {
+		errs = append(errs, field.Invalid(terminationPath, termination, "must be reencrypt when internalTLS.enabled"))
+	}
+
+	errs = append(errs, validateRoutePublicURL(path.Child("publicURL"), s.PublicURL)...)
+
+	if s.Components.Notary != nil {
+		errs = append(errs, validateRoutePublicURL(path.Child("components", "notary", "publicURL"), s.Components.Notary.PublicURL)...)
+	}
+
+	return errs
+}
+
+// validateRoutePublicURL checks routes in front of backends serving internal TLS are secured.
+func validateRoutePublicURL(path *field.Path, publicURL string) field.ErrorList {
+	var errs field.ErrorList
+
+	u, err := url.Parse(publicURL)
+	if err != nil {
+		return append(errs, field.Invalid(path, publicURL, err.Error()))
+	}
+
+	if u.Scheme != "https" {
+		errs = append(errs, field.Invalid(path, publicURL, "must use https when internalTLS.enabled"))
+	}
+
+	return errs
+}
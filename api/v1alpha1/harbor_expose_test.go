@@ -0,0 +1,64 @@
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var _ = Describe("HarborExpose", func() {
+	var spec HarborSpec
+
+	BeforeEach(func() {
+		spec = HarborSpec{
+			PublicURL: "https://registry.example.com",
+			Expose: HarborExpose{
+				Type:  RouteExposeType,
+				Route: &RouteExpose{Termination: ReencryptRouteTermination},
+			},
+		}
+	})
+
+	validate := func() field.ErrorList {
+		return spec.ValidateExpose(field.NewPath("spec"))
+	}
+
+	It("Should accept the reencrypt termination with internal TLS", func() {
+		spec.InternalTLS.Enabled = true
+
+		Expect(validate()).To(BeEmpty())
+	})
+
+	It("Should reject the reencrypt termination without internal TLS", func() {
+		errs := validate()
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("spec.expose.route.termination"))
+	})
+
+	It("Should reject the edge termination with internal TLS", func() {
+		spec.InternalTLS.Enabled = true
+		spec.Expose.Route = nil
+
+		errs := validate()
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("spec.expose.route.termination"))
+	})
+
+	It("Should reject http public URLs with internal TLS", func() {
+		spec.InternalTLS.Enabled = true
+		spec.Components.Notary = &NotaryComponent{PublicURL: "http://notary.example.com"}
+
+		errs := validate()
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("spec.components.notary.publicURL"))
+	})
+
+	It("Should not check ingresses", func() {
+		spec.InternalTLS.Enabled = true
+		spec.PublicURL = "http://registry.example.com"
+		spec.Expose = HarborExpose{Type: IngressExposeType}
+
+		Expect(validate()).To(BeEmpty())
+	})
+})
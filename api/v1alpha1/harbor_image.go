@@ -1,8 +1,94 @@
 package v1alpha1
 
+import (
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Keys of the default images of components.
+const (
+	CoreImageKey               = "core"
+	ChartMuseumImageKey        = "chartmuseum"
+	ClairImageKey              = "clair"
+	ClairAdapterImageKey       = "clair-adapter"
+	JobServiceImageKey         = "jobservice"
+	NotaryServerImageKey       = "notary-server"
+	NotarySignerImageKey       = "notary-signer"
+	NotaryDBMigratorImageKey   = "notary-db-migrator"
+	PortalImageKey             = "portal"
+	RegistryImageKey           = "registry"
+	RegistryControllerImageKey = "registry-controller"
+)
+
+var builtinImages = map[string]string{
+	CoreImageKey:               "goharbor/harbor-core:v1.10.0",
+	ChartMuseumImageKey:        "goharbor/chartmuseum-photon:v0.9.0-v1.10.0",
+	ClairImageKey:              "goharbor/clair-photon:v2.1.1-v1.10.0",
+	ClairAdapterImageKey:       "holyhope/clair-adapter-with-config:v1.10.0", // Use "goharbor/clair-adapter-photon:v1.0.1-v1.10.0" when possible
+	JobServiceImageKey:         "goharbor/harbor-jobservice:v1.10.0",
+	NotaryServerImageKey:       "goharbor/notary-server-photon:v0.6.1-v1.10.0",
+	NotarySignerImageKey:       "goharbor/notary-signer-photon:v0.6.1-v1.10.0",
+	NotaryDBMigratorImageKey:   "jmonsinjon/notary-db-migrator:v0.6.1",
+	PortalImageKey:             "goharbor/harbor-portal:v1.10.0",
+	RegistryImageKey:           "goharbor/registry-photon:v2.7.1-patch-2819-2553-v1.10.0",
+	RegistryControllerImageKey: "goharbor/harbor-registryctl:v1.10.0",
+}
+
+var (
+	defaultImagesLock sync.RWMutex
+	defaultImages     = builtinImages
+)
+
+// GetDefaultImage returns the image of the component when its image is not set.
+func GetDefaultImage(key string) string {
+	defaultImagesLock.RLock()
+	defer defaultImagesLock.RUnlock()
+
+	return defaultImages[key]
+}
+
+// GetDefaultImageKeys returns the keys of the default images, sorted.
+func GetDefaultImageKeys() []string {
+	keys := make([]string, 0, len(builtinImages))
+	for key := range builtinImages {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// SetDefaultImages overrides the built-in default images by key. Previous overrides are discarded.
+func SetDefaultImages(overrides map[string]string) error {
+	images := make(map[string]string, len(builtinImages))
+	for key, image := range builtinImages {
+		images[key] = image
+	}
+
+	for key, image := range overrides {
+		if _, ok := builtinImages[key]; !ok {
+			return errors.Errorf("unknown image %q", key)
+		}
+
+		if image != "" {
+			images[key] = image
+		}
+	}
+
+	defaultImagesLock.Lock()
+	defer defaultImagesLock.Unlock()
+
+	defaultImages = images
+
+	return nil
+}
+
 func (component *CoreComponent) GetImage() string {
 	if component.Image == nil {
-		return "goharbor/harbor-core:v1.10.0"
+		return GetDefaultImage(CoreImageKey)
 	}
 
 	return *component.Image
@@ -10,7 +96,7 @@ func (component *CoreComponent) GetImage() string {
 
 func (component *ChartMuseumComponent) GetImage() string {
 	if component.Image == nil {
-		return "goharbor/chartmuseum-photon:v0.9.0-v1.10.0"
+		return GetDefaultImage(ChartMuseumImageKey)
 	}
 
 	return *component.Image
@@ -18,7 +104,7 @@ func (component *ChartMuseumComponent) GetImage() string {
 
 func (component *ClairComponent) GetImage() string {
 	if component.Image == nil {
-		return "goharbor/clair-photon:v2.1.1-v1.10.0"
+		return GetDefaultImage(ClairImageKey)
 	}
 
 	return *component.Image
@@ -26,7 +112,7 @@ func (component *ClairComponent) GetImage() string {
 
 func (component *ClairAdapterComponent) GetImage() string {
 	if component.Image == nil {
-		return "holyhope/clair-adapter-with-config:v1.10.0" // Use "goharbor/clair-adapter-photon:v1.0.1-v1.10.0" when possible
+		return GetDefaultImage(ClairAdapterImageKey)
 	}
 
 	return *component.Image
@@ -34,14 +120,14 @@ func (component *ClairAdapterComponent) GetImage() string {
 
 func (component *JobServiceComponent) GetImage() string {
 	if component.Image == nil {
-		return "goharbor/harbor-jobservice:v1.10.0"
+		return GetDefaultImage(JobServiceImageKey)
 	}
 
 	return *component.Image
 }
 func (component *NotaryServerComponent) GetImage() string {
 	if component.Image == nil {
-		return "goharbor/notary-server-photon:v0.6.1-v1.10.0"
+		return GetDefaultImage(NotaryServerImageKey)
 	}
 
 	return *component.Image
@@ -49,14 +135,14 @@ func (component *NotaryServerComponent) GetImage() string {
 
 func (component *NotarySignerComponent) GetImage() string {
 	if component.Image == nil {
-		return "goharbor/notary-signer-photon:v0.6.1-v1.10.0"
+		return GetDefaultImage(NotarySignerImageKey)
 	}
 
 	return *component.Image
 }
 func (component *NotaryDBMigrator) GetImage() string {
 	if component.Image == nil {
-		return "jmonsinjon/notary-db-migrator:v0.6.1"
+		return GetDefaultImage(NotaryDBMigratorImageKey)
 	}
 
 	return *component.Image
@@ -64,7 +150,7 @@ func (component *NotaryDBMigrator) GetImage() string {
 
 func (component *PortalComponent) GetImage() string {
 	if component.Image == nil {
-		return "goharbor/harbor-portal:v1.10.0"
+		return GetDefaultImage(PortalImageKey)
 	}
 
 	return *component.Image
@@ -72,7 +158,7 @@ func (component *PortalComponent) GetImage() string {
 
 func (component *RegistryComponent) GetImage() string {
 	if component.Image == nil {
-		return "goharbor/registry-photon:v2.7.1-patch-2819-2553-v1.10.0"
+		return GetDefaultImage(RegistryImageKey)
 	}
 
 	return *component.Image
@@ -80,7 +166,7 @@ func (component *RegistryComponent) GetImage() string {
 
 func (component *RegistryControllerComponent) GetImage() string {
 	if component.Image == nil {
-		return "goharbor/harbor-registryctl:v1.10.0"
+		return GetDefaultImage(RegistryControllerImageKey)
 	}
 
 	return *component.Image
@@ -0,0 +1,123 @@
+package v1alpha1
+
+import (
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// MinInternalTLSHarborVersion is the first major version of Harbor supporting internal TLS.
+const MinInternalTLSHarborVersion = 2
+
+func (tls *InternalTLSSpec) IsEnabled() bool {
+	return tls != nil && tls.Enabled
+}
+
+// GetScheme returns the URL scheme used to reach Harbor components.
+func (tls *InternalTLSSpec) GetScheme() string {
+	if tls.IsEnabled() {
+		return "https"
+	}
+
+	return "http"
+}
+
+type componentImage struct {
+	path  *field.Path
+	image string
+}
+
+// ValidateInternalTLS checks the version and the images of components support internal TLS.
+// Images whose tag does not end with a Harbor release, such as custom builds, are not checked.
+func (s *HarborSpec) ValidateInternalTLS(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if !s.InternalTLS.IsEnabled() {
+		return errs
+	}
+
+	if major, ok := getMajorVersion(s.HarborVersion); ok && major < MinInternalTLSHarborVersion {
+		errs = append(errs, field.Invalid(path.Child("version"), s.HarborVersion, "internal TLS requires Harbor 2.0 or later"))
+	}
+
+	components := path.Child("components")
+
+	var images []componentImage
+
+	if s.Components.Core != nil {
+		images = append(images, componentImage{components.Child("core", "image"), s.Components.Core.GetImage()})
+	}
+
+	if s.Components.Portal != nil {
+		images = append(images, componentImage{components.Child("portal", "image"), s.Components.Portal.GetImage()})
+	}
+
+	if s.Components.Registry != nil {
+		images = append(images,
+			componentImage{components.Child("registry", "image"), s.Components.Registry.GetImage()},
+			componentImage{components.Child("registry", "controller", "image"), s.Components.Registry.Controller.GetImage()})
+	}
+
+	if s.Components.JobService != nil {
+		images = append(images, componentImage{components.Child("jobService", "image"), s.Components.JobService.GetImage()})
+	}
+
+	if s.Components.ChartMuseum != nil {
+		images = append(images, componentImage{components.Child("chartMuseum", "image"), s.Components.ChartMuseum.GetImage()})
+	}
+
+	if s.Components.Clair != nil {
+		images = append(images,
+			componentImage{components.Child("clair", "image"), s.Components.Clair.GetImage()},
+			componentImage{components.Child("clair", "adapter", "image"), s.Components.Clair.Adapter.GetImage()})
+	}
+
+	if s.Components.Notary != nil {
+		images = append(images, componentImage{components.Child("notary", "server", "image"), s.Components.Notary.Server.GetImage()})
+	}
+
+	for _, image := range images {
+		if major, ok := getImageMajorVersion(image.image); ok && major < MinInternalTLSHarborVersion {
+			errs = append(errs, field.Invalid(image.path, image.image, "internal TLS requires images of Harbor 2.0 or later"))
+		}
+	}
+
+	return errs
+}
+
+// getImageMajorVersion returns the major version of the Harbor release ending the tag of the image,
+// such as 1 for goharbor/harbor-core:v1.10.0 or goharbor/registry-photon:v2.7.1-patch-2819-2553-v1.10.0.
+func getImageMajorVersion(image string) (int, bool) {
+	if strings.Contains(image, "@") {
+		return 0, false
+	}
+
+	i := strings.LastIndex(image, ":")
+	if i < 0 || strings.Contains(image[i:], "/") {
+		return 0, false
+	}
+
+	tag := image[i+1:]
+	if j := strings.LastIndex(tag, "-v"); j >= 0 {
+		tag = tag[j+1:]
+	}
+
+	if !strings.HasPrefix(tag, "v") {
+		return 0, false
+	}
+
+	return getMajorVersion(tag)
+}
+
+// getMajorVersion returns the major version of a semver version, with or without the v prefix.
+func getMajorVersion(version string) (int, bool) {
+	major := strings.SplitN(strings.TrimPrefix(version, "v"), ".", 2)[0]
+
+	value, err := strconv.Atoi(major)
+	if err != nil {
+		return 0, false
+	}
+
+	return value, true
+}
@@ -0,0 +1,65 @@
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var _ = Describe("InternalTLS", func() {
+	var spec HarborSpec
+
+	BeforeEach(func() {
+		spec = HarborSpec{
+			HarborVersion: "1.10.0",
+			InternalTLS:   InternalTLSSpec{Enabled: true},
+			Components: HarborComponents{
+				Core:   &CoreComponent{},
+				Portal: &PortalComponent{},
+			},
+		}
+	})
+
+	validate := func() field.ErrorList {
+		return spec.ValidateInternalTLS(field.NewPath("spec"))
+	}
+
+	It("Should reject Harbor 1 and its default images", func() {
+		errs := validate()
+		Expect(errs).To(HaveLen(3))
+		Expect(errs[0].Field).To(Equal("spec.version"))
+		Expect(errs[1].Field).To(Equal("spec.components.core.image"))
+		Expect(errs[2].Field).To(Equal("spec.components.portal.image"))
+	})
+
+	It("Should accept Harbor 2 images and custom tags", func() {
+		core, portal := "goharbor/harbor-core:v2.0.0", "registry.example.com:5000/harbor-portal:dev"
+
+		spec.HarborVersion = "2.0.0"
+		spec.Components.Core.Image = &core
+		spec.Components.Portal.Image = &portal
+
+		Expect(validate()).To(BeEmpty())
+	})
+
+	It("Should check the release ending the tag", func() {
+		registry := "goharbor/registry-photon:v2.7.1-patch-2819-2553-v1.10.0"
+
+		spec.HarborVersion = "2.0.0"
+		spec.Components = HarborComponents{
+			Registry: &RegistryComponent{HarborDeployment: HarborDeployment{Image: &registry}},
+		}
+
+		errs := validate()
+		Expect(errs).To(HaveLen(2))
+		Expect(errs[0].Field).To(Equal("spec.components.registry.image"))
+		Expect(errs[1].Field).To(Equal("spec.components.registry.controller.image"))
+	})
+
+	It("Should not check harbors without internal TLS", func() {
+		spec.InternalTLS.Enabled = false
+
+		Expect(validate()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,25 @@
+package v1alpha1
+
+func (deployment *HarborDeployment) GetLogLevel() LogLevel {
+	if deployment.LogLevel == "" {
+		return InfoLogLevel
+	}
+
+	return deployment.LogLevel
+}
+
+func (component *RegistryComponent) GetLogFormat() LogFormat {
+	if component.LogFormat == "" {
+		return JSONLogFormat
+	}
+
+	return component.LogFormat
+}
+
+func (component *ChartMuseumComponent) GetLogFormat() LogFormat {
+	if component.LogFormat == "" {
+		return JSONLogFormat
+	}
+
+	return component.LogFormat
+}
@@ -0,0 +1,75 @@
+package v1alpha1
+
+import (
+	"regexp"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var (
+	metricsComponents = []string{
+		string(CoreMetricsComponent),
+		string(JobServiceMetricsComponent),
+	}
+
+	monitoringIntervalRegexp = regexp.MustCompile("^[0-9]+(ms|s|m|h)$")
+)
+
+// IsEnabled returns true if the metrics of the component are exposed.
+// Registry metrics are always exposed when monitoring is enabled.
+func (m *HarborMonitoring) IsEnabled(component MetricsComponent) bool {
+	if m == nil {
+		return false
+	}
+
+	for _, c := range m.Components {
+		if c == component {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AreRulesDisabled returns true if the default alerting rules must not be created.
+func (m *HarborMonitoring) AreRulesDisabled() bool {
+	return m != nil && m.DisableRules != nil && *m.DisableRules
+}
+
+// Validate checks the interval and that monitored components are deployed.
+func (m *HarborMonitoring) Validate(path *field.Path, components *HarborComponents) field.ErrorList {
+	var errs field.ErrorList
+
+	if m == nil {
+		return errs
+	}
+
+	if m.Interval != "" && !monitoringIntervalRegexp.MatchString(m.Interval) {
+		errs = append(errs, field.Invalid(path.Child("interval"), m.Interval, "must be a duration like 30s or 1m"))
+	}
+
+	seen := map[MetricsComponent]bool{}
+
+	for i, component := range m.Components {
+		switch component {
+		case CoreMetricsComponent:
+			if components.Core == nil {
+				errs = append(errs, field.Invalid(path.Child("components").Index(i), component, "core is not deployed"))
+			}
+		case JobServiceMetricsComponent:
+			if components.JobService == nil {
+				errs = append(errs, field.Invalid(path.Child("components").Index(i), component, "jobservice is not deployed"))
+			}
+		default:
+			errs = append(errs, field.NotSupported(path.Child("components").Index(i), component, metricsComponents))
+		}
+
+		if seen[component] {
+			errs = append(errs, field.Duplicate(path.Child("components").Index(i), component))
+		}
+
+		seen[component] = true
+	}
+
+	return errs
+}
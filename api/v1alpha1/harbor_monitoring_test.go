@@ -0,0 +1,63 @@
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var _ = Describe("HarborMonitoring", func() {
+	var monitoring *HarborMonitoring
+
+	components := &HarborComponents{
+		Core: &CoreComponent{},
+	}
+
+	BeforeEach(func() {
+		monitoring = &HarborMonitoring{}
+	})
+
+	validate := func() field.ErrorList {
+		return monitoring.Validate(field.NewPath("spec", "monitoring"), components)
+	}
+
+	It("Should accept deployed components", func() {
+		monitoring.Interval = "30s"
+		monitoring.Components = []MetricsComponent{CoreMetricsComponent}
+
+		Expect(validate()).To(BeEmpty())
+	})
+
+	It("Should reject intervals without unit", func() {
+		monitoring.Interval = "30"
+
+		errs := validate()
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("spec.monitoring.interval"))
+	})
+
+	It("Should reject components not deployed", func() {
+		monitoring.Components = []MetricsComponent{JobServiceMetricsComponent}
+
+		errs := validate()
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("spec.monitoring.components[0]"))
+	})
+
+	It("Should reject unsupported components", func() {
+		monitoring.Components = []MetricsComponent{RegistryName}
+
+		errs := validate()
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("spec.monitoring.components[0]"))
+	})
+
+	It("Should reject duplicated components", func() {
+		monitoring.Components = []MetricsComponent{CoreMetricsComponent, CoreMetricsComponent}
+
+		errs := validate()
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("spec.monitoring.components[1]"))
+	})
+})
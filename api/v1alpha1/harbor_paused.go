@@ -0,0 +1,63 @@
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var pausableComponents = []string{
+	CoreName,
+	PortalName,
+	RegistryName,
+	JobServiceName,
+	ChartMuseumName,
+	ClairName,
+	NotaryName,
+	MonitoringName,
+}
+
+// IsComponentPaused returns true if the resources of the component must not be changed,
+// because the Harbor or the component is paused.
+func (h *Harbor) IsComponentPaused(name string) bool {
+	if h.Spec.Paused {
+		return true
+	}
+
+	for _, component := range h.Spec.PausedComponents {
+		if string(component) == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidatePausedComponents checks paused components are supported and listed once.
+func (s *HarborSpec) ValidatePausedComponents(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	seen := map[PausableComponent]bool{}
+
+	for i, component := range s.PausedComponents {
+		if !isPausableComponent(component) {
+			errs = append(errs, field.NotSupported(path.Index(i), component, pausableComponents))
+		}
+
+		if seen[component] {
+			errs = append(errs, field.Duplicate(path.Index(i), component))
+		}
+
+		seen[component] = true
+	}
+
+	return errs
+}
+
+func isPausableComponent(component PausableComponent) bool {
+	for _, name := range pausableComponents {
+		if name == string(component) {
+			return true
+		}
+	}
+
+	return false
+}
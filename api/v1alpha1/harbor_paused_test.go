@@ -0,0 +1,34 @@
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var _ = Describe("PausedComponents", func() {
+	var spec HarborSpec
+
+	BeforeEach(func() {
+		spec = HarborSpec{}
+	})
+
+	validate := func() field.ErrorList {
+		return spec.ValidatePausedComponents(field.NewPath("spec", "pausedComponents"))
+	}
+
+	It("Should accept supported components", func() {
+		spec.PausedComponents = []PausableComponent{CoreName, NotaryName, MonitoringName}
+
+		Expect(validate()).To(BeEmpty())
+	})
+
+	It("Should reject duplicated components", func() {
+		spec.PausedComponents = []PausableComponent{CoreName, CoreName}
+
+		errs := validate()
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("spec.pausedComponents[1]"))
+	})
+})
@@ -0,0 +1,97 @@
+package v1alpha1
+
+import (
+	"net/url"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var proxyComponents = []string{
+	string(CoreProxyComponent),
+	string(JobServiceProxyComponent),
+	string(ClairProxyComponent),
+	string(RegistryProxyComponent),
+	string(ChartMuseumProxyComponent),
+}
+
+// IsEnabled returns true if the component uses the proxy.
+func (proxy *HarborProxy) IsEnabled(component ProxyComponent) bool {
+	if proxy == nil {
+		return false
+	}
+
+	for _, c := range proxy.Components {
+		if c == component {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Validate checks proxy urls and components.
+func (proxy *HarborProxy) Validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if proxy == nil {
+		return errs
+	}
+
+	errs = append(errs, validateProxyURL(path.Child("httpProxy"), proxy.HTTPProxy)...)
+	errs = append(errs, validateProxyURL(path.Child("httpsProxy"), proxy.HTTPSProxy)...)
+
+	seen := map[ProxyComponent]bool{}
+
+	for i, component := range proxy.Components {
+		if !isProxyComponent(component) {
+			errs = append(errs, field.NotSupported(path.Child("components").Index(i), component, proxyComponents))
+		}
+
+		if seen[component] {
+			errs = append(errs, field.Duplicate(path.Child("components").Index(i), component))
+		}
+
+		seen[component] = true
+	}
+
+	for i, host := range proxy.NoProxy {
+		if host == "" {
+			errs = append(errs, field.Invalid(path.Child("noProxy").Index(i), host, "must not be empty"))
+		}
+	}
+
+	return errs
+}
+
+func validateProxyURL(path *field.Path, proxyURL string) field.ErrorList {
+	var errs field.ErrorList
+
+	if proxyURL == "" {
+		return errs
+	}
+
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return append(errs, field.Invalid(path, proxyURL, err.Error()))
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		errs = append(errs, field.Invalid(path, proxyURL, "scheme must be http or https"))
+	}
+
+	if u.Host == "" {
+		errs = append(errs, field.Invalid(path, proxyURL, "host is required"))
+	}
+
+	return errs
+}
+
+func isProxyComponent(component ProxyComponent) bool {
+	for _, c := range proxyComponents {
+		if c == string(component) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,69 @@
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var _ = Describe("HarborProxy", func() {
+	var proxy *HarborProxy
+
+	BeforeEach(func() {
+		proxy = &HarborProxy{}
+	})
+
+	validate := func() field.ErrorList {
+		return proxy.Validate(field.NewPath("spec", "proxy"))
+	}
+
+	It("Should accept proxies of supported components", func() {
+		proxy.HTTPProxy = "http://proxy:3128"
+		proxy.HTTPSProxy = "https://proxy:3129"
+		proxy.NoProxy = []string{".example.com"}
+		proxy.Components = []ProxyComponent{CoreProxyComponent, ClairProxyComponent}
+
+		Expect(validate()).To(BeEmpty())
+	})
+
+	It("Should reject unsupported schemes", func() {
+		proxy.HTTPProxy = "socks5://proxy:1080"
+
+		errs := validate()
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("spec.proxy.httpProxy"))
+	})
+
+	It("Should reject urls without host", func() {
+		proxy.HTTPSProxy = "http://"
+
+		errs := validate()
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("spec.proxy.httpsProxy"))
+	})
+
+	It("Should reject unsupported components", func() {
+		proxy.Components = []ProxyComponent{PortalName}
+
+		errs := validate()
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("spec.proxy.components[0]"))
+	})
+
+	It("Should reject duplicated components", func() {
+		proxy.Components = []ProxyComponent{CoreProxyComponent, CoreProxyComponent}
+
+		errs := validate()
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("spec.proxy.components[1]"))
+	})
+
+	It("Should reject empty hosts", func() {
+		proxy.NoProxy = []string{""}
+
+		errs := validate()
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("spec.proxy.noProxy[0]"))
+	})
+})
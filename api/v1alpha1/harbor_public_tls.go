@@ -0,0 +1,56 @@
+package v1alpha1
+
+import (
+	"net/url"
+
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+)
+
+const (
+	PublicCertificateName = "public-tls"
+)
+
+// IsPublicCertificateIssued returns true when the operator issues the certificate of public hosts.
+func (h *Harbor) IsPublicCertificateIssued() bool {
+	return h.Spec.TLSSecretName == "" && len(h.GetPublicHosts()) > 0
+}
+
+// GetTLSSecretName returns the name of the secret containing the certificate of public hosts.
+func (h *Harbor) GetTLSSecretName() string {
+	if h.Spec.TLSSecretName != "" {
+		return h.Spec.TLSSecretName
+	}
+
+	return h.NormalizeComponentName(PublicCertificateName)
+}
+
+// GetPublicCertificateIssuerRef returns the issuer of the certificate of public hosts.
+func (h *Harbor) GetPublicCertificateIssuerRef() cmmeta.ObjectReference {
+	if h.Spec.PublicCertificateIssuerRef != nil {
+		return *h.Spec.PublicCertificateIssuerRef
+	}
+
+	return h.Spec.CertificateIssuerRef
+}
+
+// GetPublicHosts returns the hosts of the public URLs using https.
+func (h *Harbor) GetPublicHosts() []string {
+	publicURLs := []string{h.Spec.PublicURL}
+
+	if h.Spec.Components.Notary != nil {
+		publicURLs = append(publicURLs, h.Spec.Components.Notary.PublicURL)
+	}
+
+	hosts := []string{}
+
+	for _, publicURL := range publicURLs {
+		u, err := url.Parse(publicURL)
+		if err != nil || u.Scheme != "https" {
+			continue
+		}
+
+		hosts = append(hosts, u.Hostname())
+	}
+
+	return hosts
+}
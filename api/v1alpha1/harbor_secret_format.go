@@ -37,6 +37,11 @@ const (
 	HarborCoreDatabaseNameKey     = "database"
 	HarborCoreDatabaseUserKey     = "username"
 	HarborCoreDatabasePasswordKey = "password"
+	HarborCoreDatabaseSSLKey      = "ssl"
+)
+
+const (
+	HarborCoreDatabaseCAKey = "ca.crt"
 )
 
 const (
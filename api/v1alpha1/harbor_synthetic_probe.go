@@ -0,0 +1,65 @@
+package v1alpha1
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	DefaultSyntheticProbeProject  = "harbor-operator"
+	DefaultSyntheticProbeInterval = 5 * time.Minute
+
+	MinSyntheticProbeInterval = time.Minute
+)
+
+// GetProject returns the project the image is pushed to.
+func (p *HarborSyntheticProbe) GetProject() string {
+	if p.Project == "" {
+		return DefaultSyntheticProbeProject
+	}
+
+	return p.Project
+}
+
+// GetInterval returns the interval between probes.
+func (p *HarborSyntheticProbe) GetInterval() time.Duration {
+	if p.Interval == nil {
+		return DefaultSyntheticProbeInterval
+	}
+
+	return p.Interval.Duration
+}
+
+// GetEndpoint returns the endpoint the image is pushed to, defaulting to internal.
+func (p *HarborSyntheticProbe) GetEndpoint() SyntheticProbeEndpoint {
+	if p.Endpoint == "" {
+		return InternalSyntheticProbeEndpoint
+	}
+
+	return p.Endpoint
+}
+
+// Validate checks the interval is long enough not to fill the registry.
+func (p *HarborSyntheticProbe) Validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if p == nil {
+		return errs
+	}
+
+	if p.Interval != nil && p.Interval.Duration < MinSyntheticProbeInterval {
+		errs = append(errs, field.Invalid(path.Child("interval"), p.Interval.Duration.String(), "must be at least 1m"))
+	}
+
+	switch p.GetEndpoint() {
+	case InternalSyntheticProbeEndpoint, PublicSyntheticProbeEndpoint:
+	default:
+		errs = append(errs, field.NotSupported(path.Child("endpoint"), p.Endpoint, []string{
+			string(InternalSyntheticProbeEndpoint),
+			string(PublicSyntheticProbeEndpoint),
+		}))
+	}
+
+	return errs
+}
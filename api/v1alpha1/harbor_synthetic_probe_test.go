@@ -0,0 +1,46 @@
+package v1alpha1
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var _ = Describe("HarborSyntheticProbe", func() {
+	var probe *HarborSyntheticProbe
+
+	BeforeEach(func() {
+		probe = &HarborSyntheticProbe{}
+	})
+
+	validate := func() field.ErrorList {
+		return probe.Validate(field.NewPath("spec", "syntheticProbe"))
+	}
+
+	It("Should default to the internal endpoint", func() {
+		probe.Interval = &metav1.Duration{Duration: time.Minute}
+
+		Expect(validate()).To(BeEmpty())
+		Expect(probe.GetEndpoint()).To(Equal(InternalSyntheticProbeEndpoint))
+	})
+
+	It("Should reject short intervals", func() {
+		probe.Interval = &metav1.Duration{Duration: 30 * time.Second}
+
+		errs := validate()
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("spec.syntheticProbe.interval"))
+	})
+
+	It("Should reject unsupported endpoints", func() {
+		probe.Endpoint = "external"
+
+		errs := validate()
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("spec.syntheticProbe.endpoint"))
+	})
+})
@@ -0,0 +1,41 @@
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Validate checks exactly one source of CA certificates is set.
+func (ca *TrustedCA) Validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if ca == nil {
+		return errs
+	}
+
+	switch {
+	case ca.Secret == nil && ca.ConfigMap == nil:
+		errs = append(errs, field.Required(path, "one of secret or configMap must be set"))
+	case ca.Secret != nil && ca.ConfigMap != nil:
+		errs = append(errs, field.Forbidden(path.Child("configMap"), "secret and configMap are mutually exclusive"))
+	case ca.Secret != nil:
+		errs = append(errs, validateKeySelector(path.Child("secret"), ca.Secret.Name, ca.Secret.Key)...)
+	default:
+		errs = append(errs, validateKeySelector(path.Child("configMap"), ca.ConfigMap.Name, ca.ConfigMap.Key)...)
+	}
+
+	return errs
+}
+
+func validateKeySelector(path *field.Path, name, key string) field.ErrorList {
+	var errs field.ErrorList
+
+	if name == "" {
+		errs = append(errs, field.Required(path.Child("name"), ""))
+	}
+
+	if key == "" {
+		errs = append(errs, field.Required(path.Child("key"), ""))
+	}
+
+	return errs
+}
@@ -0,0 +1,61 @@
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var _ = Describe("TrustedCA", func() {
+	var ca *TrustedCA
+
+	BeforeEach(func() {
+		ca = &TrustedCA{}
+	})
+
+	validate := func() field.ErrorList {
+		return ca.Validate(field.NewPath("spec", "trustedCA"))
+	}
+
+	It("Should accept a key of a configmap", func() {
+		ca.ConfigMap = &corev1.ConfigMapKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "trusted-ca"},
+			Key:                  "ca.crt",
+		}
+
+		Expect(validate()).To(BeEmpty())
+	})
+
+	It("Should require a source", func() {
+		errs := validate()
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("spec.trustedCA"))
+	})
+
+	It("Should reject both sources", func() {
+		ca.Secret = &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "trusted-ca"},
+			Key:                  "ca.crt",
+		}
+		ca.ConfigMap = &corev1.ConfigMapKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "trusted-ca"},
+			Key:                  "ca.crt",
+		}
+
+		errs := validate()
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("spec.trustedCA.configMap"))
+	})
+
+	It("Should require the key", func() {
+		ca.Secret = &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "trusted-ca"},
+		}
+
+		errs := validate()
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("spec.trustedCA.secret.key"))
+	})
+})
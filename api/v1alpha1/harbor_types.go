@@ -4,6 +4,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	certv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha2"
 	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 )
 
@@ -54,10 +55,20 @@ type HarborSpec struct {
 	// +kubebuilder:validation:Pattern="^https?://.*$"
 	PublicURL string `json:"publicURL"`
 
-	// The name of the secret containing the TLS secret used for ingresses
+	// The name of the secret containing the TLS secret used for ingresses.
+	// When empty and a public URL uses https, a certificate is issued for public hosts.
 	// +optional
 	TLSSecretName string `json:"tlsSecretName"`
 
+	// The issuer for the public certificate, used when tlsSecretName is empty.
+	// Defaults to certificateIssuerRef.
+	// +optional
+	PublicCertificateIssuerRef *cmmeta.ObjectReference `json:"publicCertificateIssuerRef,omitempty"`
+
+	// The way Harbor is exposed to clients
+	// +optional
+	Expose HarborExpose `json:"expose,omitempty"`
+
 	// +kubebuilder:validation:Required
 	Components HarborComponents `json:"components,omitempty"`
 
@@ -73,10 +84,15 @@ type HarborSpec struct {
 	// +kubebuilder:validation:Optional
 	ReadOnly bool `json:"readOnly,omitempty"`
 
-	// Indicates that the harbor is paused.
+	// Indicates that the harbor is paused: its resources are neither created, updated nor deleted.
+	// Health is still reported.
 	// +optional
 	Paused bool `json:"paused,omitempty"`
 
+	// Components whose resources are neither created, updated nor deleted.
+	// +optional
+	PausedComponents []PausableComponent `json:"pausedComponents,omitempty"`
+
 	// The issuer for Harbor certificates.
 	// If the 'kind' field is not set, or set to 'Issuer', an Issuer resource
 	// with the given name in the same namespace as the Certificate will be used.
@@ -84,8 +100,210 @@ type HarborSpec struct {
 	// provided name will be used.
 	// The 'name' field in this stanza is required at all times.
 	CertificateIssuerRef cmmeta.ObjectReference `json:"certificateIssuerRef"`
+
+	// TLS between Harbor components.
+	// Certificates are issued by certificateIssuerRef.
+	// +optional
+	InternalTLS InternalTLSSpec `json:"internalTLS,omitempty"`
+
+	// Key and lifetime settings of the certificates issued by certificateIssuerRef.
+	// +optional
+	Certificates HarborCertificates `json:"certificates,omitempty"`
+
+	// The proxy used by components for outbound connections.
+	// +optional
+	Proxy *HarborProxy `json:"proxy,omitempty"`
+
+	// Additional CA certificates trusted by components for outbound connections,
+	// to OIDC providers, replication targets or storage endpoints for example.
+	// +optional
+	TrustedCA *TrustedCA `json:"trustedCA,omitempty"`
+
+	// Services, ServiceMonitors and PrometheusRules for the prometheus-operator.
+	// ServiceMonitors and PrometheusRules are only created when their CRDs are installed.
+	// +optional
+	Monitoring *HarborMonitoring `json:"monitoring,omitempty"`
+
+	// Periodic push and pull of a generated image, reported by the Functional condition.
+	// +optional
+	SyntheticProbe *HarborSyntheticProbe `json:"syntheticProbe,omitempty"`
+}
+
+type HarborSyntheticProbe struct {
+	// The project the image is pushed to, created when missing.
+	// +optional
+	// +kubebuilder:validation:Pattern="^[a-z0-9]+(?:[._-][a-z0-9]+)*$"
+	Project string `json:"project,omitempty"`
+
+	// The interval between probes.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// The endpoint the image is pushed to: the internal core service, or the public URL.
+	// The internal endpoint requires the operator to run in the cluster.
+	// +optional
+	Endpoint SyntheticProbeEndpoint `json:"endpoint,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=internal;public
+type SyntheticProbeEndpoint string
+
+const (
+	InternalSyntheticProbeEndpoint SyntheticProbeEndpoint = "internal"
+	PublicSyntheticProbeEndpoint   SyntheticProbeEndpoint = "public"
+)
+
+type HarborMonitoring struct {
+	// Labels added to ServiceMonitors and PrometheusRules,
+	// matched by the selectors of the Prometheus resource.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// The interval between scrapes.
+	// Defaults to the Prometheus global interval.
+	// +optional
+	// +kubebuilder:validation:Pattern="^[0-9]+(ms|s|m|h)$"
+	Interval string `json:"interval,omitempty"`
+
+	// The components exposing metrics, in addition to the registry.
+	// Core and jobservice expose metrics since Harbor 2.2.
+	// +optional
+	Components []MetricsComponent `json:"components,omitempty"`
+
+	// Do not create the default alerting rules.
+	// Defaults to false.
+	// +optional
+	DisableRules *bool `json:"disableRules,omitempty"`
 }
 
+// +kubebuilder:validation:Enum=core;portal;registry;jobservice;chartmuseum;clair;notary;monitoring
+type PausableComponent string
+
+// +kubebuilder:validation:Enum=core;jobservice
+type MetricsComponent string
+
+const (
+	CoreMetricsComponent       MetricsComponent = CoreName
+	JobServiceMetricsComponent MetricsComponent = JobServiceName
+)
+
+type HarborProxy struct {
+	// +optional
+	// +kubebuilder:validation:Pattern="^https?://.*$"
+	HTTPProxy string `json:"httpProxy,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Pattern="^https?://.*$"
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+
+	// Hosts reached without proxy, in addition to Harbor services.
+	// +optional
+	NoProxy []string `json:"noProxy,omitempty"`
+
+	// The components using the proxy. Other components do not use any proxy.
+	// +optional
+	Components []ProxyComponent `json:"components,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=core;jobservice;clair;registry;chartmuseum
+type ProxyComponent string
+
+const (
+	CoreProxyComponent        ProxyComponent = CoreName
+	JobServiceProxyComponent  ProxyComponent = JobServiceName
+	ClairProxyComponent       ProxyComponent = ClairName
+	RegistryProxyComponent    ProxyComponent = RegistryName
+	ChartMuseumProxyComponent ProxyComponent = ChartMuseumName
+)
+
+// TrustedCA references the PEM encoded CA certificates, either in a secret or in a configmap.
+type TrustedCA struct {
+	// +optional
+	Secret *corev1.SecretKeySelector `json:"secret,omitempty"`
+
+	// +optional
+	ConfigMap *corev1.ConfigMapKeySelector `json:"configMap,omitempty"`
+}
+
+type HarborCertificates struct {
+	// The certificate used by core to sign registry tokens.
+	// Harbor signs tokens with RS256, so only rsa keys are supported.
+	// +optional
+	RegistryToken *CertificateSettings `json:"registryToken,omitempty"`
+
+	// The certificate served by notary signer to notary server.
+	// +optional
+	Notary *CertificateSettings `json:"notary,omitempty"`
+}
+
+type CertificateSettings struct {
+	// The private key algorithm.
+	// Defaults to rsa.
+	// +optional
+	// +kubebuilder:validation:Enum=rsa;ecdsa
+	KeyAlgorithm certv1.KeyAlgorithm `json:"keyAlgorithm,omitempty"`
+
+	// The private key size in bits: 2048 to 8192 for rsa, 256, 384 or 521 for ecdsa.
+	// Defaults to 4096 for rsa and 256 for ecdsa.
+	// +optional
+	KeySize int `json:"keySize,omitempty"`
+
+	// The requested validity of the certificate.
+	// Defaults to the cert-manager default.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+
+	// How long before expiry the certificate is renewed.
+	// Defaults to the cert-manager default.
+	// +optional
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+
+	// DNS names added to the certificate.
+	// +optional
+	DNSNames []string `json:"dnsNames,omitempty"`
+}
+
+type InternalTLSSpec struct {
+	// Serve every component over HTTPS and trust the issuer CA in every client component.
+	// The issuer must provide the CA in the ca.crt key of the certificate secrets.
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+}
+
+type HarborExpose struct {
+	// The kind of resources used to expose Harbor.
+	// Defaults to ingress.
+	// +optional
+	// +kubebuilder:validation:Enum=ingress;route
+	Type ExposeType `json:"type,omitempty"`
+
+	// +optional
+	Route *RouteExpose `json:"route,omitempty"`
+}
+
+type ExposeType string
+
+const (
+	IngressExposeType ExposeType = "ingress"
+	RouteExposeType   ExposeType = "route"
+)
+
+type RouteExpose struct {
+	// The TLS termination of OpenShift routes, used when public URLs use https.
+	// The certificate and key are read from the tlsSecretName secret.
+	// Defaults to edge.
+	// +optional
+	// +kubebuilder:validation:Enum=edge;reencrypt
+	Termination RouteTermination `json:"termination,omitempty"`
+}
+
+type RouteTermination string
+
+const (
+	EdgeRouteTermination      RouteTermination = "edge"
+	ReencryptRouteTermination RouteTermination = "reencrypt"
+)
+
 type HarborComponents struct {
 	// +optional
 	Core *CoreComponent `json:"core,omitempty"`
@@ -122,17 +340,85 @@ type HarborDeployment struct {
 	// +optional
 	NodeSelector     NodeSelector                  `json:"nodeSelector,omitempty"`
 	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty" patchStrategy:"merge" patchMergeKey:"name"`
+
+	// The log level, translated into the native setting of the component.
+	// Defaults to info.
+	// +optional
+	// +kubebuilder:validation:Enum=debug;info;warning;error;fatal
+	LogLevel LogLevel `json:"logLevel,omitempty"`
 }
 
 type NodeSelector map[string]string
 
+type LogLevel string
+
+const (
+	DebugLogLevel   LogLevel = "debug"
+	InfoLogLevel    LogLevel = "info"
+	WarningLogLevel LogLevel = "warning"
+	ErrorLogLevel   LogLevel = "error"
+	FatalLogLevel   LogLevel = "fatal"
+)
+
+type LogFormat string
+
+const (
+	JSONLogFormat LogFormat = "json"
+	TextLogFormat LogFormat = "text"
+)
+
 type CoreComponent struct {
 	HarborDeployment `json:",inline"`
 
 	// +kubebuilder:validation:Required
 	DatabaseSecret string `json:"databaseSecret"`
+
+	// Connection settings of the database described by databaseSecret.
+	// They also apply to the clair database settings of core.
+	// +optional
+	Database CoreDatabaseSpec `json:"database,omitempty"`
+}
+
+type CoreDatabaseSpec struct {
+	// The maximum number of idle connections in the pool.
+	// Defaults to 50, or maxOpenConns if lower.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MaxIdleConnections *int32 `json:"maxIdleConns,omitempty"`
+
+	// The maximum number of open connections, 0 means unlimited.
+	// Defaults to 1000.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MaxOpenConnections *int32 `json:"maxOpenConns,omitempty"`
+
+	// The SSL mode of connections.
+	// Defaults to the ssl key of databaseSecret, or disable if missing.
+	// +optional
+	// +kubebuilder:validation:Enum=disable;allow;prefer;require;verify-ca;verify-full
+	SSLMode PostgresSSLMode `json:"sslMode,omitempty"`
+
+	// The name of the secret containing, in the ca.crt key, the CA used to verify the database certificate.
+	// +optional
+	CASecretName string `json:"caSecretName,omitempty"`
+
+	// The maximum wait for a connection, rounded down to the second.
+	// Defaults to no timeout.
+	// +optional
+	ConnectTimeout *metav1.Duration `json:"connectTimeout,omitempty"`
 }
 
+type PostgresSSLMode string
+
+const (
+	DisablePostgresSSLMode    PostgresSSLMode = "disable"
+	AllowPostgresSSLMode      PostgresSSLMode = "allow"
+	PreferPostgresSSLMode     PostgresSSLMode = "prefer"
+	RequirePostgresSSLMode    PostgresSSLMode = "require"
+	VerifyCAPostgresSSLMode   PostgresSSLMode = "verify-ca"
+	VerifyFullPostgresSSLMode PostgresSSLMode = "verify-full"
+)
+
 type PortalComponent struct {
 	HarborDeployment `json:",inline"`
 }
@@ -140,6 +426,12 @@ type PortalComponent struct {
 type RegistryComponent struct {
 	HarborDeployment `json:",inline"`
 
+	// The format of the logs.
+	// Defaults to json.
+	// +optional
+	// +kubebuilder:validation:Enum=json;text
+	LogFormat LogFormat `json:"logFormat,omitempty"`
+
 	Controller RegistryControllerComponent `json:"controller,omitempty"`
 
 	// +optional
@@ -147,6 +439,9 @@ type RegistryComponent struct {
 
 	// +optional
 	CacheSecret string `json:"cacheSecret,omitempty"`
+
+	// +optional
+	ConfigOverrides *ConfigOverrides `json:"configOverrides,omitempty"`
 }
 
 type RegistryControllerComponent struct {
@@ -162,6 +457,9 @@ type JobServiceComponent struct {
 
 	// +optional
 	WorkerCount int32 `json:"workerCount"`
+
+	// +optional
+	ConfigOverrides *ConfigOverrides `json:"configOverrides,omitempty"`
 }
 
 type ClairAdapterComponent struct {
@@ -181,16 +479,28 @@ type ClairComponent struct {
 	VulnerabilitySources []string `json:"vulnerabilitySources"`
 
 	Adapter ClairAdapterComponent `json:"adapter"`
+
+	// +optional
+	ConfigOverrides *ConfigOverrides `json:"configOverrides,omitempty"`
 }
 
 type ChartMuseumComponent struct {
 	HarborDeployment `json:",inline"`
 
+	// The format of the logs.
+	// Defaults to json.
+	// +optional
+	// +kubebuilder:validation:Enum=json;text
+	LogFormat LogFormat `json:"logFormat,omitempty"`
+
 	// +optional
 	StorageSecret string `json:"storageSecret,omitempty"`
 
 	// +optional
 	CacheSecret string `json:"cacheSecret,omitempty"`
+
+	// +optional
+	ConfigOverrides *ConfigOverrides `json:"configOverrides,omitempty"`
 }
 
 type NotaryComponent struct {
@@ -219,6 +529,9 @@ type NotarySignerComponent struct {
 
 	// +kubebuilder:validation:Required
 	DatabaseSecret string `json:"databaseSecret"`
+
+	// +optional
+	ConfigOverrides *ConfigOverrides `json:"configOverrides,omitempty"`
 }
 
 type NotaryServerComponent struct {
@@ -226,6 +539,25 @@ type NotaryServerComponent struct {
 
 	// +kubebuilder:validation:Required
 	DatabaseSecret string `json:"databaseSecret"`
+
+	// +optional
+	ConfigOverrides *ConfigOverrides `json:"configOverrides,omitempty"`
+}
+
+// ConfigOverrides are applied to the configuration file rendered by the operator.
+// Inline patches and configMapRef are mutually exclusive.
+type ConfigOverrides struct {
+	// JSON merge patch (RFC 7386), written in YAML or JSON, merged into the configuration.
+	// +optional
+	MergePatch string `json:"mergePatch,omitempty"`
+
+	// JSON patch (RFC 6902), written in YAML or JSON, applied after the merge patch.
+	// +optional
+	JSONPatch string `json:"jsonPatch,omitempty"`
+
+	// ConfigMap containing the mergePatch and jsonPatch keys.
+	// +optional
+	ConfigMapRef *corev1.LocalObjectReference `json:"configMapRef,omitempty"`
 }
 
 // HarborStatus defines the observed state of Harbor
@@ -237,6 +569,95 @@ type HarborStatus struct {
 	Conditions []HarborCondition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,6,rep,name=conditions"`
 
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Admission status of the OpenShift routes exposing Harbor.
+	// +optional
+	Routes []RouteAdmissionStatus `json:"routes,omitempty"`
+
+	// Settings of the certificates issued for Harbor.
+	// +optional
+	Certificates []CertificateStatus `json:"certificates,omitempty"`
+
+	// Readiness of the certificate issued for public hosts, when tlsSecretName is empty.
+	// +optional
+	PublicCertificate *PublicCertificateStatus `json:"publicCertificate,omitempty"`
+
+	// Desired state last applied for each component.
+	// +optional
+	// +patchMergeKey=name
+	// +patchStrategy=merge
+	Components []ComponentStatus `json:"components,omitempty" patchStrategy:"merge" patchMergeKey:"name"`
+}
+
+// ComponentStatus describes the desired state last applied for a component.
+type ComponentStatus struct {
+	// Name of the component.
+	Name string `json:"name"`
+
+	// Hash of the resources rendered for the component, also set as the goharbor.io/desired-state-hash annotation of the resources.
+	DesiredStateHash string `json:"desiredStateHash"`
+}
+
+// PublicCertificateStatus describes the readiness of the certificate issued for public hosts.
+type PublicCertificateStatus struct {
+	// Name of the secret containing the certificate.
+	SecretName string `json:"secretName"`
+
+	// Ready is True when the certificate is issued and up to date.
+	Ready corev1.ConditionStatus `json:"ready"`
+
+	// The reason given by cert-manager.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// A human readable message given by cert-manager.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// The expiration time of the certificate.
+	// +optional
+	NotAfter *metav1.Time `json:"notAfter,omitempty"`
+}
+
+// CertificateStatus describes the settings used to issue a certificate.
+type CertificateStatus struct {
+	// Name of the certificate.
+	Name string `json:"name"`
+
+	// The private key algorithm.
+	KeyAlgorithm certv1.KeyAlgorithm `json:"keyAlgorithm"`
+
+	// The private key size in bits.
+	KeySize int `json:"keySize"`
+
+	// The requested validity of the certificate.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+
+	// How long before expiry the certificate is renewed.
+	// +optional
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+}
+
+// RouteAdmissionStatus describes whether a route has been admitted by the OpenShift routers.
+type RouteAdmissionStatus struct {
+	// Name of the route.
+	Name string `json:"name"`
+
+	// Host exposed by the route.
+	// +optional
+	Host string `json:"host,omitempty"`
+
+	// Admitted is True when at least one router admitted the route, False when rejected, Unknown otherwise.
+	Admitted corev1.ConditionStatus `json:"admitted"`
+
+	// The reason given by the router.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// A human readable message given by the router.
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // HarborCondition describes the state of a Harbor at a certain point.
@@ -265,6 +686,13 @@ type HarborConditionType string
 const (
 	AppliedConditionType HarborConditionType = "Applied"
 	ReadyConditionType   HarborConditionType = "Ready"
+	// FunctionalConditionType is True when the last synthetic push and pull succeeded.
+	FunctionalConditionType HarborConditionType = "Functional"
+	// PausedConditionType is True when the Harbor or some of its components are paused.
+	PausedConditionType HarborConditionType = "Paused"
+	// DriftedConditionType is True when owned resources were changed outside of the operator.
+	// It is only maintained when drifts are reported instead of corrected.
+	DriftedConditionType HarborConditionType = "Drifted"
 )
 
 func init() { // nolint:gochecknoinits
@@ -1,7 +1,13 @@
 package v1alpha1
 
 import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
@@ -9,6 +15,14 @@ import (
 // log is for logging in this package.
 var harborlog = logf.Log.WithName("harbor-resource")
 
+// harborClassReader reads the HarborClasses whose defaults are merged before validation, nil when disabled.
+var harborClassReader client.Reader
+
+// SetHarborClassReader sets the reader of HarborClasses used by webhooks, nil disables HarborClasses.
+func SetHarborClassReader(reader client.Reader) {
+	harborClassReader = reader
+}
+
 func (r *Harbor) SetupWebhookWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
@@ -23,13 +37,109 @@ var _ webhook.Defaulter = &Harbor{}
 func (r *Harbor) Default() {
 	harborlog.Info("default", "name", r.Name)
 
+	// Values provided by the class are not defaulted
+	merged, err := r.withHarborClass()
+	if err != nil {
+		harborlog.Error(err, "cannot merge harbor class", "name", r.Name)
+
+		merged = r
+	}
+
+	r.setDefaults(merged)
+}
+
+// SetDefaults sets default values of the spec, the Harbor must already be merged with its class.
+// Harbors not mutated by the webhook are defaulted in memory by the operator.
+func (r *Harbor) SetDefaults() {
+	r.setDefaults(r)
+}
+
+// setDefaults sets default values of the fields not set in the merged Harbor.
+func (r *Harbor) setDefaults(merged *Harbor) {
 	if r.Spec.Components.JobService != nil {
-		if r.Spec.Components.JobService.WorkerCount == 0 {
+		if merged.Spec.Components.JobService.WorkerCount == 0 {
 			r.Spec.Components.JobService.WorkerCount = 3
 		}
 	}
 
+	if merged.Spec.Expose.Type == "" {
+		r.Spec.Expose.Type = IngressExposeType
+	}
+
 	if r.Spec.HarborVersion == "" {
 		r.Spec.HarborVersion = "1.10.0"
 	}
 }
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-goharbor-io-v1alpha1-harbor,mutating=false,failurePolicy=fail,groups=goharbor.io,resources=harbors,versions=v1alpha1,name=vharbor.kb.io
+
+var _ webhook.Validator = &Harbor{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *Harbor) ValidateCreate() error {
+	harborlog.Info("validate create", "name", r.Name)
+
+	return r.validateWithClass()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *Harbor) ValidateUpdate(old runtime.Object) error {
+	harborlog.Info("validate update", "name", r.Name)
+
+	return r.validateWithClass()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *Harbor) ValidateDelete() error {
+	return nil
+}
+
+// validateWithClass validates the Harbor merged over the defaults of its class.
+// Defaults of the class are not written into the Harbor, so updates of the class apply to existing Harbors.
+func (r *Harbor) validateWithClass() error {
+	harbor, err := r.withHarborClass()
+	if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+
+	return harbor.Validate()
+}
+
+// withHarborClass returns the Harbor merged over the defaults of its class.
+func (r *Harbor) withHarborClass() (*Harbor, error) {
+	if harborClassReader == nil {
+		return r, nil
+	}
+
+	class, err := GetHarborClass(context.TODO(), harborClassReader, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.WithClass(class)
+}
+
+// Validate returns an Invalid error listing every invalid field of the spec.
+func (r *Harbor) Validate() error {
+	var errs field.ErrorList
+
+	errs = append(errs, r.Spec.Certificates.Validate(field.NewPath("spec").Child("certificates"))...)
+	errs = append(errs, r.Spec.ValidateInternalTLS(field.NewPath("spec"))...)
+	errs = append(errs, r.Spec.ValidateExpose(field.NewPath("spec"))...)
+	errs = append(errs, r.Spec.Components.ValidateConfigOverrides(field.NewPath("spec").Child("components"))...)
+	errs = append(errs, r.Spec.Proxy.Validate(field.NewPath("spec").Child("proxy"))...)
+	errs = append(errs, r.Spec.TrustedCA.Validate(field.NewPath("spec").Child("trustedCA"))...)
+	errs = append(errs, r.Spec.Monitoring.Validate(field.NewPath("spec").Child("monitoring"), &r.Spec.Components)...)
+	errs = append(errs, r.Spec.SyntheticProbe.Validate(field.NewPath("spec").Child("syntheticProbe"))...)
+	errs = append(errs, r.Spec.ValidatePausedComponents(field.NewPath("spec").Child("pausedComponents"))...)
+
+	if r.Spec.Components.Core != nil {
+		errs = append(errs, r.Spec.Components.Core.Database.Validate(field.NewPath("spec").Child("components", "core", "database"))...)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return apierrors.NewInvalid(GroupVersion.WithKind("Harbor").GroupKind(), r.Name, errs)
+}
@@ -0,0 +1,323 @@
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// deploymentComponentPaths are the paths of the components with a deployment, in the spec of Harbors.
+var deploymentComponentPaths = [][]string{
+	{"core"},
+	{"portal"},
+	{"registry"},
+	{"jobService"},
+	{"chartMuseum"},
+	{"clair"},
+	{"notary", "signer"},
+	{"notary", "server"},
+}
+
+// GetHarborClassName returns the name of the HarborClass of the Harbor, empty when not set.
+func (r *Harbor) GetHarborClassName() string {
+	return r.GetAnnotations()[HarborClassAnnotation]
+}
+
+// GetHarborClass returns the class of the Harbor, nil if the Harbor has no class,
+// the class does not exist or the HarborClass CRD is not installed.
+func GetHarborClass(ctx context.Context, reader client.Reader, harbor *Harbor) (*HarborClass, error) {
+	name := harbor.GetHarborClassName()
+	if name == "" {
+		return nil, nil
+	}
+
+	class := &HarborClass{}
+
+	err := reader.Get(ctx, client.ObjectKey{Name: name}, class)
+	if err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+
+		return nil, errors.Wrapf(err, "cannot get harbor class %s", name)
+	}
+
+	return class, nil
+}
+
+// WithClass returns a copy of the Harbor whose spec is merged over the defaults of the class.
+// Fields set in the Harbor take precedence, see pruneUnset.
+// Component defaults only apply to the components enabled in the Harbor.
+func (r *Harbor) WithClass(class *HarborClass) (*Harbor, error) {
+	harbor := r.DeepCopy()
+
+	if class == nil {
+		return harbor, nil
+	}
+
+	defaults, err := class.Spec.Defaults.getSpecDefaults(&r.Spec.Components)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get defaults")
+	}
+
+	spec, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&r.Spec)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot convert spec")
+	}
+
+	pruneUnset(reflect.ValueOf(&r.Spec), spec)
+
+	merged := mergeDefaults(defaults, spec)
+
+	harbor.Spec = HarborSpec{}
+
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(merged, &harbor.Spec)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot convert merged spec")
+	}
+
+	if class.Spec.ImageRegistry != "" {
+		harbor.Spec.Components.setImageRegistry(class.Spec.ImageRegistry)
+	}
+
+	return harbor, nil
+}
+
+// getSpecDefaults returns the defaults as the content of a Harbor spec whose components are enabled.
+func (d *HarborClassDefaults) getSpecDefaults(enabled *HarborComponents) (map[string]interface{}, error) {
+	defaults := d.DeepCopy()
+	defaults.Deployment = nil
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(defaults)
+	if err != nil {
+		return nil, err
+	}
+
+	pruneUnset(reflect.ValueOf(defaults), content)
+
+	enabledContent, err := runtime.DefaultUnstructuredConverter.ToUnstructured(enabled)
+	if err != nil {
+		return nil, err
+	}
+
+	var deployment map[string]interface{}
+
+	if d.Deployment != nil {
+		deployment, err = runtime.DefaultUnstructuredConverter.ToUnstructured(d.Deployment)
+		if err != nil {
+			return nil, err
+		}
+
+		pruneUnset(reflect.ValueOf(d.Deployment), deployment)
+	}
+
+	components, _ := content["components"].(map[string]interface{})
+	if components == nil {
+		components = map[string]interface{}{}
+	}
+
+	for name := range components {
+		if enabledContent[name] == nil {
+			delete(components, name)
+		}
+	}
+
+	for _, path := range deploymentComponentPaths {
+		if !hasPath(enabledContent, path) {
+			continue
+		}
+
+		component := getOrCreatePath(components, path)
+
+		for key, value := range mergeDefaults(deployment, component) {
+			component[key] = value
+		}
+	}
+
+	content["components"] = components
+
+	return content, nil
+}
+
+func hasPath(content map[string]interface{}, path []string) bool {
+	for _, key := range path {
+		value, ok := content[key].(map[string]interface{})
+		if !ok {
+			return false
+		}
+
+		content = value
+	}
+
+	return true
+}
+
+func getOrCreatePath(content map[string]interface{}, path []string) map[string]interface{} {
+	for _, key := range path {
+		value, ok := content[key].(map[string]interface{})
+		if !ok {
+			value = map[string]interface{}{}
+			content[key] = value
+		}
+
+		content = value
+	}
+
+	return content
+}
+
+// mergeDefaults returns the values merged over the defaults. Objects are merged, other values replace defaults.
+func mergeDefaults(defaults, values map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(defaults)+len(values))
+
+	for key, value := range defaults {
+		result[key] = runtime.DeepCopyJSONValue(value)
+	}
+
+	for key, value := range values {
+		defaultValue, ok := result[key].(map[string]interface{})
+		object, isObject := value.(map[string]interface{})
+
+		if ok && isObject {
+			result[key] = mergeDefaults(defaultValue, object)
+		} else {
+			result[key] = value
+		}
+	}
+
+	return result
+}
+
+// pruneUnset removes from the content the fields of the value which are not set: nil pointers, lists and maps,
+// and zero values of other fields, since they cannot be told apart from omitted fields.
+// Zero values behind pointers, such as replicas: 0, are set. Objects are kept, since some of them enable features.
+func pruneUnset(value reflect.Value, content map[string]interface{}) {
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return
+		}
+
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return
+	}
+
+	valueType := value.Type()
+
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name, inline := getJSONName(field)
+		if name == "-" {
+			continue
+		}
+
+		if inline {
+			pruneUnset(value.Field(i), content)
+			continue
+		}
+
+		if !isSet(value.Field(i)) {
+			delete(content, name)
+			continue
+		}
+
+		if object, ok := content[name].(map[string]interface{}); ok {
+			pruneUnset(value.Field(i), object)
+		}
+	}
+}
+
+// getJSONName returns the name of the field in JSON, and whether it is inlined in its parent.
+func getJSONName(field reflect.StructField) (string, bool) {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+
+	if name == "" && field.Anonymous {
+		return "", true
+	}
+
+	if name == "" {
+		name = field.Name
+	}
+
+	return name, strings.Contains(field.Tag.Get("json"), ",inline")
+}
+
+func isSet(value reflect.Value) bool {
+	switch value.Kind() { // nolint:exhaustive
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return !value.IsNil()
+	case reflect.Struct:
+		if _, ok := value.Interface().(json.Marshaler); ok {
+			return !value.IsZero()
+		}
+
+		return true
+	default:
+		return !value.IsZero()
+	}
+}
+
+// withImageRegistry returns the image pulled from the registry, the registry of the image is replaced.
+func withImageRegistry(registry, image string) string {
+	parts := strings.SplitN(image, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		image = parts[1]
+	}
+
+	return strings.TrimSuffix(registry, "/") + "/" + image
+}
+
+func setImageRegistry(image **string, registry, key string) {
+	if *image == nil {
+		value := withImageRegistry(registry, GetDefaultImage(key))
+		*image = &value
+	}
+}
+
+// setImageRegistry sets the images of enabled components whose image is not set, pulled from the registry.
+func (c *HarborComponents) setImageRegistry(registry string) {
+	if c.Core != nil {
+		setImageRegistry(&c.Core.Image, registry, CoreImageKey)
+	}
+
+	if c.Portal != nil {
+		setImageRegistry(&c.Portal.Image, registry, PortalImageKey)
+	}
+
+	if c.Registry != nil {
+		setImageRegistry(&c.Registry.Image, registry, RegistryImageKey)
+		setImageRegistry(&c.Registry.Controller.Image, registry, RegistryControllerImageKey)
+	}
+
+	if c.JobService != nil {
+		setImageRegistry(&c.JobService.Image, registry, JobServiceImageKey)
+	}
+
+	if c.ChartMuseum != nil {
+		setImageRegistry(&c.ChartMuseum.Image, registry, ChartMuseumImageKey)
+	}
+
+	if c.Clair != nil {
+		setImageRegistry(&c.Clair.Image, registry, ClairImageKey)
+		setImageRegistry(&c.Clair.Adapter.Image, registry, ClairAdapterImageKey)
+	}
+
+	if c.Notary != nil {
+		setImageRegistry(&c.Notary.Signer.Image, registry, NotarySignerImageKey)
+		setImageRegistry(&c.Notary.Server.Image, registry, NotaryServerImageKey)
+		setImageRegistry(&c.Notary.DBMigrator.Image, registry, NotaryDBMigratorImageKey)
+	}
+}
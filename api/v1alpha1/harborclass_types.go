@@ -0,0 +1,108 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+)
+
+// HarborClass contains defaults of the Harbors whose goharbor.io/harbor-class annotation is its name.
+// +kubebuilder:object:root=true
+// +k8s:openapi-gen=true
+// +resource:path=harborclass
+// +kubebuilder:resource:scope=Cluster,shortName="hc"
+// +kubebuilder:printcolumn:name="Controller",type=string,JSONPath=`.spec.controller`,description="The class of the operator reconciling Harbors of this class",priority=0
+// +kubebuilder:printcolumn:name="Image Registry",type=string,JSONPath=`.spec.imageRegistry`,description="The registry of default images",priority=5
+type HarborClass struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec HarborClassSpec `json:"spec,omitempty"`
+}
+
+// HarborClassList contains a list of HarborClass
+// +kubebuilder:object:root=true
+// +resource:path=harborclasses
+type HarborClassList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HarborClass `json:"items"`
+}
+
+// HarborClassSpec defines the defaults of a class of Harbors
+type HarborClassSpec struct {
+	// The class of the operator reconciling Harbors of this class, as configured by the harbor-controller-class key.
+	// Defaults to the name of the HarborClass.
+	// +optional
+	Controller string `json:"controller,omitempty"`
+
+	// The registry, and optional path, from which default images are pulled instead of Docker Hub.
+	// For example, registry.example.com/mirror pulls registry.example.com/mirror/goharbor/harbor-core.
+	// Images set in Harbors are not changed.
+	// +optional
+	// +kubebuilder:validation:Pattern="^[^/@:]+(:[0-9]+)?(/[a-z0-9]+(?:[._-][a-z0-9]+)*)*$"
+	ImageRegistry string `json:"imageRegistry,omitempty"`
+
+	// Defaults of the spec of Harbors of this class. Fields set in a Harbor take precedence.
+	// +optional
+	Defaults HarborClassDefaults `json:"defaults,omitempty"`
+}
+
+type HarborClassDefaults struct {
+	// The issuer for Harbor certificates.
+	// +optional
+	CertificateIssuerRef *cmmeta.ObjectReference `json:"certificateIssuerRef,omitempty"`
+
+	// The issuer for the public certificate.
+	// +optional
+	PublicCertificateIssuerRef *cmmeta.ObjectReference `json:"publicCertificateIssuerRef,omitempty"`
+
+	// The way Harbor is exposed to clients
+	// +optional
+	Expose *HarborExpose `json:"expose,omitempty"`
+
+	// The Maximum priority of deployments.
+	// +optional
+	Priority *int32 `json:"priority,omitempty"`
+
+	// Defaults of every component deployment: replicas, image pull secrets, node selector and log level.
+	// Images are not defaulted, use imageRegistry instead.
+	// +optional
+	Deployment *HarborDeploymentDefaults `json:"deployment,omitempty"`
+
+	// Defaults of components, applied to the components enabled in a Harbor only.
+	// They take precedence over the deployment defaults.
+	// +optional
+	Components HarborComponents `json:"components,omitempty"`
+
+	// The proxy used by components for outbound connections.
+	// +optional
+	Proxy *HarborProxy `json:"proxy,omitempty"`
+
+	// Additional CA certificates trusted by components for outbound connections.
+	// +optional
+	TrustedCA *TrustedCA `json:"trustedCA,omitempty"`
+
+	// Services, ServiceMonitors and PrometheusRules for the prometheus-operator.
+	// +optional
+	Monitoring *HarborMonitoring `json:"monitoring,omitempty"`
+}
+
+type HarborDeploymentDefaults struct {
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// +optional
+	NodeSelector     NodeSelector                  `json:"nodeSelector,omitempty"`
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Enum=debug;info;warning;error;fatal
+	LogLevel LogLevel `json:"logLevel,omitempty"`
+}
+
+func init() { // nolint:gochecknoinits
+	SchemeBuilder.Register(&HarborClass{}, &HarborClassList{})
+}
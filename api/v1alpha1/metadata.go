@@ -1,7 +1,12 @@
 package v1alpha1
 
 const (
-	HarborClassAnnotation = "goharbor.io/harbor-class"
+	HarborClassAnnotation      = "goharbor.io/harbor-class"
+	DesiredStateHashAnnotation = "goharbor.io/desired-state-hash"
+
+	RequeueInitialDelayAnnotation = "goharbor.io/requeue-initial-delay"
+	RequeueMaxDelayAnnotation     = "goharbor.io/requeue-max-delay"
+	ResyncIntervalAnnotation      = "goharbor.io/resync-interval"
 )
 
 const (
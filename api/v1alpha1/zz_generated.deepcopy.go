@@ -5,14 +5,76 @@
 package v1alpha1
 
 import (
-	"k8s.io/api/core/v1"
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	"github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateSettings) DeepCopyInto(out *CertificateSettings) {
+	*out = *in
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RenewBefore != nil {
+		in, out := &in.RenewBefore, &out.RenewBefore
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.DNSNames != nil {
+		in, out := &in.DNSNames, &out.DNSNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateSettings.
+func (in *CertificateSettings) DeepCopy() *CertificateSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateStatus) DeepCopyInto(out *CertificateStatus) {
+	*out = *in
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RenewBefore != nil {
+		in, out := &in.RenewBefore, &out.RenewBefore
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateStatus.
+func (in *CertificateStatus) DeepCopy() *CertificateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ChartMuseumComponent) DeepCopyInto(out *ChartMuseumComponent) {
 	*out = *in
 	in.HarborDeployment.DeepCopyInto(&out.HarborDeployment)
+	if in.ConfigOverrides != nil {
+		in, out := &in.ConfigOverrides, &out.ConfigOverrides
+		*out = new(ConfigOverrides)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChartMuseumComponent.
@@ -55,6 +117,11 @@ func (in *ClairComponent) DeepCopyInto(out *ClairComponent) {
 		copy(*out, *in)
 	}
 	in.Adapter.DeepCopyInto(&out.Adapter)
+	if in.ConfigOverrides != nil {
+		in, out := &in.ConfigOverrides, &out.ConfigOverrides
+		*out = new(ConfigOverrides)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClairComponent.
@@ -67,10 +134,46 @@ func (in *ClairComponent) DeepCopy() *ClairComponent {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentStatus) DeepCopyInto(out *ComponentStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentStatus.
+func (in *ComponentStatus) DeepCopy() *ComponentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigOverrides) DeepCopyInto(out *ConfigOverrides) {
+	*out = *in
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigOverrides.
+func (in *ConfigOverrides) DeepCopy() *ConfigOverrides {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigOverrides)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CoreComponent) DeepCopyInto(out *CoreComponent) {
 	*out = *in
 	in.HarborDeployment.DeepCopyInto(&out.HarborDeployment)
+	in.Database.DeepCopyInto(&out.Database)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreComponent.
@@ -83,6 +186,36 @@ func (in *CoreComponent) DeepCopy() *CoreComponent {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CoreDatabaseSpec) DeepCopyInto(out *CoreDatabaseSpec) {
+	*out = *in
+	if in.MaxIdleConnections != nil {
+		in, out := &in.MaxIdleConnections, &out.MaxIdleConnections
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxOpenConnections != nil {
+		in, out := &in.MaxOpenConnections, &out.MaxOpenConnections
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ConnectTimeout != nil {
+		in, out := &in.ConnectTimeout, &out.ConnectTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDatabaseSpec.
+func (in *CoreDatabaseSpec) DeepCopy() *CoreDatabaseSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CoreDatabaseSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Harbor) DeepCopyInto(out *Harbor) {
 	*out = *in
@@ -110,6 +243,161 @@ func (in *Harbor) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HarborCertificates) DeepCopyInto(out *HarborCertificates) {
+	*out = *in
+	if in.RegistryToken != nil {
+		in, out := &in.RegistryToken, &out.RegistryToken
+		*out = new(CertificateSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Notary != nil {
+		in, out := &in.Notary, &out.Notary
+		*out = new(CertificateSettings)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HarborCertificates.
+func (in *HarborCertificates) DeepCopy() *HarborCertificates {
+	if in == nil {
+		return nil
+	}
+	out := new(HarborCertificates)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HarborClass) DeepCopyInto(out *HarborClass) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HarborClass.
+func (in *HarborClass) DeepCopy() *HarborClass {
+	if in == nil {
+		return nil
+	}
+	out := new(HarborClass)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HarborClass) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HarborClassDefaults) DeepCopyInto(out *HarborClassDefaults) {
+	*out = *in
+	if in.CertificateIssuerRef != nil {
+		in, out := &in.CertificateIssuerRef, &out.CertificateIssuerRef
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+	if in.PublicCertificateIssuerRef != nil {
+		in, out := &in.PublicCertificateIssuerRef, &out.PublicCertificateIssuerRef
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+	if in.Expose != nil {
+		in, out := &in.Expose, &out.Expose
+		*out = new(HarborExpose)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Deployment != nil {
+		in, out := &in.Deployment, &out.Deployment
+		*out = new(HarborDeploymentDefaults)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Components.DeepCopyInto(&out.Components)
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(HarborProxy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TrustedCA != nil {
+		in, out := &in.TrustedCA, &out.TrustedCA
+		*out = new(TrustedCA)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Monitoring != nil {
+		in, out := &in.Monitoring, &out.Monitoring
+		*out = new(HarborMonitoring)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HarborClassDefaults.
+func (in *HarborClassDefaults) DeepCopy() *HarborClassDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(HarborClassDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HarborClassList) DeepCopyInto(out *HarborClassList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HarborClass, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HarborClassList.
+func (in *HarborClassList) DeepCopy() *HarborClassList {
+	if in == nil {
+		return nil
+	}
+	out := new(HarborClassList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HarborClassList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HarborClassSpec) DeepCopyInto(out *HarborClassSpec) {
+	*out = *in
+	in.Defaults.DeepCopyInto(&out.Defaults)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HarborClassSpec.
+func (in *HarborClassSpec) DeepCopy() *HarborClassSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HarborClassSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HarborComponents) DeepCopyInto(out *HarborComponents) {
 	*out = *in
@@ -199,7 +487,7 @@ func (in *HarborDeployment) DeepCopyInto(out *HarborDeployment) {
 	}
 	if in.ImagePullSecrets != nil {
 		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
-		*out = make([]v1.LocalObjectReference, len(*in))
+		*out = make([]corev1.LocalObjectReference, len(*in))
 		copy(*out, *in)
 	}
 }
@@ -214,6 +502,58 @@ func (in *HarborDeployment) DeepCopy() *HarborDeployment {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HarborDeploymentDefaults) DeepCopyInto(out *HarborDeploymentDefaults) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(NodeSelector, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]corev1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HarborDeploymentDefaults.
+func (in *HarborDeploymentDefaults) DeepCopy() *HarborDeploymentDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(HarborDeploymentDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HarborExpose) DeepCopyInto(out *HarborExpose) {
+	*out = *in
+	if in.Route != nil {
+		in, out := &in.Route, &out.Route
+		*out = new(RouteExpose)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HarborExpose.
+func (in *HarborExpose) DeepCopy() *HarborExpose {
+	if in == nil {
+		return nil
+	}
+	out := new(HarborExpose)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HarborList) DeepCopyInto(out *HarborList) {
 	*out = *in
@@ -246,16 +586,106 @@ func (in *HarborList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HarborMonitoring) DeepCopyInto(out *HarborMonitoring) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make([]MetricsComponent, len(*in))
+		copy(*out, *in)
+	}
+	if in.DisableRules != nil {
+		in, out := &in.DisableRules, &out.DisableRules
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HarborMonitoring.
+func (in *HarborMonitoring) DeepCopy() *HarborMonitoring {
+	if in == nil {
+		return nil
+	}
+	out := new(HarborMonitoring)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HarborProxy) DeepCopyInto(out *HarborProxy) {
+	*out = *in
+	if in.NoProxy != nil {
+		in, out := &in.NoProxy, &out.NoProxy
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make([]ProxyComponent, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HarborProxy.
+func (in *HarborProxy) DeepCopy() *HarborProxy {
+	if in == nil {
+		return nil
+	}
+	out := new(HarborProxy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HarborSpec) DeepCopyInto(out *HarborSpec) {
 	*out = *in
+	if in.PublicCertificateIssuerRef != nil {
+		in, out := &in.PublicCertificateIssuerRef, &out.PublicCertificateIssuerRef
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+	in.Expose.DeepCopyInto(&out.Expose)
 	in.Components.DeepCopyInto(&out.Components)
 	if in.Priority != nil {
 		in, out := &in.Priority, &out.Priority
 		*out = new(int32)
 		**out = **in
 	}
+	if in.PausedComponents != nil {
+		in, out := &in.PausedComponents, &out.PausedComponents
+		*out = make([]PausableComponent, len(*in))
+		copy(*out, *in)
+	}
 	out.CertificateIssuerRef = in.CertificateIssuerRef
+	out.InternalTLS = in.InternalTLS
+	in.Certificates.DeepCopyInto(&out.Certificates)
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(HarborProxy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TrustedCA != nil {
+		in, out := &in.TrustedCA, &out.TrustedCA
+		*out = new(TrustedCA)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Monitoring != nil {
+		in, out := &in.Monitoring, &out.Monitoring
+		*out = new(HarborMonitoring)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SyntheticProbe != nil {
+		in, out := &in.SyntheticProbe, &out.SyntheticProbe
+		*out = new(HarborSyntheticProbe)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HarborSpec.
@@ -278,6 +708,28 @@ func (in *HarborStatus) DeepCopyInto(out *HarborStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Routes != nil {
+		in, out := &in.Routes, &out.Routes
+		*out = make([]RouteAdmissionStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Certificates != nil {
+		in, out := &in.Certificates, &out.Certificates
+		*out = make([]CertificateStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PublicCertificate != nil {
+		in, out := &in.PublicCertificate, &out.PublicCertificate
+		*out = new(PublicCertificateStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make([]ComponentStatus, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HarborStatus.
@@ -290,10 +742,50 @@ func (in *HarborStatus) DeepCopy() *HarborStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HarborSyntheticProbe) DeepCopyInto(out *HarborSyntheticProbe) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HarborSyntheticProbe.
+func (in *HarborSyntheticProbe) DeepCopy() *HarborSyntheticProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(HarborSyntheticProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InternalTLSSpec) DeepCopyInto(out *InternalTLSSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternalTLSSpec.
+func (in *InternalTLSSpec) DeepCopy() *InternalTLSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(InternalTLSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *JobServiceComponent) DeepCopyInto(out *JobServiceComponent) {
 	*out = *in
 	in.HarborDeployment.DeepCopyInto(&out.HarborDeployment)
+	if in.ConfigOverrides != nil {
+		in, out := &in.ConfigOverrides, &out.ConfigOverrides
+		*out = new(ConfigOverrides)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobServiceComponent.
@@ -369,6 +861,11 @@ func (in *NotaryDBMigrator) DeepCopy() *NotaryDBMigrator {
 func (in *NotaryServerComponent) DeepCopyInto(out *NotaryServerComponent) {
 	*out = *in
 	in.HarborDeployment.DeepCopyInto(&out.HarborDeployment)
+	if in.ConfigOverrides != nil {
+		in, out := &in.ConfigOverrides, &out.ConfigOverrides
+		*out = new(ConfigOverrides)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotaryServerComponent.
@@ -385,6 +882,11 @@ func (in *NotaryServerComponent) DeepCopy() *NotaryServerComponent {
 func (in *NotarySignerComponent) DeepCopyInto(out *NotarySignerComponent) {
 	*out = *in
 	in.HarborDeployment.DeepCopyInto(&out.HarborDeployment)
+	if in.ConfigOverrides != nil {
+		in, out := &in.ConfigOverrides, &out.ConfigOverrides
+		*out = new(ConfigOverrides)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotarySignerComponent.
@@ -413,11 +915,35 @@ func (in *PortalComponent) DeepCopy() *PortalComponent {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PublicCertificateStatus) DeepCopyInto(out *PublicCertificateStatus) {
+	*out = *in
+	if in.NotAfter != nil {
+		in, out := &in.NotAfter, &out.NotAfter
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PublicCertificateStatus.
+func (in *PublicCertificateStatus) DeepCopy() *PublicCertificateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PublicCertificateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RegistryComponent) DeepCopyInto(out *RegistryComponent) {
 	*out = *in
 	in.HarborDeployment.DeepCopyInto(&out.HarborDeployment)
 	in.Controller.DeepCopyInto(&out.Controller)
+	if in.ConfigOverrides != nil {
+		in, out := &in.ConfigOverrides, &out.ConfigOverrides
+		*out = new(ConfigOverrides)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryComponent.
@@ -449,3 +975,58 @@ func (in *RegistryControllerComponent) DeepCopy() *RegistryControllerComponent {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouteAdmissionStatus) DeepCopyInto(out *RouteAdmissionStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteAdmissionStatus.
+func (in *RouteAdmissionStatus) DeepCopy() *RouteAdmissionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RouteAdmissionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouteExpose) DeepCopyInto(out *RouteExpose) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteExpose.
+func (in *RouteExpose) DeepCopy() *RouteExpose {
+	if in == nil {
+		return nil
+	}
+	out := new(RouteExpose)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrustedCA) DeepCopyInto(out *TrustedCA) {
+	*out = *in
+	if in.Secret != nil {
+		in, out := &in.Secret, &out.Secret
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrustedCA.
+func (in *TrustedCA) DeepCopy() *TrustedCA {
+	if in == nil {
+		return nil
+	}
+	out := new(TrustedCA)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -2,249 +2,206 @@ package harbor
 
 import (
 	"context"
+	"encoding/base64"
 
-	certv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha2"
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
-	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
-	netv1 "k8s.io/api/networking/v1beta1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
 	"github.com/goharbor/harbor-operator/controllers/harbor/components"
+	"github.com/goharbor/harbor-operator/pkg/factories/logger"
 )
 
-func (r *Reconciler) ApplyMutationFunc(ctx context.Context, harbor *goharborv1alpha1.Harbor, resource components.Resource, result metav1.Object, mutate controllerutil.MutateFn) func() error {
-	return func() error {
-		// Immutable field
-		resourceVersion := result.GetResourceVersion()
-
-		defer func() { result.SetResourceVersion(resourceVersion) }()
+// GetFieldManager returns the field manager owning the fields applied by the operator.
+func (r *Reconciler) GetFieldManager() string {
+	return r.GetName()
+}
 
-		// Keep old annotation
-		// Often used by other controllers
-		annotations := result.GetAnnotations()
-		if annotations == nil {
-			annotations = map[string]string{}
-		}
+// PrepareResource sets annotations, labels and owner of the resource, and returns it
+// as the body of a server-side apply, with the live object, nil if missing.
+func (r *Reconciler) PrepareResource(ctx context.Context, harbor *goharborv1alpha1.Harbor, resource components.Resource) (*unstructured.Unstructured, *unstructured.Unstructured, error) {
+	gvk, err := apiutil.GVKForObject(resource, r.Scheme)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "cannot get kind")
+	}
 
-		defer func() {
-			for key, value := range result.GetAnnotations() {
-				annotations[key] = value
-			}
+	live := &unstructured.Unstructured{}
+	live.SetGroupVersionKind(gvk)
 
-			result.SetAnnotations(annotations)
+	err = r.Client.Get(ctx, client.ObjectKey{
+		Namespace: resource.GetNamespace(),
+		Name:      resource.GetName(),
+	}, live)
+	if err != nil {
+		if !apierrs.IsNotFound(err) {
+			return nil, nil, errors.Wrap(err, "cannot get live resource")
+		}
 
-			r.MutateAnnotations(ctx, result)
-		}()
+		live = nil
+	}
 
-		// Keep old labels
-		labels := result.GetLabels()
-		if labels == nil {
-			labels = map[string]string{}
-		}
+	desired, err := r.getDesiredState(ctx, harbor, gvk, resource, live)
 
-		defer func() {
-			for key, value := range result.GetLabels() {
-				labels[key] = value
-			}
+	return desired, live, err
+}
 
-			result.SetLabels(labels)
+// getDesiredState sets annotations, labels and owner of the resource, and returns it as the body of a server-side apply.
+func (r *Reconciler) getDesiredState(ctx context.Context, harbor *goharborv1alpha1.Harbor, gvk schema.GroupVersionKind, resource components.Resource, live *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	r.MutateAnnotations(ctx, resource)
+	r.MutateLabels(ctx, resource)
 
-			r.MutateLabels(ctx, result)
-		}()
+	// Set Harbor instance as the owner and controller of the resource
+	err := controllerutil.SetControllerReference(harbor, resource, r.Scheme)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot set controller reference")
+	}
 
-		err := mutate()
+	if secret, ok := resource.(*corev1.Secret); ok {
+		err := keepGeneratedValues(secret, live)
 		if err != nil {
-			return errors.Wrap(err, "cannot mutate resource")
+			return nil, err
 		}
-
-		// Set Harbor instance as the owner and controller of the resource
-		err = controllerutil.SetControllerReference(harbor, result, r.Scheme)
-
-		return errors.Wrapf(err, "cannot set controller reference for %s/%s", resource.GroupVersionKind().GroupKind(), resource.GetName())
 	}
-}
-
-func (r *Reconciler) ApplyResource(ctx context.Context, harbor *goharborv1alpha1.Harbor, resource components.Resource, objectFactory components.ResourceFactory, objectMutation components.ResourceMutationGetter) (components.Resource, error) {
-	kind, version := resource.GetObjectKind().GroupVersionKind().ToAPIVersionAndKind()
 
-	span, ctx := opentracing.StartSpanFromContext(ctx, "deployResource", opentracing.Tags{
-		"Resource.Kind":    kind,
-		"Resource.Version": version,
-	})
-	defer span.Finish()
-
-	// Get resource result from factory
-	result := objectFactory()
-	result.SetName(resource.GetName())
-	result.SetNamespace(resource.GetNamespace())
-
-	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, result, r.ApplyMutationFunc(ctx, harbor, resource, result, objectMutation(resource, result)))
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(resource)
 	if err != nil {
-		return nil, errors.Wrapf(err, "cannot create/update %s/%s", resource.GroupVersionKind().GroupKind(), resource.GetName())
+		return nil, errors.Wrap(err, "cannot convert resource")
 	}
 
-	span.SetTag("Resource.Operation", op)
+	desired := &unstructured.Unstructured{Object: pruneNullFields(content)}
+	desired.SetGroupVersionKind(gvk)
 
-	return result, nil
-}
+	// Status is not managed by the operator
+	delete(desired.Object, "status")
 
-func (r *Reconciler) ApplyResources(ctx context.Context, harbor *goharborv1alpha1.Harbor, resources []components.Resource, objectFactory func() components.Resource, objectMutation func(components.Resource, components.Resource) controllerutil.MutateFn) error {
-	var g errgroup.Group
-
-	for _, resource := range resources {
-		resource := resource
-
-		g.Go(func() error {
-			_, err := r.ApplyResource(ctx, harbor, resource, objectFactory, objectMutation)
-			return err
-		})
-	}
-
-	return g.Wait()
+	return desired, nil
 }
 
-func mutateSecret(secretResource, result components.Resource) controllerutil.MutateFn {
-	secretResult, ok := result.(*corev1.Secret)
-	secret := secretResource.(*corev1.Secret)
+// keepGeneratedValues keeps the values of existing keys of StringData.
+// Passwords are generated as StringData on every reconciliation, so they are not overridden.
+// To update a password, the key should be renamed or deleted before being recreated.
+// Data contains rendered configurations, it is always overridden.
+func keepGeneratedValues(secret *corev1.Secret, live *unstructured.Unstructured) error {
+	var liveData map[string]string
 
-	return func() error {
-		if !ok {
-			return errors.Errorf("unexpected argument %+v", result)
-		}
-
-		// Most of password are generated
-		// Do not override existing secrets
-		// To update secrets value, we should rename the key or
-		//  delete it before recreating it.
-		for key := range secretResult.Data {
-			_, okString := secret.StringData[key]
-			_, okBytes := secret.Data[key]
-
-			if !okString && !okBytes {
-				delete(secretResult.Data, key)
-			}
-
-			delete(secret.Data, key)
-			delete(secret.StringData, key)
-		}
-
-		if secretResult.Data == nil {
-			secretResult.Data = map[string][]byte{}
-		}
+	if live != nil {
+		var err error
 
-		for name, value := range secret.Data {
-			secretResult.Data[name] = value
+		liveData, _, err = unstructured.NestedStringMap(live.Object, "data")
+		if err != nil {
+			return errors.Wrap(err, "cannot get live secret data")
 		}
-
-		// StringData is write only, it overrides Data
-		// so we can compare with remote secretResult
-		secretResult.StringData = secret.StringData
-
-		return nil
 	}
-}
-
-func mutateCertificate(certificateResource, result components.Resource) controllerutil.MutateFn {
-	certificateResult, ok := result.(*certv1.Certificate)
-	certificate := certificateResource.(*certv1.Certificate)
 
-	return func() error {
-		if !ok {
-			return errors.Errorf("unexpected argument %+v", result)
-		}
-
-		certificate.DeepCopyInto(certificateResult)
-
-		return nil
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
 	}
-}
 
-func mutateService(serviceResource, result components.Resource) controllerutil.MutateFn {
-	serviceResult, ok := result.(*corev1.Service)
-	service := serviceResource.(*corev1.Service)
-
-	return func() error {
+	for key, value := range secret.StringData {
+		encoded, ok := liveData[key]
 		if !ok {
-			return errors.Errorf("unexpected argument %+v", result)
+			secret.Data[key] = []byte(value)
+			continue
 		}
 
-		// Immutable field
-		clusterIP := serviceResult.Spec.ClusterIP
-
-		defer func() { serviceResult.Spec.ClusterIP = clusterIP }()
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return errors.Wrapf(err, "cannot decode key %s of live secret", key)
+		}
 
-		for _, port := range serviceResult.Spec.Ports {
-			port := port
+		secret.Data[key] = decoded
+	}
 
-			defer func() {
-				ports := make([]corev1.ServicePort, len(serviceResult.Spec.Ports))
+	// StringData is write only, Data is applied so that it can be compared to the live secret
+	secret.StringData = nil
 
-				for i, p := range serviceResult.Spec.Ports {
-					if p.Name == port.Name {
-						p.NodePort = port.NodePort
-					}
+	return nil
+}
 
-					ports[i] = p
+// pruneNullFields removes fields without value, such as empty timestamps, from the content.
+func pruneNullFields(content map[string]interface{}) map[string]interface{} {
+	for key, value := range content {
+		switch value := value.(type) {
+		case nil:
+			delete(content, key)
+		case map[string]interface{}:
+			pruneNullFields(value)
+		case []interface{}:
+			for _, item := range value {
+				if item, ok := item.(map[string]interface{}); ok {
+					pruneNullFields(item)
 				}
-
-				serviceResult.Spec.Ports = ports
-			}()
+			}
 		}
-
-		service.DeepCopyInto(serviceResult)
-
-		return nil
 	}
+
+	return content
 }
 
-func mutateIngress(ingressResource, result components.Resource) controllerutil.MutateFn {
-	ingressResult, ok := result.(*netv1.Ingress)
-	ingress := ingressResource.(*netv1.Ingress)
+// ServerSideApply applies the desired state, taking ownership of conflicting fields.
+// desired is updated with the result.
+func (r *Reconciler) ServerSideApply(ctx context.Context, desired *unstructured.Unstructured) error {
+	return r.Client.Patch(ctx, desired, client.Apply, client.FieldOwner(r.GetFieldManager()), client.ForceOwnership)
+}
 
-	return func() error {
-		if !ok {
-			return errors.Errorf("unexpected argument %+v", result)
-		}
+// ApplyResource applies the resource using server-side apply.
+// Only fields set by the operator are owned, fields defaulted by the API server
+// or set by other controllers are kept.
+func (r *Reconciler) ApplyResource(ctx context.Context, harbor *goharborv1alpha1.Harbor, resource components.Resource) error {
+	kind, version := resource.GetObjectKind().GroupVersionKind().ToAPIVersionAndKind()
 
-		ingress.DeepCopyInto(ingressResult)
+	span, ctx := opentracing.StartSpanFromContext(ctx, "deployResource", opentracing.Tags{
+		"Resource.Kind":    kind,
+		"Resource.Version": version,
+	})
+	defer span.Finish()
 
-		return nil
+	desired, live, err := r.PrepareResource(ctx, harbor, resource)
+	if err == nil {
+		err = r.ServerSideApply(ctx, desired)
 	}
-}
-
-func mutateDeployment(deploymentResource, result components.Resource) controllerutil.MutateFn {
-	deploymentResult, ok := result.(*appsv1.Deployment)
-	deployment := deploymentResource.(*appsv1.Deployment)
 
-	return func() error {
-		if !ok {
-			return errors.Errorf("unexpected argument %+v", result)
-		}
+	if err != nil {
+		r.ResourceErrorEvent(ctx, harbor, resource, err)
 
-		deployment.DeepCopyInto(deploymentResult)
+		return errors.Wrapf(err, "cannot apply %s/%s", resource.GroupVersionKind().GroupKind(), resource.GetName())
+	}
 
-		return nil
+	switch {
+	case live == nil:
+		span.SetTag("Resource.Operation", controllerutil.OperationResultCreated)
+		r.ResourceEvent(ctx, harbor, resource, corev1.EventTypeNormal, CreatedReason, "created")
+	case live.GetResourceVersion() != desired.GetResourceVersion():
+		span.SetTag("Resource.Operation", controllerutil.OperationResultUpdated)
+		r.ResourceEvent(ctx, harbor, resource, corev1.EventTypeNormal, UpdatedReason, "updated")
+	default:
+		span.SetTag("Resource.Operation", controllerutil.OperationResultNone)
 	}
-}
 
-func mutateConfigMap(configResource, result components.Resource) controllerutil.MutateFn {
-	configResult, ok := result.(*corev1.ConfigMap)
-	config := configResource.(*corev1.ConfigMap)
+	return nil
+}
 
-	return func() error {
-		if !ok {
-			return errors.Errorf("unexpected argument %+v", result)
-		}
+func (r *Reconciler) ApplyResources(ctx context.Context, harbor *goharborv1alpha1.Harbor, resources []components.Resource) error {
+	var g errgroup.Group
 
-		config.DeepCopyInto(configResult)
+	for _, resource := range resources {
+		resource := resource
 
-		return nil
+		g.Go(func() error {
+			return r.ApplyResource(ctx, harbor, resource)
+		})
 	}
+
+	return g.Wait()
 }
 
 // +kubebuilder:rbac:groups="",resources="configmaps",verbs=get;list;watch;update;patch;create
@@ -252,60 +209,48 @@ func mutateConfigMap(configResource, result components.Resource) controllerutil.
 // +kubebuilder:rbac:groups="cert-manager.io",resources="certificates",verbs=get;list;watch;update;patch;create
 // +kubebuilder:rbac:groups="",resources="services",verbs=get;list;watch;update;patch;create
 // +kubebuilder:rbac:groups="networking.k8s.io",resources="ingresses",verbs=get;list;watch;update;patch;create
+// +kubebuilder:rbac:groups="route.openshift.io",resources="routes",verbs=get;list;watch;update;patch;create
+// +kubebuilder:rbac:groups="route.openshift.io",resources="routes/custom-host",verbs=create
 // +kubebuilder:rbac:groups="apps",resources="deployments",verbs=get;list;watch;update;patch;create
 
 func (r *Reconciler) ApplyComponent(ctx context.Context, harbor *goharborv1alpha1.Harbor, component *components.ComponentRunner) error {
-	service := func(ctx context.Context, harbor *goharborv1alpha1.Harbor, resources []components.Resource) error {
-		return r.ApplyResources(ctx, harbor, resources, func() components.Resource { return &corev1.Service{} }, mutateService)
-	}
-	configMap := func(ctx context.Context, harbor *goharborv1alpha1.Harbor, resources []components.Resource) error {
-		return r.ApplyResources(ctx, harbor, resources, func() components.Resource { return &corev1.ConfigMap{} }, mutateConfigMap)
-	}
-	ingress := func(ctx context.Context, harbor *goharborv1alpha1.Harbor, resources []components.Resource) error {
-		return r.ApplyResources(ctx, harbor, resources, func() components.Resource { return &netv1.Ingress{} }, mutateIngress)
-	}
-	secret := func(ctx context.Context, harbor *goharborv1alpha1.Harbor, resources []components.Resource) error {
-		return r.ApplyResources(ctx, harbor, resources, func() components.Resource { return &corev1.Secret{} }, mutateSecret)
-	}
-	certificate := func(ctx context.Context, harbor *goharborv1alpha1.Harbor, resources []components.Resource) error {
-		return r.ApplyResources(ctx, harbor, resources, func() components.Resource { return &certv1.Certificate{} }, mutateCertificate)
+	if isComponentPaused(ctx, harbor) {
+		return nil
 	}
-	deployment := func(ctx context.Context, harbor *goharborv1alpha1.Harbor, resources []components.Resource) error {
-		return r.ApplyResources(ctx, harbor, resources, func() components.Resource { return &appsv1.Deployment{} }, mutateDeployment)
+
+	deployment := r.WithTrustedCAChecksum(r.WithInternalTLSChecksum(r.ApplyResources))
+
+	err := component.ParallelRun(ctx, harbor, r.ApplyResources, r.ApplyResources, r.ApplyResources, r.WithRoutesTLS(r.ApplyResources), r.ApplyResources, r.ApplyResources, deployment, true)
+	if err != nil {
+		return err
 	}
 
-	return component.ParallelRun(ctx, harbor, service, configMap, ingress, secret, certificate, deployment, true)
+	return r.ApplyMonitoring(ctx, harbor, component)
 }
 
-func (r *Reconciler) Apply(ctx context.Context, harbor *goharborv1alpha1.Harbor) error {
-	span, ctx := opentracing.StartSpanFromContext(ctx, "apply")
-	defer span.Finish()
+// Apply applies the resources of components whose desired state changed since their last apply, or of every component on resync.
+// Resources of other components are only created if missing, and checked for drifts.
+// Drifts are returned when reported instead of corrected.
+func (r *Reconciler) Apply(ctx context.Context, harbor *goharborv1alpha1.Harbor, harborResources *components.Components, hashes map[string]string, resync bool) ([]Drift, error) {
+	ctx, drifts := withDriftCollector(ctx)
 
-	harborResource, err := components.GetComponents(ctx, harbor)
-	if err != nil {
-		return errors.Wrap(err, "cannot get resources to manage")
-	}
+	err := harborResources.ParallelRun(ctx, harbor, func(ctx context.Context, harbor *goharborv1alpha1.Harbor, component *components.ComponentRunner) error {
+		name := components.ComponentName(ctx)
+		hash := hashes[name]
 
-	var g errgroup.Group
+		ctx = withDesiredStateHash(ctx, hash)
 
-	if harbor.Spec.Components.Clair == nil {
-		g.Go(func() error {
-			err := r.DeleteComponent(ctx, harbor, goharborv1alpha1.ClairName)
-			return errors.Wrap(err, "cannot delete clair")
-		})
-	}
+		if !resync && harbor.Status.GetDesiredStateHash(name) == hash {
+			logger.Get(ctx).V(1).Info("desired state unchanged, checking resources only")
 
-	if harbor.Spec.Components.Notary == nil {
-		g.Go(func() error {
-			err := r.DeleteComponent(ctx, harbor, goharborv1alpha1.NotaryName)
-			return errors.Wrap(err, "cannot delete notary")
-		})
-	}
+			return r.CreateComponent(ctx, harbor, component)
+		}
 
-	g.Go(func() error {
-		err = harborResource.ParallelRun(ctx, harbor, r.ApplyComponent)
-		return errors.Wrap(err, "cannot deploy component")
+		return r.ApplyComponent(ctx, harbor, component)
 	})
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot deploy component")
+	}
 
-	return g.Wait()
+	return drifts.get(), nil
 }
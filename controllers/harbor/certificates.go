@@ -0,0 +1,161 @@
+package harbor
+
+import (
+	"context"
+
+	certv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha2"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components"
+)
+
+const (
+	// https://github.com/jetstack/cert-manager/blob/v0.12.0/pkg/util/pki/csr.go
+	certManagerDefaultRSAKeySize   = 2048
+	certManagerDefaultECDSAKeySize = 256
+)
+
+// ApplyLegacyCertificates sets the key settings of the deprecated certificate-encryption configstore slice
+// on the registry token and notary certificates whose key is not set by the Harbor.
+// They are only set in memory, as the defaults of HarborClasses.
+func (r *Reconciler) ApplyLegacyCertificates(harbor *goharborv1alpha1.Harbor) {
+	legacy := r.Config.LegacyCertificates
+	if legacy == nil {
+		return
+	}
+
+	harbor.Spec.Certificates.RegistryToken = withLegacyKey(harbor.Spec.Certificates.RegistryToken, legacy)
+	harbor.Spec.Certificates.Notary = withLegacyKey(harbor.Spec.Certificates.Notary, legacy)
+}
+
+func withLegacyKey(settings, legacy *goharborv1alpha1.CertificateSettings) *goharborv1alpha1.CertificateSettings {
+	if settings == nil {
+		settings = &goharborv1alpha1.CertificateSettings{}
+	}
+
+	if settings.KeyAlgorithm == "" && settings.KeySize == 0 {
+		settings.KeyAlgorithm = legacy.KeyAlgorithm
+		settings.KeySize = legacy.KeySize
+	}
+
+	return settings
+}
+
+// UpdateCertificatesStatus reports the settings used to issue the certificates of the Harbor.
+func (r *Reconciler) UpdateCertificatesStatus(ctx context.Context, harbor *goharborv1alpha1.Harbor) error {
+	harborResource, err := components.GetComponents(ctx, harbor)
+	if err != nil {
+		return errors.Wrap(err, "cannot get resources to manage")
+	}
+
+	var statuses []goharborv1alpha1.CertificateStatus
+
+	for _, component := range []*components.ComponentRunner{harborResource.Core, harborResource.Registry, harborResource.JobService, harborResource.Portal, harborResource.ChartMuseum, harborResource.Clair, harborResource.Notary} {
+		if component == nil {
+			continue
+		}
+
+		for _, certificate := range component.Component.GetCertificates(ctx) {
+			statuses = append(statuses, getCertificateStatus(certificate))
+		}
+	}
+
+	harbor.Status.Certificates = statuses
+
+	return nil
+}
+
+func getCertificateStatus(certificate *certv1.Certificate) goharborv1alpha1.CertificateStatus {
+	status := goharborv1alpha1.CertificateStatus{
+		Name:         certificate.GetName(),
+		KeyAlgorithm: certificate.Spec.KeyAlgorithm,
+		KeySize:      certificate.Spec.KeySize,
+		Duration:     certificate.Spec.Duration,
+		RenewBefore:  certificate.Spec.RenewBefore,
+	}
+
+	if status.KeyAlgorithm == "" {
+		status.KeyAlgorithm = certv1.RSAKeyAlgorithm
+	}
+
+	if status.KeySize == 0 {
+		switch status.KeyAlgorithm {
+		case certv1.ECDSAKeyAlgorithm:
+			status.KeySize = certManagerDefaultECDSAKeySize
+		default:
+			status.KeySize = certManagerDefaultRSAKeySize
+		}
+	}
+
+	return status
+}
+
+// UpdatePublicCertificateStatus reports the readiness of the certificate issued for public hosts.
+func (r *Reconciler) UpdatePublicCertificateStatus(ctx context.Context, harbor *goharborv1alpha1.Harbor) error {
+	if !harbor.IsPublicCertificateIssued() {
+		harbor.Status.PublicCertificate = nil
+
+		return nil
+	}
+
+	name := harbor.NormalizeComponentName(goharborv1alpha1.PublicCertificateName)
+	certificate := &certv1.Certificate{}
+
+	err := r.Client.Get(ctx, client.ObjectKey{
+		Namespace: harbor.GetNamespace(),
+		Name:      name,
+	}, certificate)
+	if err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return errors.Wrapf(err, "cannot get certificate %s", name)
+		}
+
+		harbor.Status.PublicCertificate = &goharborv1alpha1.PublicCertificateStatus{
+			SecretName: harbor.GetTLSSecretName(),
+			Ready:      corev1.ConditionUnknown,
+			Reason:     "NotFound",
+		}
+
+		return nil
+	}
+
+	previous := harbor.Status.PublicCertificate
+
+	harbor.Status.PublicCertificate = getPublicCertificateStatus(certificate)
+
+	if previous != nil && previous.Ready == harbor.Status.PublicCertificate.Ready {
+		return nil
+	}
+
+	switch harbor.Status.PublicCertificate.Ready {
+	case corev1.ConditionTrue:
+		r.Recorder.Eventf(harbor, corev1.EventTypeNormal, CertificateIssuedReason, "Certificate %s issued for public hosts in secret %s", name, harbor.Status.PublicCertificate.SecretName)
+	case corev1.ConditionFalse:
+		r.Recorder.Eventf(harbor, corev1.EventTypeWarning, CertificateFailedReason, "Certificate %s for public hosts is not ready: %s", name, harbor.Status.PublicCertificate.Message)
+	}
+
+	return nil
+}
+
+func getPublicCertificateStatus(certificate *certv1.Certificate) *goharborv1alpha1.PublicCertificateStatus {
+	status := &goharborv1alpha1.PublicCertificateStatus{
+		SecretName: certificate.Spec.SecretName,
+		Ready:      corev1.ConditionUnknown,
+		NotAfter:   certificate.Status.NotAfter,
+	}
+
+	for _, condition := range certificate.Status.Conditions {
+		if condition.Type != certv1.CertificateConditionReady {
+			continue
+		}
+
+		status.Ready = corev1.ConditionStatus(condition.Status)
+		status.Reason = condition.Reason
+		status.Message = condition.Message
+	}
+
+	return status
+}
@@ -0,0 +1,176 @@
+package harbor
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	certv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha2"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+)
+
+var _ = Describe("certificates", func() {
+	Describe("A certificate without key settings", func() {
+		It("Should report cert-manager defaults", func() {
+			status := getCertificateStatus(&certv1.Certificate{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "harbor-core-internal-tls",
+				},
+			})
+			Expect(status.Name).To(Equal("harbor-core-internal-tls"))
+			Expect(status.KeyAlgorithm).To(Equal(certv1.RSAKeyAlgorithm))
+			Expect(status.KeySize).To(Equal(certManagerDefaultRSAKeySize))
+		})
+	})
+
+	Describe("Certificate settings", func() {
+		var settings *goharborv1alpha1.CertificateSettings
+
+		BeforeEach(func() {
+			settings = &goharborv1alpha1.CertificateSettings{
+				KeyAlgorithm: certv1.ECDSAKeyAlgorithm,
+				Duration:     &metav1.Duration{Duration: 24 * time.Hour},
+				DNSNames:     []string{"notary.example.com"},
+			}
+		})
+
+		It("Should be applied to the certificate", func() {
+			spec := certv1.CertificateSpec{
+				DNSNames: []string{"harbor-notary-signer"},
+			}
+
+			settings.ApplyTo(&spec)
+
+			Expect(spec.KeyAlgorithm).To(Equal(certv1.ECDSAKeyAlgorithm))
+			Expect(spec.KeySize).To(Equal(goharborv1alpha1.DefaultECDSAKeySize))
+			Expect(spec.Duration.Duration).To(Equal(24 * time.Hour))
+			Expect(spec.DNSNames).To(ConsistOf("harbor-notary-signer", "notary.example.com"))
+		})
+
+		It("Should default to rsa 4096", func() {
+			var defaults *goharborv1alpha1.CertificateSettings
+
+			spec := certv1.CertificateSpec{}
+			defaults.ApplyTo(&spec)
+
+			Expect(spec.KeyAlgorithm).To(Equal(certv1.RSAKeyAlgorithm))
+			Expect(spec.KeySize).To(Equal(goharborv1alpha1.DefaultRSAKeySize))
+		})
+
+		It("Should be valid for notary", func() {
+			harbor := &goharborv1alpha1.Harbor{}
+			harbor.Spec.Certificates.Notary = settings
+
+			Expect(harbor.Validate()).To(Succeed())
+		})
+
+		It("Should be rejected for registry tokens", func() {
+			harbor := &goharborv1alpha1.Harbor{}
+			harbor.Spec.Certificates.RegistryToken = settings
+
+			err := harbor.Validate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.certificates.registryToken.keyAlgorithm"))
+		})
+
+		It("Should reject invalid sizes and lifetimes", func() {
+			settings.KeySize = 4096
+			settings.RenewBefore = &metav1.Duration{Duration: 48 * time.Hour}
+
+			harbor := &goharborv1alpha1.Harbor{}
+			harbor.Spec.Certificates.Notary = settings
+
+			err := harbor.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.certificates.notary.keySize"))
+			Expect(err.Error()).To(ContainSubstring("spec.certificates.notary.renewBefore"))
+		})
+	})
+
+	Describe("The deprecated certificate encryption", func() {
+		It("Should apply to certificates without key settings", func() {
+			r := &Reconciler{Config: Config{
+				LegacyCertificates: &goharborv1alpha1.CertificateSettings{KeySize: 2048},
+			}}
+
+			harbor := &goharborv1alpha1.Harbor{}
+			harbor.Spec.Certificates.Notary = &goharborv1alpha1.CertificateSettings{KeyAlgorithm: certv1.ECDSAKeyAlgorithm}
+
+			r.ApplyLegacyCertificates(harbor)
+
+			Expect(harbor.Spec.Certificates.RegistryToken.KeySize).To(Equal(2048))
+			Expect(harbor.Spec.Certificates.Notary.KeySize).To(BeZero())
+		})
+	})
+
+	Describe("An Harbor with https public URLs", func() {
+		var harbor *goharborv1alpha1.Harbor
+
+		BeforeEach(func() {
+			harbor = &goharborv1alpha1.Harbor{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "my",
+				},
+				Spec: goharborv1alpha1.HarborSpec{
+					PublicURL: "https://harbor.example.com",
+					Components: goharborv1alpha1.HarborComponents{
+						Notary: &goharborv1alpha1.NotaryComponent{
+							PublicURL: "https://notary.example.com:8443",
+						},
+					},
+				},
+			}
+		})
+
+		It("Should issue a certificate for public hosts", func() {
+			Expect(harbor.IsPublicCertificateIssued()).To(BeTrue())
+			Expect(harbor.GetPublicHosts()).To(ConsistOf("harbor.example.com", "notary.example.com"))
+			Expect(harbor.GetTLSSecretName()).To(Equal("my-public-tls"))
+		})
+
+		It("Should use the provided TLS secret", func() {
+			harbor.Spec.TLSSecretName = "wildcard"
+
+			Expect(harbor.IsPublicCertificateIssued()).To(BeFalse())
+			Expect(harbor.GetTLSSecretName()).To(Equal("wildcard"))
+		})
+
+		It("Should report the readiness given by cert-manager", func() {
+			status := getPublicCertificateStatus(&certv1.Certificate{
+				Spec: certv1.CertificateSpec{
+					SecretName: "my-public-tls",
+				},
+				Status: certv1.CertificateStatus{
+					Conditions: []certv1.CertificateCondition{{
+						Type:    certv1.CertificateConditionReady,
+						Status:  cmmeta.ConditionFalse,
+						Reason:  "Pending",
+						Message: "Waiting for order",
+					}},
+				},
+			})
+
+			Expect(status.SecretName).To(Equal("my-public-tls"))
+			Expect(status.Ready).To(Equal(corev1.ConditionFalse))
+			Expect(status.Reason).To(Equal("Pending"))
+		})
+	})
+
+	Describe("An Harbor with http public URLs", func() {
+		It("Should not issue any certificate", func() {
+			harbor := &goharborv1alpha1.Harbor{
+				Spec: goharborv1alpha1.HarborSpec{
+					PublicURL: "http://harbor.example.com",
+				},
+			}
+
+			Expect(harbor.IsPublicCertificateIssued()).To(BeFalse())
+		})
+	})
+})
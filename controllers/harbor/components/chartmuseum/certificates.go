@@ -4,8 +4,11 @@ import (
 	"context"
 
 	certv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha2"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/internaltls"
 )
 
-func (*ChartMuseum) GetCertificates(ctx context.Context) []*certv1.Certificate {
-	return []*certv1.Certificate{}
+func (c *ChartMuseum) GetCertificates(ctx context.Context) []*certv1.Certificate {
+	return internaltls.GetCertificates(ctx, c.harbor, goharborv1alpha1.ChartMuseumName, c.harbor.NormalizeComponentName(goharborv1alpha1.ChartMuseumName))
 }
@@ -4,15 +4,14 @@ import (
 	"context"
 	"crypto/sha256"
 	"fmt"
-	"io/ioutil"
-	"sync"
 
-	"github.com/markbates/pkger"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/render"
 	"github.com/goharbor/harbor-operator/pkg/factories/application"
 )
 
@@ -20,29 +19,106 @@ const (
 	configName = "config.yaml"
 )
 
-var (
-	once   sync.Once
-	config []byte
-)
+// https://github.com/helm/chartmuseum#configuration
+// https://github.com/goharbor/harbor/blob/master/make/photon/prepare/templates/chartserver/env.jinja
+const configTemplate = `allow.overwrite: true
+
+auth.anonymous.get: false
+basic.auth.user: chart_controller
+bearerauth: 0
+{{- with .Redis }}
+
+cache: redis
+cache.redis:
+  addr: {{ quote .Addr }}
+  {{- if .Password }}
+  password: {{ quote .Password }}
+  {{- end }}
+  db: {{ .DB }}
+{{- end }}
+
+context.path: ''
+
+debug: {{ .Debug }}
+
+depth: 1
+
+disable:
+  api: false
+  delete: false
+  force.overwrite: false
+  metrics: false
+  statefiles: false
 
-func InitConfigMaps() {
-	file, err := pkger.Open("/assets/templates/chartmuseum/config.yaml")
+index.limit: 0
+
+log:
+  health: false
+  json: {{ .JSONLog }}
+
+max:
+  storage.objects: 0
+  upload.size: 20971520
+
+chart.post.form.field.name: chart
+prov.post.form.field.name: prov
+`
+
+type configData struct {
+	Redis   *render.Redis
+	Debug   bool
+	JSONLog bool
+}
+
+// The configuration contains the cache password, it is stored in a secret.
+func (c *ChartMuseum) RenderConfigs(ctx context.Context, reader client.Reader) error {
+	redis, err := c.getRedis(ctx, reader)
 	if err != nil {
-		panic(errors.Wrapf(err, "cannot open ChartMuseum configuration template %s", "/assets/templates/chartmuseum/config.yaml"))
+		return errors.Wrap(err, "cannot get cache")
 	}
-	defer file.Close()
 
-	config, err = ioutil.ReadAll(file)
+	config, err := render.Template(configName, configTemplate, configData{
+		Redis:   redis,
+		Debug:   c.harbor.Spec.Components.ChartMuseum.GetLogLevel() == goharborv1alpha1.DebugLogLevel,
+		JSONLog: c.harbor.Spec.Components.ChartMuseum.GetLogFormat() == goharborv1alpha1.JSONLogFormat,
+	})
 	if err != nil {
-		panic(errors.Wrapf(err, "cannot read ChartMuseum configuration template %s", "/assets/templates/chartmuseum/config.yaml"))
+		return errors.Wrap(err, "cannot render chartmuseum configuration")
 	}
+
+	config, err = render.Override(ctx, reader, c.harbor.Namespace, c.harbor.Spec.Components.ChartMuseum.ConfigOverrides, configName, config)
+	if err != nil {
+		return errors.Wrap(err, "cannot override chartmuseum configuration")
+	}
+
+	c.secretConfigs = render.Configs{
+		configName: config,
+	}
+
+	return nil
+}
+
+func (c *ChartMuseum) getRedis(ctx context.Context, reader client.Reader) (*render.Redis, error) {
+	if c.harbor.Spec.Components.ChartMuseum.CacheSecret == "" {
+		return nil, nil
+	}
+
+	secret, err := render.GetSecret(ctx, reader, c.harbor.Namespace, c.harbor.Spec.Components.ChartMuseum.CacheSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	redisURL := render.GetSecretValue(secret, goharborv1alpha1.HarborChartMuseumCacheURLKey, "")
+	if redisURL == "" {
+		return nil, nil
+	}
+
+	return render.ParseRedisURL(redisURL)
 }
 
 // https://github.com/goharbor/harbor/blob/master/make/photon/prepare/templates/chartserver/env.jinja
 
 func (c *ChartMuseum) GetConfigMaps(ctx context.Context) []*corev1.ConfigMap {
-	once.Do(InitConfigMaps)
-
 	operatorName := application.GetName(ctx)
 	harborName := c.harbor.Name
 
@@ -57,9 +133,6 @@ func (c *ChartMuseum) GetConfigMaps(ctx context.Context) []*corev1.ConfigMap {
 					"operator": operatorName,
 				},
 			},
-			BinaryData: map[string][]byte{
-				configName: config,
-			},
 			Data: map[string]string{
 				"PORT":      fmt.Sprintf("%d", port),
 				"CHART_URL": fmt.Sprintf("%s/chartrepo", c.harbor.Spec.PublicURL),
@@ -69,9 +142,8 @@ func (c *ChartMuseum) GetConfigMaps(ctx context.Context) []*corev1.ConfigMap {
 }
 
 func (c *ChartMuseum) GetConfigMapsCheckSum() string {
-	value := fmt.Sprintf("%s\n%d\n%x", c.harbor.Spec.PublicURL, port, config)
+	value := fmt.Sprintf("%s\n%d", c.harbor.Spec.PublicURL, port)
 	sum := sha256.New().Sum([]byte(value))
 
-	// todo get generation of the secret
 	return fmt.Sprintf("%x", sum)
 }
@@ -10,17 +10,19 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/internaltls"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/proxy"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/render"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/trustedca"
 	"github.com/goharbor/harbor-operator/pkg/factories/application"
 )
 
 var (
 	revisionHistoryLimit int32 = 0 // nolint:golint
 	varFalse                   = false
-	varTrue                    = true
 )
 
 const (
-	initImage  = "hairyhenderson/gomplate"
 	configPath = "/etc/chartmuseum/"
 	port       = 8080 // https://github.com/helm/chartmuseum/blob/969515a51413e1f1840fb99509401aa3c63deccd/pkg/config/vars.go#L135
 )
@@ -49,7 +51,6 @@ func (c *ChartMuseum) GetDeployments(ctx context.Context) []*appsv1.Deployment {
 		Value: "/mnt/chartmuseum",
 	}}
 	envFroms := []corev1.EnvFromSource{}
-	initEnv := []corev1.EnvVar{}
 
 	if c.harbor.Spec.Components.ChartMuseum.StorageSecret != "" {
 		volumes = []corev1.Volume{}
@@ -87,21 +88,22 @@ func (c *ChartMuseum) GetDeployments(ctx context.Context) []*appsv1.Deployment {
 		}}
 	}
 
-	if c.harbor.Spec.Components.ChartMuseum.CacheSecret != "" {
-		initEnv = []corev1.EnvVar{
-			{
-				Name: "CACHE_URL",
-				ValueFrom: &corev1.EnvVarSource{
-					SecretKeyRef: &corev1.SecretKeySelector{
-						LocalObjectReference: corev1.LocalObjectReference{
-							Name: c.harbor.Spec.Components.ChartMuseum.CacheSecret,
-						},
-						Key:      goharborv1alpha1.HarborChartMuseumCacheURLKey,
-						Optional: &varTrue,
-					},
-				},
-			},
-		}
+	volumes = append(volumes, internaltls.GetVolumes(c.harbor, goharborv1alpha1.ChartMuseumName)...)
+	volumeMounts = append(volumeMounts, internaltls.GetVolumeMounts(c.harbor)...)
+
+	volumes = append(volumes, trustedca.GetVolumes(c.harbor)...)
+	volumeMounts = append(volumeMounts, trustedca.GetVolumeMounts(c.harbor)...)
+	envs = append(envs, proxy.GetEnv(c.harbor, goharborv1alpha1.ChartMuseumProxyComponent)...)
+
+	if c.harbor.Spec.InternalTLS.IsEnabled() {
+		// https://github.com/helm/chartmuseum#https
+		envs = append(envs, corev1.EnvVar{
+			Name:  "TLS_CERT",
+			Value: internaltls.CertificateFile,
+		}, corev1.EnvVar{
+			Name:  "TLS_KEY",
+			Value: internaltls.KeyFile,
+		})
 	}
 
 	return []*appsv1.Deployment{
@@ -144,41 +146,18 @@ func (c *ChartMuseum) GetDeployments(ctx context.Context) []*appsv1.Deployment {
 							{
 								Name: "config",
 								VolumeSource: corev1.VolumeSource{
-									EmptyDir: &corev1.EmptyDirVolumeSource{},
-								},
-							}, {
-								Name: "config-template",
-								VolumeSource: corev1.VolumeSource{
-									ConfigMap: &corev1.ConfigMapVolumeSource{
-										LocalObjectReference: corev1.LocalObjectReference{
-											Name: c.harbor.NormalizeComponentName(goharborv1alpha1.ChartMuseumName),
+									Secret: &corev1.SecretVolumeSource{
+										SecretName: render.GetConfigSecretName(c.harbor, goharborv1alpha1.ChartMuseumName),
+										Items: []corev1.KeyToPath{
+											{
+												Key:  configName,
+												Path: configName,
+											},
 										},
 									},
 								},
 							},
 						}, volumes...),
-						InitContainers: []corev1.Container{
-							{
-								Name:            "configuration",
-								Image:           initImage,
-								WorkingDir:      "/workdir",
-								Args:            []string{"--input-dir", "/workdir", "--output-dir", "/processed"},
-								SecurityContext: &corev1.SecurityContext{},
-								VolumeMounts: []corev1.VolumeMount{
-									{
-										Name:      "config-template",
-										MountPath: path.Join("/workdir", configName),
-										ReadOnly:  true,
-										SubPath:   configName,
-									}, {
-										Name:      "config",
-										MountPath: "/processed",
-										ReadOnly:  false,
-									},
-								},
-								Env: initEnv,
-							},
-						},
 						Containers: []corev1.Container{
 							{
 								Name:  "chartmuseum",
@@ -225,16 +204,18 @@ func (c *ChartMuseum) GetDeployments(ctx context.Context) []*appsv1.Deployment {
 								LivenessProbe: &corev1.Probe{
 									Handler: corev1.Handler{
 										HTTPGet: &corev1.HTTPGetAction{
-											Path: "/health",
-											Port: intstr.FromInt(port),
+											Path:   "/health",
+											Port:   intstr.FromInt(port),
+											Scheme: internaltls.GetURIScheme(c.harbor),
 										},
 									},
 								},
 								ReadinessProbe: &corev1.Probe{
 									Handler: corev1.Handler{
 										HTTPGet: &corev1.HTTPGetAction{
-											Path: "/health",
-											Port: intstr.FromInt(port),
+											Path:   "/health",
+											Port:   intstr.FromInt(port),
+											Scheme: internaltls.GetURIScheme(c.harbor),
 										},
 									},
 								},
@@ -244,7 +225,6 @@ func (c *ChartMuseum) GetDeployments(ctx context.Context) []*appsv1.Deployment {
 					},
 				},
 				RevisionHistoryLimit: &revisionHistoryLimit,
-				Paused:               c.harbor.Spec.Paused,
 			},
 		},
 	}
@@ -11,10 +11,15 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/internaltls"
 	"github.com/goharbor/harbor-operator/pkg/factories/application"
 )
 
 func (c *ChartMuseum) GetIngresses(ctx context.Context) []*netv1.Ingress { // nolint:funlen
+	if c.harbor.Spec.Expose.GetType() != goharborv1alpha1.IngressExposeType {
+		return []*netv1.Ingress{}
+	}
+
 	operatorName := application.GetName(ctx)
 	harborName := c.harbor.Name
 
@@ -29,7 +34,7 @@ func (c *ChartMuseum) GetIngresses(ctx context.Context) []*netv1.Ingress { // no
 	if u.Scheme == "https" {
 		tls = []netv1.IngressTLS{
 			{
-				SecretName: c.harbor.Spec.TLSSecretName,
+				SecretName: c.harbor.GetTLSSecretName(),
 			},
 		}
 	}
@@ -37,8 +42,9 @@ func (c *ChartMuseum) GetIngresses(ctx context.Context) []*netv1.Ingress { // no
 	return []*netv1.Ingress{
 		{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      c.harbor.NormalizeComponentName(goharborv1alpha1.ChartMuseumName),
-				Namespace: c.harbor.Namespace,
+				Name:        c.harbor.NormalizeComponentName(goharborv1alpha1.ChartMuseumName),
+				Namespace:   c.harbor.Namespace,
+				Annotations: internaltls.GetIngressAnnotations(c.harbor),
 				Labels: map[string]string{
 					"app":      goharborv1alpha1.ChartMuseumName,
 					"harbor":   harborName,
@@ -57,7 +63,7 @@ func (c *ChartMuseum) GetIngresses(ctx context.Context) []*netv1.Ingress { // no
 										Path: "/chartrepo",
 										Backend: netv1.IngressBackend{
 											ServiceName: c.harbor.NormalizeComponentName(goharborv1alpha1.CoreName),
-											ServicePort: intstr.FromInt(PublicPort),
+											ServicePort: intstr.FromInt(int(internaltls.GetPublicPort(c.harbor, PublicPort))),
 										},
 									},
 								},
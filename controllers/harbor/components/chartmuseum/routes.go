@@ -0,0 +1,67 @@
+package chartmuseum
+
+import (
+	"context"
+	"net/url"
+
+	routev1 "github.com/openshift/api/route/v1"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/pkg/factories/application"
+)
+
+// corePort is the port of core, which proxies chart repositories to chartmuseum.
+const corePort = 8080 // https://github.com/goharbor/harbor/blob/2fb1cc89d9ef9313842cc68b4b7c36be73681505/src/common/const.go#L127
+
+func (c *ChartMuseum) GetRoutes(ctx context.Context) ([]*routev1.Route, error) {
+	if c.harbor.Spec.Expose.GetType() != goharborv1alpha1.RouteExposeType {
+		return []*routev1.Route{}, nil
+	}
+
+	operatorName := application.GetName(ctx)
+	harborName := c.harbor.Name
+
+	u, err := url.Parse(c.harbor.Spec.PublicURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid url")
+	}
+
+	host := u.Hostname()
+
+	var tls *routev1.TLSConfig
+	if u.Scheme == "https" {
+		tls = &routev1.TLSConfig{
+			Termination:                   routev1.TLSTerminationType(c.harbor.Spec.Expose.GetRouteTermination()),
+			InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+		}
+	}
+
+	return []*routev1.Route{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      c.harbor.NormalizeComponentName(goharborv1alpha1.ChartMuseumName),
+				Namespace: c.harbor.Namespace,
+				Labels: map[string]string{
+					"app":      goharborv1alpha1.ChartMuseumName,
+					"harbor":   harborName,
+					"operator": operatorName,
+				},
+			},
+			Spec: routev1.RouteSpec{
+				Host: host,
+				Path: "/chartrepo",
+				To: routev1.RouteTargetReference{
+					Kind: "Service",
+					Name: c.harbor.NormalizeComponentName(goharborv1alpha1.CoreName),
+				},
+				Port: &routev1.RoutePort{
+					TargetPort: intstr.FromInt(corePort),
+				},
+				TLS: tls,
+			},
+		},
+	}, nil
+}
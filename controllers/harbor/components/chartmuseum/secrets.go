@@ -6,15 +6,39 @@ import (
 	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/render"
+	"github.com/goharbor/harbor-operator/pkg/factories/application"
 )
 
-func (*ChartMuseum) GetSecrets(ctx context.Context) []*corev1.Secret {
-	return []*corev1.Secret{}
+func (c *ChartMuseum) GetSecrets(ctx context.Context) []*corev1.Secret {
+	operatorName := application.GetName(ctx)
+	harborName := c.harbor.Name
+
+	return []*corev1.Secret{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      render.GetConfigSecretName(c.harbor, goharborv1alpha1.ChartMuseumName),
+				Namespace: c.harbor.Namespace,
+				Labels: map[string]string{
+					"app":      goharborv1alpha1.ChartMuseumName,
+					"harbor":   harborName,
+					"operator": operatorName,
+				},
+			},
+			Type: corev1.SecretTypeOpaque,
+			Data: map[string][]byte{
+				configName: c.secretConfigs[configName],
+			},
+		},
+	}
 }
 
 func (c *ChartMuseum) GetSecretsCheckSum() string {
-	// TODO get generation of the secrets
-	value := fmt.Sprintf("%s\n%s", c.harbor.Spec.Components.ChartMuseum.CacheSecret, c.harbor.Spec.Components.ChartMuseum.StorageSecret)
+	// TODO get generation of the storage secret
+	value := fmt.Sprintf("%s\n%s\n%s", c.harbor.Spec.Components.ChartMuseum.CacheSecret, c.harbor.Spec.Components.ChartMuseum.StorageSecret, c.secretConfigs.CheckSum())
 	sum := sha256.New().Sum([]byte(value))
 
 	return fmt.Sprintf("%x", sum)
@@ -4,15 +4,14 @@ import (
 	"context"
 	"crypto/sha256"
 	"fmt"
-	"io/ioutil"
-	"sync"
 
-	"github.com/markbates/pkger"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/render"
 	"github.com/goharbor/harbor-operator/pkg/factories/application"
 )
 
@@ -20,27 +19,72 @@ const (
 	configKey = "config.yaml"
 )
 
-var (
-	once   sync.Once
-	config []byte
-)
+// https://github.com/coreos/clair/blob/master/config.yaml.sample
+// https://github.com/goharbor/harbor/blob/master/make/photon/prepare/templates/clair/config.yaml.jinja
+const configTemplate = `clair:
+  database:
+    type: pgsql
+    options:
+      source: {{ quote .DatabaseURL }}
+  updater:
+    interval: 0s
+    enabledupdaters:
+{{ toYaml .VulnerabilitySources | indent 4 }}
+  api:
+    port: {{ .APIPort }}
+    healthport: {{ .HealthPort }}
+    timeout: 5m0s
+`
+
+type configData struct {
+	DatabaseURL          string
+	VulnerabilitySources []string
+	APIPort              int
+	HealthPort           int
+}
 
-func InitConfigMaps() {
-	file, err := pkger.Open("/assets/templates/clair/config.yaml")
+// The configuration contains the database password, it is stored in a secret.
+func (c *Clair) RenderConfigs(ctx context.Context, reader client.Reader) error {
+	secret, err := render.GetSecret(ctx, reader, c.harbor.Namespace, c.harbor.Spec.Components.Clair.DatabaseSecret)
 	if err != nil {
-		panic(errors.Wrapf(err, "cannot open Clair configuration template %s", "/assets/templates/clair/config.yaml"))
+		return errors.Wrap(err, "cannot get database")
 	}
-	defer file.Close()
 
-	config, err = ioutil.ReadAll(file)
+	vulnerabilitySources := c.harbor.Spec.Components.Clair.VulnerabilitySources
+	if vulnerabilitySources == nil {
+		vulnerabilitySources = []string{}
+	}
+
+	config, err := render.Template(configKey, configTemplate, configData{
+		DatabaseURL: render.GetPostgresURL(secret, render.DatabaseKeys{
+			Host:     goharborv1alpha1.HarborClairDatabaseHostKey,
+			Port:     goharborv1alpha1.HarborClairDatabasePortKey,
+			Name:     goharborv1alpha1.HarborClairDatabaseNameKey,
+			User:     goharborv1alpha1.HarborClairDatabaseUserKey,
+			Password: goharborv1alpha1.HarborClairDatabasePasswordKey,
+			SSL:      goharborv1alpha1.HarborClairDatabaseSSLKey,
+		}),
+		VulnerabilitySources: vulnerabilitySources,
+		APIPort:              apiPort,
+		HealthPort:           healthPort,
+	})
 	if err != nil {
-		panic(errors.Wrapf(err, "cannot read Clair configuration template %s", "/assets/templates/clair/config.yaml"))
+		return errors.Wrap(err, "cannot render clair configuration")
 	}
+
+	config, err = render.Override(ctx, reader, c.harbor.Namespace, c.harbor.Spec.Components.Clair.ConfigOverrides, configKey, config)
+	if err != nil {
+		return errors.Wrap(err, "cannot override clair configuration")
+	}
+
+	c.secretConfigs = render.Configs{
+		configKey: config,
+	}
+
+	return nil
 }
 
 func (c *Clair) GetConfigMaps(ctx context.Context) []*corev1.ConfigMap {
-	once.Do(InitConfigMaps)
-
 	operatorName := application.GetName(ctx)
 	harborName := c.harbor.Name
 
@@ -55,14 +99,11 @@ func (c *Clair) GetConfigMaps(ctx context.Context) []*corev1.ConfigMap {
 					"operator": operatorName,
 				},
 			},
-			BinaryData: map[string][]byte{
-				configKey: config,
-			},
 			// https://github.com/goharbor/harbor-scanner-clair#configuration
 			// https://github.com/goharbor/harbor/blob/master/make/photon/prepare/templates/clair/clair_env.jinja
 			Data: map[string]string{
 				"SCANNER_CLAIR_URL":                   fmt.Sprintf("http://%s", c.harbor.NormalizeComponentName(goharborv1alpha1.ClairName)),
-				"SCANNER_LOG_LEVEL":                   "debug",
+				"SCANNER_LOG_LEVEL":                   string(c.harbor.Spec.Components.Clair.GetLogLevel()),
 				"SCANNER_STORE_REDIS_POOL_MAX_ACTIVE": "5",
 				"SCANNER_STORE_REDIS_POOL_MAX_IDLE":   "5",
 				"SCANNER_STORE_REDIS_SCAN_JOB_TTL":    "1h",
@@ -73,7 +114,7 @@ func (c *Clair) GetConfigMaps(ctx context.Context) []*corev1.ConfigMap {
 }
 
 func (c *Clair) GetConfigMapsCheckSum() string {
-	value := fmt.Sprintf("%d\n%+v\n%x", adapterPort, c.harbor.Spec.Components.Clair.VulnerabilitySources, config)
+	value := fmt.Sprintf("%d\n%s", adapterPort, c.harbor.Spec.Components.Clair.GetLogLevel())
 	sum := sha256.New().Sum([]byte(value))
 
 	return fmt.Sprintf("%x", sum)
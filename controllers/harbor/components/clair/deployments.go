@@ -2,7 +2,6 @@ package clair
 
 import (
 	"context"
-	"encoding/json"
 	"path"
 	"time"
 
@@ -12,12 +11,14 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/internaltls"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/proxy"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/render"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/trustedca"
 	"github.com/goharbor/harbor-operator/pkg/factories/application"
-	"github.com/goharbor/harbor-operator/pkg/factories/logger"
 )
 
 const (
-	initImage       = "hairyhenderson/gomplate"
 	apiPort         = 6060 // https://github.com/quay/clair/blob/c39101e9b8206401d8b9cb631f3aee47a24ab889/cmd/clair/config.go#L64
 	healthPort      = 6061 // https://github.com/quay/clair/blob/c39101e9b8206401d8b9cb631f3aee47a24ab889/cmd/clair/config.go#L63
 	adapterPort     = 8080
@@ -35,11 +36,23 @@ func (c *Clair) GetDeployments(ctx context.Context) []*appsv1.Deployment { // no
 	operatorName := application.GetName(ctx)
 	harborName := c.harbor.GetName()
 
-	vulnsrc, err := json.Marshal(c.harbor.Spec.Components.Clair.VulnerabilitySources)
-	if err != nil {
-		logger.Get(ctx).Error(err, "invalid vulnerability sources")
+	// Clair API does not support TLS, only the adapter is served over HTTPS
+	var adapterEnv []corev1.EnvVar
+	if c.harbor.Spec.InternalTLS.IsEnabled() {
+		// https://github.com/goharbor/harbor-scanner-clair#configuration
+		adapterEnv = []corev1.EnvVar{
+			{
+				Name:  "SCANNER_API_SERVER_TLS_CERTIFICATE",
+				Value: internaltls.CertificateFile,
+			}, {
+				Name:  "SCANNER_API_SERVER_TLS_KEY",
+				Value: internaltls.KeyFile,
+			},
+		}
 	}
 
+	volumes := append(internaltls.GetVolumes(c.harbor, goharborv1alpha1.ClairName), trustedca.GetVolumes(c.harbor)...)
+
 	return []*appsv1.Deployment{
 		{
 			ObjectMeta: metav1.ObjectMeta{
@@ -76,14 +89,12 @@ func (c *Clair) GetDeployments(ctx context.Context) []*appsv1.Deployment { // no
 					Spec: corev1.PodSpec{
 						NodeSelector:                 c.harbor.Spec.Components.Clair.NodeSelector,
 						AutomountServiceAccountToken: &varFalse,
-						Volumes: []corev1.Volume{
+						Volumes: append([]corev1.Volume{
 							{
-								Name: "config-template",
+								Name: "config",
 								VolumeSource: corev1.VolumeSource{
-									ConfigMap: &corev1.ConfigMapVolumeSource{
-										LocalObjectReference: corev1.LocalObjectReference{
-											Name: c.harbor.NormalizeComponentName(goharborv1alpha1.ClairName),
-										},
+									Secret: &corev1.SecretVolumeSource{
+										SecretName: render.GetConfigSecretName(c.harbor, goharborv1alpha1.ClairName),
 										Items: []corev1.KeyToPath{
 											{
 												Key:  configKey,
@@ -92,46 +103,8 @@ func (c *Clair) GetDeployments(ctx context.Context) []*appsv1.Deployment { // no
 										},
 									},
 								},
-							}, {
-								Name:         "config",
-								VolumeSource: corev1.VolumeSource{},
-							},
-						},
-						InitContainers: []corev1.Container{
-							{
-								Name:       "configuration",
-								Image:      initImage,
-								WorkingDir: "/workdir",
-								Args:       []string{"--input-dir", "/workdir", "--output-dir", "/processed"},
-								VolumeMounts: []corev1.VolumeMount{
-									{
-										Name:      "config-template",
-										MountPath: "/workdir",
-										ReadOnly:  true,
-									}, {
-										Name:      "config",
-										MountPath: "/processed",
-										ReadOnly:  false,
-									},
-								},
-								Env: []corev1.EnvVar{
-									{
-										Name:  "vulnsrc",
-										Value: string(vulnsrc),
-									},
-								},
-								EnvFrom: []corev1.EnvFromSource{
-									{
-										SecretRef: &corev1.SecretEnvSource{
-											Optional: &varFalse,
-											LocalObjectReference: corev1.LocalObjectReference{
-												Name: c.harbor.Spec.Components.Clair.DatabaseSecret,
-											},
-										},
-									},
-								},
 							},
-						},
+						}, volumes...),
 						Containers: []corev1.Container{
 							{
 								Name:  "clair",
@@ -144,17 +117,9 @@ func (c *Clair) GetDeployments(ctx context.Context) []*appsv1.Deployment { // no
 									},
 								},
 
-								Env: []corev1.EnvVar{
-									{ // // https://github.com/goharbor/harbor/blob/master/make/photon/prepare/templates/clair/clair_env.jinja
-										Name:  "HTTP_PROXY",
-										Value: "",
-									}, {
-										Name:  "HTTPS_PROXY",
-										Value: "",
-									}, {
-										Name:  "NO_PROXY",
-										Value: "",
-									}, { // https://github.com/goharbor/harbor/blob/master/make/photon/prepare/templates/clair/postgres_env.jinja
+								// https://github.com/goharbor/harbor/blob/master/make/photon/prepare/templates/clair/clair_env.jinja
+								Env: append([]corev1.EnvVar{
+									{ // https://github.com/goharbor/harbor/blob/master/make/photon/prepare/templates/clair/postgres_env.jinja
 										Name: "POSTGRES_PASSWORD",
 										ValueFrom: &corev1.EnvVarSource{
 											SecretKeyRef: &corev1.SecretKeySelector{
@@ -166,9 +131,9 @@ func (c *Clair) GetDeployments(ctx context.Context) []*appsv1.Deployment { // no
 											},
 										},
 									},
-								},
+								}, proxy.GetEnv(c.harbor, goharborv1alpha1.ClairProxyComponent)...),
 								Command:         []string{"/home/clair/clair"},
-								Args:            []string{"-config", path.Join(clairConfigPath, configKey)},
+								Args:            []string{"-config", path.Join(clairConfigPath, configKey), "-log-level", string(c.harbor.Spec.Components.Clair.GetLogLevel())},
 								ImagePullPolicy: corev1.PullAlways,
 								LivenessProbe: &corev1.Probe{
 									Handler: corev1.Handler{
@@ -186,13 +151,13 @@ func (c *Clair) GetDeployments(ctx context.Context) []*appsv1.Deployment { // no
 										},
 									},
 								},
-								VolumeMounts: []corev1.VolumeMount{
+								VolumeMounts: append([]corev1.VolumeMount{
 									{
 										MountPath: path.Join(clairConfigPath, configKey),
 										Name:      "config",
 										SubPath:   configKey,
 									},
-								},
+								}, trustedca.GetVolumeMounts(c.harbor)...),
 							}, {
 								Name:  "clair-adapter",
 								Image: c.harbor.Spec.Components.Clair.Adapter.GetImage(),
@@ -202,7 +167,7 @@ func (c *Clair) GetDeployments(ctx context.Context) []*appsv1.Deployment { // no
 									},
 								},
 
-								Env: []corev1.EnvVar{
+								Env: append([]corev1.EnvVar{
 									{
 										Name: "SCANNER_STORE_REDIS_URL",
 										ValueFrom: &corev1.EnvVarSource{
@@ -226,7 +191,7 @@ func (c *Clair) GetDeployments(ctx context.Context) []*appsv1.Deployment { // no
 											},
 										},
 									},
-								},
+								}, append(adapterEnv, proxy.GetEnv(c.harbor, goharborv1alpha1.ClairProxyComponent)...)...),
 								EnvFrom: []corev1.EnvFromSource{
 									{
 										Prefix: "clair_db_",
@@ -250,8 +215,9 @@ func (c *Clair) GetDeployments(ctx context.Context) []*appsv1.Deployment { // no
 								LivenessProbe: &corev1.Probe{
 									Handler: corev1.Handler{
 										HTTPGet: &corev1.HTTPGetAction{
-											Path: "/probe/healthy",
-											Port: intstr.FromInt(adapterPort),
+											Path:   "/probe/healthy",
+											Port:   intstr.FromInt(adapterPort),
+											Scheme: internaltls.GetURIScheme(c.harbor),
 										},
 									},
 									InitialDelaySeconds: int32(livenessProbeInitialDelay.Seconds()),
@@ -259,25 +225,25 @@ func (c *Clair) GetDeployments(ctx context.Context) []*appsv1.Deployment { // no
 								ReadinessProbe: &corev1.Probe{
 									Handler: corev1.Handler{
 										HTTPGet: &corev1.HTTPGetAction{
-											Path: "/probe/healthy",
-											Port: intstr.FromInt(adapterPort),
+											Path:   "/probe/healthy",
+											Port:   intstr.FromInt(adapterPort),
+											Scheme: internaltls.GetURIScheme(c.harbor),
 										},
 									},
 								},
-								VolumeMounts: []corev1.VolumeMount{
+								VolumeMounts: append([]corev1.VolumeMount{
 									{
 										MountPath: path.Join(clairConfigPath, configKey),
 										Name:      "config",
 										SubPath:   configKey,
 									},
-								},
+								}, append(internaltls.GetVolumeMounts(c.harbor), trustedca.GetVolumeMounts(c.harbor)...)...),
 							},
 						},
 						Priority: c.Option.GetPriority(),
 					},
 				},
 				RevisionHistoryLimit: &revisionHistoryLimit,
-				Paused:               c.harbor.Spec.Paused,
 			},
 		},
 	}
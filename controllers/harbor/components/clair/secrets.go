@@ -6,15 +6,38 @@ import (
 	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/render"
+	"github.com/goharbor/harbor-operator/pkg/factories/application"
 )
 
 func (c *Clair) GetSecrets(ctx context.Context) []*corev1.Secret {
-	return []*corev1.Secret{}
+	operatorName := application.GetName(ctx)
+	harborName := c.harbor.Name
+
+	return []*corev1.Secret{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      render.GetConfigSecretName(c.harbor, goharborv1alpha1.ClairName),
+				Namespace: c.harbor.Namespace,
+				Labels: map[string]string{
+					"app":      goharborv1alpha1.ClairName,
+					"harbor":   harborName,
+					"operator": operatorName,
+				},
+			},
+			Type: corev1.SecretTypeOpaque,
+			Data: map[string][]byte{
+				configKey: c.secretConfigs[configKey],
+			},
+		},
+	}
 }
 
 func (c *Clair) GetSecretsCheckSum() string {
-	// TODO get generation of the secret
-	value := c.harbor.Spec.Components.Clair.DatabaseSecret
+	value := fmt.Sprintf("%s\n%s", c.harbor.Spec.Components.Clair.DatabaseSecret, c.secretConfigs.CheckSum())
 	sum := sha256.New().Sum([]byte(value))
 
 	return fmt.Sprintf("%x", sum)
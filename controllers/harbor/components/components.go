@@ -4,6 +4,7 @@ import (
 	"context"
 
 	certv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha2"
+	routev1 "github.com/openshift/api/route/v1"
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
@@ -11,14 +12,17 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
 	harbor_chartmuseum "github.com/goharbor/harbor-operator/controllers/harbor/components/chartmuseum"
 	harbor_clair "github.com/goharbor/harbor-operator/controllers/harbor/components/clair"
 	harbor_core "github.com/goharbor/harbor-operator/controllers/harbor/components/harbor-core"
 	harbor_jobservice "github.com/goharbor/harbor-operator/controllers/harbor/components/jobservice"
+	harbor_monitoring "github.com/goharbor/harbor-operator/controllers/harbor/components/monitoring"
 	harbor_notary "github.com/goharbor/harbor-operator/controllers/harbor/components/notary"
 	harbor_portal "github.com/goharbor/harbor-operator/controllers/harbor/components/portal"
 	harbor_registry "github.com/goharbor/harbor-operator/controllers/harbor/components/registry"
@@ -39,17 +43,28 @@ type Components struct {
 	ChartMuseum *ComponentRunner
 	Clair       *ComponentRunner
 	Notary      *ComponentRunner
+	Monitoring  *ComponentRunner
 }
 
 type Component interface {
+	// RenderConfigs renders configuration files, reading referenced secrets.
+	// It must be called before getting ConfigMaps, Secrets and Deployments.
+	RenderConfigs(context.Context, client.Reader) error
 	GetConfigMaps(context.Context) []*corev1.ConfigMap
 	GetSecrets(context.Context) []*corev1.Secret
 	GetServices(context.Context) []*corev1.Service
 	GetCertificates(context.Context) []*certv1.Certificate
 	GetIngresses(context.Context) []*netv1.Ingress
+	GetRoutes(context.Context) ([]*routev1.Route, error)
 	GetDeployments(context.Context) []*appsv1.Deployment
 }
 
+// MonitoringComponent is implemented by components creating prometheus-operator resources.
+type MonitoringComponent interface {
+	GetServiceMonitors(context.Context) []*unstructured.Unstructured
+	GetPrometheusRules(context.Context) []*unstructured.Unstructured
+}
+
 func GetComponents(ctx context.Context, harbor *goharborv1alpha1.Harbor) (*Components, error) { // nolint:funlen
 	harborResource := &Components{}
 
@@ -87,6 +102,14 @@ func GetComponents(ctx context.Context, harbor *goharborv1alpha1.Harbor) (*Compo
 		}))
 	}
 
+	if harbor.Spec.Monitoring != nil {
+		harborResource.Monitoring = &ComponentRunner{}
+
+		g.Go(harborResource.Monitoring.getInitFunc(ctx, harbor, MonitoringPriority, goharborv1alpha1.MonitoringName, func(ctx context.Context, harbor *goharborv1alpha1.Harbor, option *Option) (Component, error) {
+			return harbor_monitoring.New(ctx, harbor, option)
+		}))
+	}
+
 	if harbor.Spec.Components.Notary != nil {
 		harborResource.Notary = &ComponentRunner{}
 
@@ -4,8 +4,45 @@ import (
 	"context"
 
 	certv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/internaltls"
+	"github.com/goharbor/harbor-operator/pkg/factories/application"
 )
 
 func (c *HarborCore) GetCertificates(ctx context.Context) []*certv1.Certificate {
-	return []*certv1.Certificate{}
+	certificates := internaltls.GetCertificates(ctx, c.harbor, goharborv1alpha1.CoreName, c.harbor.NormalizeComponentName(goharborv1alpha1.CoreName))
+
+	if !c.harbor.IsPublicCertificateIssued() {
+		return certificates
+	}
+
+	operatorName := application.GetName(ctx)
+	harborName := c.harbor.Name
+
+	hosts := c.harbor.GetPublicHosts()
+
+	return append(certificates, &certv1.Certificate{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      c.harbor.NormalizeComponentName(goharborv1alpha1.PublicCertificateName),
+			Namespace: c.harbor.Namespace,
+			Labels: map[string]string{
+				"app":      goharborv1alpha1.CoreName,
+				"harbor":   harborName,
+				"operator": operatorName,
+			},
+		},
+		Spec: certv1.CertificateSpec{
+			CommonName: hosts[0],
+			SecretName: c.harbor.GetTLSSecretName(),
+			DNSNames:   hosts,
+			IssuerRef:  c.harbor.GetPublicCertificateIssuerRef(),
+			Usages: []certv1.KeyUsage{
+				certv1.UsageDigitalSignature,
+				certv1.UsageKeyEncipherment,
+				certv1.UsageServerAuth,
+			},
+		},
+	})
 }
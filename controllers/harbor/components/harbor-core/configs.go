@@ -4,50 +4,74 @@ import (
 	"context"
 	"crypto/sha256"
 	"fmt"
-	"io/ioutil"
 	"path"
 	"strconv"
-	"sync"
 
+	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
 	"github.com/goharbor/harbor-operator/controllers/harbor/components/clair"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/internaltls"
 	"github.com/goharbor/harbor-operator/controllers/harbor/components/notary"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/render"
 	"github.com/goharbor/harbor-operator/pkg/factories/application"
-	"github.com/markbates/pkger"
-	"github.com/pkg/errors"
 )
 
 const (
 	configName = "app.conf"
 )
 
-var (
-	once   sync.Once
-	config []byte
-)
+// https://github.com/goharbor/harbor/blob/master/docs/1.10/install-config/configure-yml-file.md
+const configTemplate = `appname = Harbor
+runmode = prod
+enablegzip = true
+
+[prod]
+{{- if .InternalTLS }}
+EnableHTTP = false
+EnableHTTPS = true
+HTTPSPort = {{ .Port }}
+HTTPSCertFile = {{ .CertificateFile }}
+HTTPSKeyFile = {{ .KeyFile }}
+{{- else }}
+httpport = {{ .Port }}
+{{- end }}
+`
+
+type configData struct {
+	InternalTLS     bool
+	Port            int
+	CertificateFile string
+	KeyFile         string
+}
 
-func InitConfigMaps() {
-	file, err := pkger.Open("/assets/templates/core/app.conf")
+func (c *HarborCore) RenderConfigs(ctx context.Context, reader client.Reader) error {
+	config, err := render.Template(configName, configTemplate, configData{
+		InternalTLS:     c.harbor.Spec.InternalTLS.IsEnabled(),
+		Port:            port,
+		CertificateFile: internaltls.CertificateFile,
+		KeyFile:         internaltls.KeyFile,
+	})
 	if err != nil {
-		panic(errors.Wrapf(err, "cannot open Core configuration template %s", "/assets/templates/core/app.conf"))
+		return errors.Wrap(err, "cannot render core configuration")
 	}
-	defer file.Close()
 
-	config, err = ioutil.ReadAll(file)
-	if err != nil {
-		panic(errors.Wrapf(err, "cannot read Core configuration template %s", "/assets/templates/core/app.conf"))
+	c.configs = render.Configs{
+		configName: config,
 	}
+
+	return nil
 }
 
 func (c *HarborCore) GetConfigMaps(ctx context.Context) []*corev1.ConfigMap { // nolint:funlen
-	once.Do(InitConfigMaps)
-
 	operatorName := application.GetName(ctx)
 	harborName := c.harbor.Name
 
+	scheme := c.harbor.Spec.InternalTLS.GetScheme()
+
 	return []*corev1.ConfigMap{
 		{
 			ObjectMeta: metav1.ObjectMeta{
@@ -61,7 +85,7 @@ func (c *HarborCore) GetConfigMaps(ctx context.Context) []*corev1.ConfigMap { //
 			},
 
 			BinaryData: map[string][]byte{
-				configName: config,
+				configName: c.configs[configName],
 			},
 
 			// https://github.com/goharbor/harbor/blob/master/make/photon/prepare/templates/core/env.jinja
@@ -72,7 +96,7 @@ func (c *HarborCore) GetConfigMaps(ctx context.Context) []*corev1.ConfigMap { //
 				"CFG_EXPIRATION":                 "5",
 				"CHART_CACHE_DRIVER":             "memory",
 				"EXT_ENDPOINT":                   c.harbor.Spec.PublicURL,
-				"LOG_LEVEL":                      "debug",
+				"LOG_LEVEL":                      string(c.harbor.Spec.Components.Core.GetLogLevel()),
 				"MAX_JOB_WORKERS":                fmt.Sprintf("%d", c.harbor.Spec.Components.JobService.WorkerCount),
 				"READ_ONLY":                      fmt.Sprintf("%+v", c.harbor.Spec.ReadOnly),
 				"REGISTRY_STORAGE_PROVIDER_NAME": "memory",
@@ -80,24 +104,25 @@ func (c *HarborCore) GetConfigMaps(ctx context.Context) []*corev1.ConfigMap { //
 				"SYNC_QUOTA":                     "true",
 				"SYNC_REGISTRY":                  "false",
 
-				"_REDIS_URL":                    "", // For session purpose
-				"ADMIRAL_URL":                   "NA",
-				"CHART_REPOSITORY_URL":          fmt.Sprintf("http://%s", c.harbor.NormalizeComponentName(goharborv1alpha1.ChartMuseumName)),
+				"_REDIS_URL":           "", // For session purpose
+				"ADMIRAL_URL":          "NA",
+				"CHART_REPOSITORY_URL": fmt.Sprintf("%s://%s", scheme, c.harbor.NormalizeComponentName(goharborv1alpha1.ChartMuseumName)),
+				// Clair API does not support TLS
 				"CLAIR_HEALTH_CHECK_SERVER_URL": fmt.Sprintf("http://%s:6061", c.harbor.NormalizeComponentName(goharborv1alpha1.ClairName)),
 				"CLAIR_URL":                     fmt.Sprintf("http://%s", c.harbor.NormalizeComponentName(goharborv1alpha1.ClairName)),
-				"CLAIR_ADAPTER_URL":             fmt.Sprintf("http://%s:%d", c.harbor.NormalizeComponentName(goharborv1alpha1.ClairName), clair.AdapterPublicPort),
-				"CORE_LOCAL_URL":                fmt.Sprintf("http://%s", c.harbor.NormalizeComponentName(goharborv1alpha1.CoreName)),
-				"CORE_URL":                      fmt.Sprintf("http://%s", c.harbor.NormalizeComponentName(goharborv1alpha1.CoreName)),
-				"JOBSERVICE_URL":                fmt.Sprintf("http://%s", c.harbor.NormalizeComponentName(goharborv1alpha1.JobServiceName)),
-				"NOTARY_URL":                    fmt.Sprintf("http://%s", c.harbor.NormalizeComponentName(notary.NotaryServerName)),
-				"PORTAL_URL":                    fmt.Sprintf("http://%s", c.harbor.NormalizeComponentName(goharborv1alpha1.PortalName)),
-				"REGISTRY_URL":                  fmt.Sprintf("http://%s", c.harbor.NormalizeComponentName(goharborv1alpha1.RegistryName)),
-				"REGISTRYCTL_URL":               fmt.Sprintf("http://%s:8080", c.harbor.NormalizeComponentName(goharborv1alpha1.RegistryName)),
-				"TOKEN_SERVICE_URL":             fmt.Sprintf("http://%s/service/token", c.harbor.NormalizeComponentName(goharborv1alpha1.CoreName)),
+				"CLAIR_ADAPTER_URL":             fmt.Sprintf("%s://%s:%d", scheme, c.harbor.NormalizeComponentName(goharborv1alpha1.ClairName), clair.AdapterPublicPort),
+				"CORE_LOCAL_URL":                fmt.Sprintf("%s://%s", scheme, c.harbor.NormalizeComponentName(goharborv1alpha1.CoreName)),
+				"CORE_URL":                      fmt.Sprintf("%s://%s", scheme, c.harbor.NormalizeComponentName(goharborv1alpha1.CoreName)),
+				"JOBSERVICE_URL":                fmt.Sprintf("%s://%s", scheme, c.harbor.NormalizeComponentName(goharborv1alpha1.JobServiceName)),
+				"NOTARY_URL":                    fmt.Sprintf("%s://%s", scheme, c.harbor.NormalizeComponentName(notary.NotaryServerName)),
+				"PORTAL_URL":                    fmt.Sprintf("%s://%s", scheme, c.harbor.NormalizeComponentName(goharborv1alpha1.PortalName)),
+				"REGISTRY_URL":                  fmt.Sprintf("%s://%s", scheme, c.harbor.NormalizeComponentName(goharborv1alpha1.RegistryName)),
+				"REGISTRYCTL_URL":               fmt.Sprintf("%s://%s:8080", scheme, c.harbor.NormalizeComponentName(goharborv1alpha1.RegistryName)),
+				"TOKEN_SERVICE_URL":             fmt.Sprintf("%s://%s/service/token", scheme, c.harbor.NormalizeComponentName(goharborv1alpha1.CoreName)),
 
 				"DATABASE_TYPE":             "postgresql",
-				"POSTGRESQL_MAX_IDLE_CONNS": fmt.Sprintf("%d", maxIdleConns),
-				"POSTGRESQL_MAX_OPEN_CONNS": fmt.Sprintf("%d", maxOpenConns),
+				"POSTGRESQL_MAX_IDLE_CONNS": fmt.Sprintf("%d", c.harbor.Spec.Components.Core.Database.GetMaxIdleConnections()),
+				"POSTGRESQL_MAX_OPEN_CONNS": fmt.Sprintf("%d", c.harbor.Spec.Components.Core.Database.GetMaxOpenConnections()),
 
 				"WITH_CHARTMUSEUM": strconv.FormatBool(c.harbor.Spec.Components.ChartMuseum != nil),
 				"WITH_CLAIR":       strconv.FormatBool(c.harbor.Spec.Components.Clair != nil),
@@ -108,7 +133,8 @@ func (c *HarborCore) GetConfigMaps(ctx context.Context) []*corev1.ConfigMap { //
 }
 
 func (c *HarborCore) GetConfigMapsCheckSum() string {
-	value := fmt.Sprintf("%s\n%+v\n%x", c.harbor.Spec.PublicURL, c.harbor.Spec.Components.Clair != nil, config)
+	database := c.harbor.Spec.Components.Core.Database
+	value := fmt.Sprintf("%s\n%+v\n%s\n%d\n%d\n%s", c.harbor.Spec.PublicURL, c.harbor.Spec.Components.Clair != nil, c.harbor.Spec.Components.Core.GetLogLevel(), database.GetMaxIdleConnections(), database.GetMaxOpenConnections(), c.configs.CheckSum())
 	sum := sha256.New().Sum([]byte(value))
 
 	// todo get generation of the secret
@@ -0,0 +1,103 @@
+package core
+
+import (
+	"fmt"
+	"path"
+
+	corev1 "k8s.io/api/core/v1"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+)
+
+const (
+	databaseCAVolumeName = "database-ca"
+	databaseCAPath       = "/etc/core/database"
+)
+
+// Core connects to the databases with github.com/lib/pq,
+// which reads the connection timeout and the CA from PG* variables.
+func (c *HarborCore) getDatabaseEnv() []corev1.EnvVar {
+	database := c.harbor.Spec.Components.Core.Database
+
+	env := []corev1.EnvVar{
+		c.getSSLModeEnv("POSTGRESQL_SSLMODE"),
+		c.getSSLModeEnv("CLAIR_DB_SSLMODE"),
+	}
+
+	if timeout := database.GetConnectTimeout(); timeout > 0 {
+		env = append(env, corev1.EnvVar{
+			Name:  "PGCONNECT_TIMEOUT",
+			Value: fmt.Sprintf("%d", timeout),
+		})
+	}
+
+	if database.CASecretName != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  "PGSSLROOTCERT",
+			Value: path.Join(databaseCAPath, goharborv1alpha1.HarborCoreDatabaseCAKey),
+		})
+	}
+
+	return env
+}
+
+// The ssl mode of the spec takes precedence over the ssl key of the database secret.
+func (c *HarborCore) getSSLModeEnv(name string) corev1.EnvVar {
+	if sslMode := c.harbor.Spec.Components.Core.Database.SSLMode; sslMode != "" {
+		return corev1.EnvVar{
+			Name:  name,
+			Value: string(sslMode),
+		}
+	}
+
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				Key:      goharborv1alpha1.HarborCoreDatabaseSSLKey,
+				Optional: &varTrue,
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: c.harbor.Spec.Components.Core.DatabaseSecret,
+				},
+			},
+		},
+	}
+}
+
+func (c *HarborCore) getDatabaseVolumes() []corev1.Volume {
+	if c.harbor.Spec.Components.Core.Database.CASecretName == "" {
+		return []corev1.Volume{}
+	}
+
+	return []corev1.Volume{
+		{
+			Name: databaseCAVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: c.harbor.Spec.Components.Core.Database.CASecretName,
+					Items: []corev1.KeyToPath{
+						{
+							Key:  goharborv1alpha1.HarborCoreDatabaseCAKey,
+							Path: goharborv1alpha1.HarborCoreDatabaseCAKey,
+						},
+					},
+					Optional: &varFalse,
+				},
+			},
+		},
+	}
+}
+
+func (c *HarborCore) getDatabaseVolumeMounts() []corev1.VolumeMount {
+	if c.harbor.Spec.Components.Core.Database.CASecretName == "" {
+		return []corev1.VolumeMount{}
+	}
+
+	return []corev1.VolumeMount{
+		{
+			Name:      databaseCAVolumeName,
+			ReadOnly:  true,
+			MountPath: databaseCAPath,
+		},
+	}
+}
@@ -2,7 +2,6 @@ package core
 
 import (
 	"context"
-	"fmt"
 	"path"
 	"time"
 
@@ -12,19 +11,20 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/internaltls"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/monitoring"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/proxy"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/trustedca"
 	"github.com/goharbor/harbor-operator/pkg/factories/application"
 )
 
 var (
 	revisionHistoryLimit int32 = 0 // nolint:golint
-	maxIdleConns               = 0
-	maxOpenConns               = 1
 	varFalse                   = false
 	varTrue                    = true
 )
 
 const (
-	initImage      = "hairyhenderson/gomplate"
 	coreConfigPath = "/etc/core"
 	keyFileName    = "key"
 	configFileName = "app.conf"
@@ -52,6 +52,16 @@ func (c *HarborCore) GetDeployments(ctx context.Context) []*appsv1.Deployment {
 		}
 	}
 
+	volumes := append(internaltls.GetVolumes(c.harbor, goharborv1alpha1.CoreName), c.getDatabaseVolumes()...)
+	volumes = append(volumes, trustedca.GetVolumes(c.harbor)...)
+
+	volumeMounts := append(internaltls.GetVolumeMounts(c.harbor), c.getDatabaseVolumeMounts()...)
+	volumeMounts = append(volumeMounts, trustedca.GetVolumeMounts(c.harbor)...)
+
+	env := append(c.getDatabaseEnv(), internaltls.GetEnv(c.harbor)...)
+	env = append(env, proxy.GetEnv(c.harbor, goharborv1alpha1.CoreProxyComponent)...)
+	env = append(env, monitoring.GetEnv(c.harbor, goharborv1alpha1.CoreMetricsComponent)...)
+
 	return []*appsv1.Deployment{
 		{
 			ObjectMeta: metav1.ObjectMeta{
@@ -88,19 +98,20 @@ func (c *HarborCore) GetDeployments(ctx context.Context) []*appsv1.Deployment {
 					Spec: corev1.PodSpec{
 						NodeSelector:                 c.harbor.Spec.Components.Core.NodeSelector,
 						AutomountServiceAccountToken: &varFalse,
-						Volumes: []corev1.Volume{
+						Volumes: append([]corev1.Volume{
 							{
 								Name: "config",
-								VolumeSource: corev1.VolumeSource{
-									EmptyDir: &corev1.EmptyDirVolumeSource{},
-								},
-							}, {
-								Name: "config-template",
 								VolumeSource: corev1.VolumeSource{
 									ConfigMap: &corev1.ConfigMapVolumeSource{
 										LocalObjectReference: corev1.LocalObjectReference{
 											Name: c.harbor.NormalizeComponentName(goharborv1alpha1.CoreName),
 										},
+										Items: []corev1.KeyToPath{
+											{
+												Key:  configName,
+												Path: configFileName,
+											},
+										},
 									},
 								},
 							}, {
@@ -130,47 +141,19 @@ func (c *HarborCore) GetDeployments(ctx context.Context) []*appsv1.Deployment {
 									EmptyDir: &corev1.EmptyDirVolumeSource{},
 								},
 							},
-						},
-						InitContainers: []corev1.Container{
-							{
-								Name:            "configuration",
-								Image:           initImage,
-								WorkingDir:      "/workdir",
-								Args:            []string{"--input-dir", "/workdir", "--output-dir", "/processed"},
-								SecurityContext: &corev1.SecurityContext{},
-
-								VolumeMounts: []corev1.VolumeMount{
-									{
-										Name:      "config-template",
-										MountPath: path.Join("/workdir", configName),
-										ReadOnly:  true,
-										SubPath:   configName,
-									}, {
-										Name:      "config",
-										MountPath: "/processed",
-										ReadOnly:  false,
-									},
-								},
-								Env: []corev1.EnvVar{
-									{
-										Name:  "PORT",
-										Value: fmt.Sprintf("%d", port),
-									},
-								},
-							},
-						},
+						}, volumes...),
 						Containers: []corev1.Container{
 							{
 								Name:  "core",
 								Image: c.harbor.Spec.Components.Core.GetImage(),
-								Ports: []corev1.ContainerPort{
+								Ports: append([]corev1.ContainerPort{
 									{
 										ContainerPort: int32(port),
 									},
-								},
+								}, monitoring.GetContainerPorts(c.harbor, goharborv1alpha1.CoreMetricsComponent)...),
 
 								// https://github.com/goharbor/harbor/blob/master/make/photon/prepare/templates/core/env.jinja
-								Env: []corev1.EnvVar{
+								Env: append([]corev1.EnvVar{
 									{
 										Name: "CORE_SECRET",
 										ValueFrom: &corev1.EnvVarSource{
@@ -316,7 +299,7 @@ func (c *HarborCore) GetDeployments(ctx context.Context) []*appsv1.Deployment {
 										},
 									},
 									cacheEnv,
-								},
+								}, env...),
 								EnvFrom: []corev1.EnvFromSource{
 									{
 										ConfigMapRef: &corev1.ConfigMapEnvSource{
@@ -331,8 +314,9 @@ func (c *HarborCore) GetDeployments(ctx context.Context) []*appsv1.Deployment {
 								LivenessProbe: &corev1.Probe{
 									Handler: corev1.Handler{
 										HTTPGet: &corev1.HTTPGetAction{
-											Path: "/api/ping",
-											Port: intstr.FromInt(port),
+											Path:   "/api/ping",
+											Port:   intstr.FromInt(port),
+											Scheme: internaltls.GetURIScheme(c.harbor),
 										},
 									},
 									PeriodSeconds: int32(healthCheckPeriod.Seconds()),
@@ -340,12 +324,13 @@ func (c *HarborCore) GetDeployments(ctx context.Context) []*appsv1.Deployment {
 								ReadinessProbe: &corev1.Probe{
 									Handler: corev1.Handler{
 										HTTPGet: &corev1.HTTPGetAction{
-											Path: "/api/ping",
-											Port: intstr.FromInt(port),
+											Path:   "/api/ping",
+											Port:   intstr.FromInt(port),
+											Scheme: internaltls.GetURIScheme(c.harbor),
 										},
 									},
 								},
-								VolumeMounts: []corev1.VolumeMount{
+								VolumeMounts: append([]corev1.VolumeMount{
 									{
 										Name:      "config",
 										ReadOnly:  true,
@@ -366,14 +351,13 @@ func (c *HarborCore) GetDeployments(ctx context.Context) []*appsv1.Deployment {
 										ReadOnly:  false,
 										MountPath: path.Join(coreConfigPath, "token"),
 									},
-								},
+								}, volumeMounts...),
 							},
 						},
 						Priority: c.Option.GetPriority(),
 					},
 				},
 				RevisionHistoryLimit: &revisionHistoryLimit,
-				Paused:               c.harbor.Spec.Paused,
 			},
 		},
 	}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	routev1 "github.com/openshift/api/route/v1"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/pkg/factories/application"
+)
+
+var routePaths = []string{"/api", "/c", "/service"}
+
+func (c *HarborCore) GetRoutes(ctx context.Context) ([]*routev1.Route, error) {
+	if c.harbor.Spec.Expose.GetType() != goharborv1alpha1.RouteExposeType {
+		return []*routev1.Route{}, nil
+	}
+
+	operatorName := application.GetName(ctx)
+	harborName := c.harbor.Name
+
+	u, err := url.Parse(c.harbor.Spec.PublicURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid url")
+	}
+
+	host := u.Hostname()
+
+	var tls *routev1.TLSConfig
+	if u.Scheme == "https" {
+		tls = &routev1.TLSConfig{
+			Termination:                   routev1.TLSTerminationType(c.harbor.Spec.Expose.GetRouteTermination()),
+			InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+		}
+	}
+
+	routes := make([]*routev1.Route, 0, len(routePaths))
+
+	for _, path := range routePaths {
+		routes = append(routes, &routev1.Route{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-%s", c.harbor.NormalizeComponentName(goharborv1alpha1.CoreName), strings.Trim(path, "/")),
+				Namespace: c.harbor.Namespace,
+				Labels: map[string]string{
+					"app":      goharborv1alpha1.CoreName,
+					"harbor":   harborName,
+					"operator": operatorName,
+				},
+			},
+			Spec: routev1.RouteSpec{
+				Host: host,
+				Path: path,
+				To: routev1.RouteTargetReference{
+					Kind: "Service",
+					Name: c.harbor.NormalizeComponentName(goharborv1alpha1.CoreName),
+				},
+				Port: &routev1.RoutePort{
+					TargetPort: intstr.FromInt(port),
+				},
+				TLS: tls.DeepCopy(),
+			},
+		})
+	}
+
+	return routes, nil
+}
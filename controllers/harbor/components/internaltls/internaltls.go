@@ -0,0 +1,187 @@
+package internaltls
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	certv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/pkg/factories/application"
+)
+
+const (
+	// VolumeName is the name of the pod volume containing the internal certificate.
+	VolumeName = "internal-tls"
+
+	// CertificatePath is the directory where the internal certificate is mounted.
+	CertificatePath = "/etc/harbor/ssl"
+
+	// TrustedCAPath is the file loaded in the trust store of Harbor images at startup.
+	// https://github.com/goharbor/harbor/blob/v2.0.0/make/photon/common/install_cert.sh
+	TrustedCAPath = "/harbor_cust_cert/harbor_internal_ca.crt"
+
+	// PublicPort is the port exposed by services when internal TLS is enabled.
+	PublicPort = 443
+
+	// IngressBackendProtocolAnnotation configures nginx ingress controllers to talk HTTPS to backends.
+	IngressBackendProtocolAnnotation = "nginx.ingress.kubernetes.io/backend-protocol"
+
+	secretNameSuffix = "internal-tls"
+)
+
+var (
+	CertificateFile = path.Join(CertificatePath, corev1.TLSCertKey)
+	KeyFile         = path.Join(CertificatePath, corev1.TLSPrivateKeyKey)
+	CAFile          = path.Join(CertificatePath, "ca.crt")
+)
+
+// GetSecretName returns the name of the secret containing the internal certificate of the component.
+func GetSecretName(harbor *goharborv1alpha1.Harbor, component string) string {
+	return harbor.NormalizeComponentName(fmt.Sprintf("%s-%s", component, secretNameSuffix))
+}
+
+// GetPublicPort returns the port exposed by services, depending on internal TLS.
+func GetPublicPort(harbor *goharborv1alpha1.Harbor, port int32) int32 {
+	if harbor.Spec.InternalTLS.IsEnabled() {
+		return PublicPort
+	}
+
+	return port
+}
+
+// GetURIScheme returns the scheme used by probes.
+func GetURIScheme(harbor *goharborv1alpha1.Harbor) corev1.URIScheme {
+	if harbor.Spec.InternalTLS.IsEnabled() {
+		return corev1.URISchemeHTTPS
+	}
+
+	return corev1.URISchemeHTTP
+}
+
+// GetIngressAnnotations returns annotations required by ingresses to reach backends.
+func GetIngressAnnotations(harbor *goharborv1alpha1.Harbor) map[string]string {
+	if !harbor.Spec.InternalTLS.IsEnabled() {
+		return nil
+	}
+
+	return map[string]string{
+		IngressBackendProtocolAnnotation: "HTTPS",
+	}
+}
+
+// GetCertificates returns the certificate used by the component to serve internal requests on the given services.
+func GetCertificates(ctx context.Context, harbor *goharborv1alpha1.Harbor, component string, services ...string) []*certv1.Certificate {
+	if !harbor.Spec.InternalTLS.IsEnabled() {
+		return []*certv1.Certificate{}
+	}
+
+	operatorName := application.GetName(ctx)
+
+	dnsNames := make([]string, 0, len(services)*3) // nolint:mnd
+
+	for _, service := range services {
+		dnsNames = append(dnsNames,
+			service,
+			fmt.Sprintf("%s.%s", service, harbor.Namespace),
+			fmt.Sprintf("%s.%s.svc", service, harbor.Namespace),
+		)
+	}
+
+	return []*certv1.Certificate{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      GetSecretName(harbor, component),
+				Namespace: harbor.Namespace,
+				Labels: map[string]string{
+					"app":      component,
+					"harbor":   harbor.Name,
+					"operator": operatorName,
+				},
+			},
+			Spec: certv1.CertificateSpec{
+				CommonName:   services[0],
+				Organization: []string{"Harbor Operator"},
+				SecretName:   GetSecretName(harbor, component),
+				DNSNames:     dnsNames,
+				IssuerRef:    harbor.Spec.CertificateIssuerRef,
+				Usages: []certv1.KeyUsage{
+					certv1.UsageDigitalSignature,
+					certv1.UsageKeyEncipherment,
+					certv1.UsageServerAuth,
+					certv1.UsageClientAuth,
+				},
+			},
+		},
+	}
+}
+
+// GetVolumes returns the volumes containing the internal certificate of the component.
+func GetVolumes(harbor *goharborv1alpha1.Harbor, component string) []corev1.Volume {
+	if !harbor.Spec.InternalTLS.IsEnabled() {
+		return nil
+	}
+
+	return []corev1.Volume{
+		{
+			Name: VolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: GetSecretName(harbor, component),
+				},
+			},
+		},
+	}
+}
+
+// GetVolumeMounts mounts the internal certificate and trusts its CA.
+func GetVolumeMounts(harbor *goharborv1alpha1.Harbor) []corev1.VolumeMount {
+	if !harbor.Spec.InternalTLS.IsEnabled() {
+		return nil
+	}
+
+	return []corev1.VolumeMount{
+		{
+			Name:      VolumeName,
+			MountPath: CertificatePath,
+			ReadOnly:  true,
+		}, {
+			Name:      VolumeName,
+			MountPath: TrustedCAPath,
+			SubPath:   "ca.crt",
+			ReadOnly:  true,
+		},
+	}
+}
+
+// GetEnv returns the environment variables used by Harbor components to enable internal TLS.
+// https://github.com/goharbor/harbor/blob/v2.0.0/make/photon/prepare/templates/core/env.jinja
+func GetEnv(harbor *goharborv1alpha1.Harbor) []corev1.EnvVar {
+	if !harbor.Spec.InternalTLS.IsEnabled() {
+		return []corev1.EnvVar{
+			{
+				Name:  "INTERNAL_TLS_ENABLED",
+				Value: "false",
+			},
+		}
+	}
+
+	return []corev1.EnvVar{
+		{
+			Name:  "INTERNAL_TLS_ENABLED",
+			Value: "true",
+		}, {
+			Name:  "INTERNAL_TLS_KEY_PATH",
+			Value: KeyFile,
+		}, {
+			Name:  "INTERNAL_TLS_CERT_PATH",
+			Value: CertificateFile,
+		}, {
+			Name:  "INTERNAL_TLS_TRUST_CA_PATH",
+			Value: CAFile,
+		},
+	}
+}
@@ -0,0 +1,86 @@
+package internaltls
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/pkg/factories/application"
+)
+
+func TestInternalTLS(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecsWithDefaultAndCustomReporters(t, "InternalTLS Suite", []Reporter{envtest.NewlineReporter{}})
+}
+
+var _ = Describe("Internal TLS", func() {
+	var harbor *goharborv1alpha1.Harbor
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		application.SetName(&ctx, "harbor-operator")
+
+		harbor = &goharborv1alpha1.Harbor{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "my",
+				Namespace: "registry",
+			},
+		}
+	})
+
+	Context("Disabled", func() {
+		It("Should keep plain http", func() {
+			Expect(GetPublicPort(harbor, 80)).To(BeEquivalentTo(80))
+			Expect(GetURIScheme(harbor)).To(Equal(corev1.URISchemeHTTP))
+			Expect(GetIngressAnnotations(harbor)).To(BeEmpty())
+			Expect(GetCertificates(ctx, harbor, "core", "my-harbor-core")).To(BeEmpty())
+			Expect(GetVolumes(harbor, "core")).To(BeEmpty())
+			Expect(GetVolumeMounts(harbor)).To(BeEmpty())
+			Expect(GetEnv(harbor)).To(ConsistOf(corev1.EnvVar{Name: "INTERNAL_TLS_ENABLED", Value: "false"}))
+		})
+	})
+
+	Context("Enabled", func() {
+		BeforeEach(func() {
+			harbor.Spec.InternalTLS.Enabled = true
+		})
+
+		It("Should switch to https", func() {
+			Expect(GetPublicPort(harbor, 80)).To(BeEquivalentTo(PublicPort))
+			Expect(GetURIScheme(harbor)).To(Equal(corev1.URISchemeHTTPS))
+			Expect(GetIngressAnnotations(harbor)).To(HaveKeyWithValue(IngressBackendProtocolAnnotation, "HTTPS"))
+		})
+
+		It("Should issue a certificate for every service name", func() {
+			certificates := GetCertificates(ctx, harbor, "core", "my-harbor-core")
+			Expect(certificates).To(HaveLen(1))
+			Expect(certificates[0].Spec.SecretName).To(Equal(GetSecretName(harbor, "core")))
+			Expect(certificates[0].Spec.DNSNames).To(ConsistOf(
+				"my-harbor-core",
+				"my-harbor-core.registry",
+				"my-harbor-core.registry.svc",
+			))
+		})
+
+		It("Should mount the certificate and trust its CA", func() {
+			volumes := GetVolumes(harbor, "core")
+			Expect(volumes).To(HaveLen(1))
+			Expect(volumes[0].Secret.SecretName).To(Equal(GetSecretName(harbor, "core")))
+
+			mountPaths := []string{}
+			for _, mount := range GetVolumeMounts(harbor) {
+				mountPaths = append(mountPaths, mount.MountPath)
+			}
+
+			Expect(mountPaths).To(ConsistOf(CertificatePath, TrustedCAPath))
+		})
+	})
+})
@@ -4,16 +4,17 @@ import (
 	"context"
 	"crypto/sha256"
 	"fmt"
-	"io/ioutil"
-	"sync"
+	"strings"
 
+	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/internaltls"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/render"
 	"github.com/goharbor/harbor-operator/pkg/factories/application"
-	"github.com/markbates/pkger"
-	"github.com/pkg/errors"
 )
 
 const (
@@ -25,27 +26,84 @@ const (
 )
 
 var (
-	once         sync.Once
-	config       []byte
 	hookMaxRetry = 5
 )
 
-func InitConfigMaps() {
-	file, err := pkger.Open("/assets/templates/jobservice/config.yaml")
+// https://github.com/goharbor/harbor/blob/master/make/photon/prepare/templates/jobservice/config.yml.jinja
+const configTemplate = `
+{{- if .InternalTLS }}
+protocol: "https"
+https_config:
+  cert: {{ quote .CertificateFile }}
+  key: {{ quote .KeyFile }}
+{{- else }}
+protocol: "http"
+{{- end }}
+port: {{ .Port }}
+
+worker_pool:
+  backend: "redis"
+
+  redis_pool:
+    namespace: jobservice
+
+job_loggers:
+- name: STD_OUTPUT
+  level: {{ .LogLevel }} # INFO/DEBUG/WARNING/ERROR/FATAL
+
+    # JobService read files to expose logs
+- name: FILE
+  level: INFO
+  settings: # Customized settings of logger
+    base_dir: {{ quote .LogsDirectory }}
+  sweeper:
+    duration: 7 #days
+    settings: # Customized settings of sweeper
+      work_dir: {{ quote .LogsDirectory }}
+
+loggers:
+- name: STD_OUTPUT
+  level: {{ .LogLevel }}
+
+webhook:
+  job_max_retry: 10
+`
+
+type configData struct {
+	InternalTLS     bool
+	Port            int
+	CertificateFile string
+	KeyFile         string
+	LogsDirectory   string
+	LogLevel        string
+}
+
+func (j *JobService) RenderConfigs(ctx context.Context, reader client.Reader) error {
+	config, err := render.Template(configName, configTemplate, configData{
+		InternalTLS:     j.harbor.Spec.InternalTLS.IsEnabled(),
+		Port:            port,
+		CertificateFile: internaltls.CertificateFile,
+		KeyFile:         internaltls.KeyFile,
+		LogsDirectory:   logsDirectory,
+		LogLevel:        strings.ToUpper(string(j.harbor.Spec.Components.JobService.GetLogLevel())),
+	})
 	if err != nil {
-		panic(errors.Wrapf(err, "cannot open JobService configuration template %s", "/assets/templates/jobservice/config.yaml"))
+		return errors.Wrap(err, "cannot render jobservice configuration")
 	}
-	defer file.Close()
 
-	config, err = ioutil.ReadAll(file)
+	config, err = render.Override(ctx, reader, j.harbor.Namespace, j.harbor.Spec.Components.JobService.ConfigOverrides, configName, config)
 	if err != nil {
-		panic(errors.Wrapf(err, "cannot read JobService configuration template %s", "/assets/templates/jobservice/config.yaml"))
+		return errors.Wrap(err, "cannot override jobservice configuration")
 	}
+
+	j.configs = render.Configs{
+		configName: config,
+	}
+
+	return nil
 }
 
 func (j *JobService) GetConfigMaps(ctx context.Context) []*corev1.ConfigMap {
-	once.Do(InitConfigMaps)
-
 	operatorName := application.GetName(ctx)
 	harborName := j.harbor.Name
 
@@ -61,10 +119,10 @@ func (j *JobService) GetConfigMaps(ctx context.Context) []*corev1.ConfigMap {
 				},
 			},
 			BinaryData: map[string][]byte{
-				configName: config,
+				configName: j.configs[configName],
 			},
 			Data: map[string]string{
-				"REGISTRY_CONTROLLER_URL":          fmt.Sprintf("http://%s:8080", j.harbor.NormalizeComponentName(goharborv1alpha1.RegistryName)),
+				"REGISTRY_CONTROLLER_URL":          fmt.Sprintf("%s://%s:8080", j.harbor.Spec.InternalTLS.GetScheme(), j.harbor.NormalizeComponentName(goharborv1alpha1.RegistryName)),
 				"JOBSERVICE_WEBHOOK_JOB_MAX_RETRY": fmt.Sprintf("%d", hookMaxRetry),
 				"JOB_SERVICE_POOL_WORKERS":         fmt.Sprintf("%d", j.harbor.Spec.Components.JobService.WorkerCount),
 			},
@@ -73,7 +131,7 @@ func (j *JobService) GetConfigMaps(ctx context.Context) []*corev1.ConfigMap {
 }
 
 func (j *JobService) GetConfigMapsCheckSum() string {
-	value := fmt.Sprintf("%d\n%d\n%x", hookMaxRetry, j.harbor.Spec.Components.JobService.WorkerCount, config)
+	value := fmt.Sprintf("%d\n%d\n%s", hookMaxRetry, j.harbor.Spec.Components.JobService.WorkerCount, j.configs.CheckSum())
 	sum := sha256.New().Sum([]byte(value))
 
 	return fmt.Sprintf("%x", sum)
@@ -2,7 +2,6 @@ package jobservice
 
 import (
 	"context"
-	"fmt"
 	"path"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -11,6 +10,10 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/internaltls"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/monitoring"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/proxy"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/trustedca"
 	"github.com/goharbor/harbor-operator/pkg/factories/application"
 )
 
@@ -20,7 +23,6 @@ var (
 )
 
 const (
-	initImage  = "hairyhenderson/gomplate"
 	configPath = "/etc/jobservice/"
 	port       = 8080
 )
@@ -29,6 +31,11 @@ func (j *JobService) GetDeployments(ctx context.Context) []*appsv1.Deployment {
 	operatorName := application.GetName(ctx)
 	harborName := j.harbor.GetName()
 
+	volumes := append(internaltls.GetVolumes(j.harbor, goharborv1alpha1.JobServiceName), trustedca.GetVolumes(j.harbor)...)
+	volumeMounts := append(internaltls.GetVolumeMounts(j.harbor), trustedca.GetVolumeMounts(j.harbor)...)
+	env := append(internaltls.GetEnv(j.harbor), proxy.GetEnv(j.harbor, goharborv1alpha1.JobServiceProxyComponent)...)
+	env = append(env, monitoring.GetEnv(j.harbor, goharborv1alpha1.JobServiceMetricsComponent)...)
+
 	return []*appsv1.Deployment{
 		{
 			ObjectMeta: metav1.ObjectMeta{
@@ -65,19 +72,20 @@ func (j *JobService) GetDeployments(ctx context.Context) []*appsv1.Deployment {
 					Spec: corev1.PodSpec{
 						NodeSelector:                 j.harbor.Spec.Components.JobService.NodeSelector,
 						AutomountServiceAccountToken: &varFalse,
-						Volumes: []corev1.Volume{
+						Volumes: append([]corev1.Volume{
 							{
 								Name: "config",
-								VolumeSource: corev1.VolumeSource{
-									EmptyDir: &corev1.EmptyDirVolumeSource{},
-								},
-							}, {
-								Name: "config-template",
 								VolumeSource: corev1.VolumeSource{
 									ConfigMap: &corev1.ConfigMapVolumeSource{
 										LocalObjectReference: corev1.LocalObjectReference{
 											Name: j.harbor.NormalizeComponentName(goharborv1alpha1.JobServiceName),
 										},
+										Items: []corev1.KeyToPath{
+											{
+												Key:  configName,
+												Path: configName,
+											},
+										},
 									},
 								},
 							}, {
@@ -86,50 +94,19 @@ func (j *JobService) GetDeployments(ctx context.Context) []*appsv1.Deployment {
 									EmptyDir: &corev1.EmptyDirVolumeSource{},
 								},
 							},
-						},
-						InitContainers: []corev1.Container{
-							{
-								Name:            "configuration",
-								Image:           initImage,
-								WorkingDir:      "/workdir",
-								Args:            []string{"--input-dir", "/workdir", "--output-dir", "/processed"},
-								SecurityContext: &corev1.SecurityContext{},
-
-								VolumeMounts: []corev1.VolumeMount{
-									{
-										Name:      "config-template",
-										MountPath: path.Join("/workdir", configName),
-										ReadOnly:  true,
-										SubPath:   configName,
-									}, {
-										Name:      "config",
-										MountPath: "/processed",
-										ReadOnly:  false,
-									},
-								},
-								Env: []corev1.EnvVar{
-									{
-										Name:  "PORT",
-										Value: fmt.Sprintf("%d", port),
-									}, {
-										Name:  "LOGS_DIR",
-										Value: logsDirectory,
-									},
-								},
-							},
-						},
+						}, volumes...),
 						Containers: []corev1.Container{
 							{
 								Name:  "jobservice",
 								Image: j.harbor.Spec.Components.JobService.GetImage(),
-								Ports: []corev1.ContainerPort{
+								Ports: append([]corev1.ContainerPort{
 									{
 										ContainerPort: port,
 									},
-								},
+								}, monitoring.GetContainerPorts(j.harbor, goharborv1alpha1.JobServiceMetricsComponent)...),
 
 								// https://github.com/goharbor/harbor/blob/master/make/photon/prepare/templates/jobservice/env.jinja
-								Env: []corev1.EnvVar{
+								Env: append([]corev1.EnvVar{
 									{
 										Name: "CORE_SECRET",
 										ValueFrom: &corev1.EnvVarSource{
@@ -186,7 +163,7 @@ func (j *JobService) GetDeployments(ctx context.Context) []*appsv1.Deployment {
 											},
 										},
 									},
-								},
+								}, env...),
 								EnvFrom: []corev1.EnvFromSource{
 									{
 										ConfigMapRef: &corev1.ConfigMapEnvSource{
@@ -203,20 +180,22 @@ func (j *JobService) GetDeployments(ctx context.Context) []*appsv1.Deployment {
 								LivenessProbe: &corev1.Probe{
 									Handler: corev1.Handler{
 										HTTPGet: &corev1.HTTPGetAction{
-											Path: "/api/v1/stats",
-											Port: intstr.FromInt(port),
+											Path:   "/api/v1/stats",
+											Port:   intstr.FromInt(port),
+											Scheme: internaltls.GetURIScheme(j.harbor),
 										},
 									},
 								},
 								ReadinessProbe: &corev1.Probe{
 									Handler: corev1.Handler{
 										HTTPGet: &corev1.HTTPGetAction{
-											Path: "/api/v1/stats",
-											Port: intstr.FromInt(port),
+											Path:   "/api/v1/stats",
+											Port:   intstr.FromInt(port),
+											Scheme: internaltls.GetURIScheme(j.harbor),
 										},
 									},
 								},
-								VolumeMounts: []corev1.VolumeMount{
+								VolumeMounts: append([]corev1.VolumeMount{
 									{
 										MountPath: path.Join(configPath, configName),
 										Name:      "config",
@@ -225,14 +204,13 @@ func (j *JobService) GetDeployments(ctx context.Context) []*appsv1.Deployment {
 										MountPath: logsDirectory,
 										Name:      "logs",
 									},
-								},
+								}, volumeMounts...),
 							},
 						},
 						Priority: j.Option.GetPriority(),
 					},
 				},
 				RevisionHistoryLimit: &revisionHistoryLimit,
-				Paused:               j.harbor.Spec.Paused,
 			},
 		},
 	}
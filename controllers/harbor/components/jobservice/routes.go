@@ -0,0 +1,11 @@
+package jobservice
+
+import (
+	"context"
+
+	routev1 "github.com/openshift/api/route/v1"
+)
+
+func (*JobService) GetRoutes(ctx context.Context) ([]*routev1.Route, error) {
+	return []*routev1.Route{}, nil
+}
@@ -8,6 +8,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/internaltls"
 	"github.com/goharbor/harbor-operator/pkg/factories/application"
 )
 
@@ -33,7 +34,7 @@ func (j *JobService) GetServices(ctx context.Context) []*corev1.Service {
 			Spec: corev1.ServiceSpec{
 				Ports: []corev1.ServicePort{
 					{
-						Port:       PublicPort,
+						Port:       internaltls.GetPublicPort(j.harbor, PublicPort),
 						TargetPort: intstr.FromInt(port),
 					},
 				},
@@ -0,0 +1,110 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+)
+
+const (
+	// MetricsPort is the port serving core and jobservice metrics.
+	// https://github.com/goharbor/harbor/blob/v2.2.0/make/photon/prepare/templates/core/env.jinja
+	MetricsPort = 8001
+	MetricsPath = "/metrics"
+
+	MetricsPortName = "metrics"
+
+	metricsNamespace = "harbor"
+
+	metricsSuffix = "metrics"
+)
+
+var (
+	ServiceMonitorGVK = schema.GroupVersionKind{
+		Group:   "monitoring.coreos.com",
+		Version: "v1",
+		Kind:    "ServiceMonitor",
+	}
+
+	PrometheusRuleGVK = schema.GroupVersionKind{
+		Group:   "monitoring.coreos.com",
+		Version: "v1",
+		Kind:    "PrometheusRule",
+	}
+)
+
+// Monitoring exposes the metrics of the Harbor components to the prometheus-operator.
+type Monitoring struct {
+	harbor *goharborv1alpha1.Harbor
+	Option Option
+}
+
+type Option interface {
+	GetPriority() *int32
+}
+
+func New(ctx context.Context, harbor *goharborv1alpha1.Harbor, opt Option) (*Monitoring, error) {
+	return &Monitoring{
+		harbor: harbor,
+		Option: opt,
+	}, nil
+}
+
+func (*Monitoring) RenderConfigs(ctx context.Context, reader client.Reader) error {
+	return nil
+}
+
+// GetMetricsServiceName returns the name of the service exposing the metrics of the component.
+func GetMetricsServiceName(harbor *goharborv1alpha1.Harbor, component string) string {
+	return harbor.NormalizeComponentName(fmt.Sprintf("%s-%s", component, metricsSuffix))
+}
+
+// GetPrometheusRuleName returns the name of the PrometheusRule holding the default alerting rules.
+func GetPrometheusRuleName(harbor *goharborv1alpha1.Harbor) string {
+	return harbor.NormalizeComponentName(goharborv1alpha1.MonitoringName)
+}
+
+// GetEnv returns the environment enabling the metrics of core or jobservice.
+func GetEnv(harbor *goharborv1alpha1.Harbor, component goharborv1alpha1.MetricsComponent) []corev1.EnvVar {
+	if !harbor.Spec.Monitoring.IsEnabled(component) {
+		return nil
+	}
+
+	return []corev1.EnvVar{
+		{
+			Name:  "METRIC_ENABLE",
+			Value: "true",
+		}, {
+			Name:  "METRIC_PATH",
+			Value: MetricsPath,
+		}, {
+			Name:  "METRIC_PORT",
+			Value: fmt.Sprintf("%d", MetricsPort),
+		}, {
+			Name:  "METRIC_NAMESPACE",
+			Value: metricsNamespace,
+		}, {
+			Name:  "METRIC_SUBSYSTEM",
+			Value: string(component),
+		},
+	}
+}
+
+// GetContainerPorts returns the port serving the metrics of core or jobservice.
+func GetContainerPorts(harbor *goharborv1alpha1.Harbor, component goharborv1alpha1.MetricsComponent) []corev1.ContainerPort {
+	if !harbor.Spec.Monitoring.IsEnabled(component) {
+		return nil
+	}
+
+	return []corev1.ContainerPort{
+		{
+			Name:          MetricsPortName,
+			ContainerPort: MetricsPort,
+		},
+	}
+}
@@ -0,0 +1,146 @@
+package monitoring
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/pkg/factories/application"
+)
+
+func TestMonitoring(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecsWithDefaultAndCustomReporters(t, "Monitoring Suite", []Reporter{envtest.NewlineReporter{}})
+}
+
+func getAlerts(prometheusRule *unstructured.Unstructured) []string {
+	groups, _, err := unstructured.NestedSlice(prometheusRule.Object, "spec", "groups")
+	Expect(err).ToNot(HaveOccurred())
+	Expect(groups).To(HaveLen(1))
+
+	rules, _, err := unstructured.NestedSlice(groups[0].(map[string]interface{}), "rules")
+	Expect(err).ToNot(HaveOccurred())
+
+	alerts := []string{}
+	for _, rule := range rules {
+		alerts = append(alerts, rule.(map[string]interface{})["alert"].(string))
+	}
+
+	return alerts
+}
+
+var _ = Describe("Monitoring", func() {
+	var harbor *goharborv1alpha1.Harbor
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		application.SetName(&ctx, "harbor-operator")
+
+		harbor = &goharborv1alpha1.Harbor{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "my",
+				Namespace: "registry",
+			},
+			Spec: goharborv1alpha1.HarborSpec{
+				Components: goharborv1alpha1.HarborComponents{
+					Core:       &goharborv1alpha1.CoreComponent{},
+					Registry:   &goharborv1alpha1.RegistryComponent{},
+					JobService: &goharborv1alpha1.JobServiceComponent{},
+				},
+				Monitoring: &goharborv1alpha1.HarborMonitoring{
+					Labels:   map[string]string{"prometheus": "main"},
+					Interval: "30s",
+				},
+			},
+		}
+	})
+
+	Context("Registry only", func() {
+		It("Should expose the registry debug port", func() {
+			monitoring, err := New(ctx, harbor, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			services := monitoring.GetServices(ctx)
+			Expect(services).To(HaveLen(1))
+			Expect(services[0].GetName()).To(Equal("my-registry-metrics"))
+			Expect(services[0].Spec.Ports[0].Port).To(BeEquivalentTo(5001))
+			Expect(services[0].Spec.Selector).To(HaveKeyWithValue("app", goharborv1alpha1.RegistryName))
+
+			Expect(GetEnv(harbor, goharborv1alpha1.CoreMetricsComponent)).To(BeEmpty())
+			Expect(GetContainerPorts(harbor, goharborv1alpha1.CoreMetricsComponent)).To(BeEmpty())
+		})
+
+		It("Should select the metrics service", func() {
+			monitoring, err := New(ctx, harbor, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			serviceMonitors := monitoring.GetServiceMonitors(ctx)
+			Expect(serviceMonitors).To(HaveLen(1))
+			Expect(serviceMonitors[0].GroupVersionKind()).To(Equal(ServiceMonitorGVK))
+			Expect(serviceMonitors[0].GetLabels()).To(HaveKeyWithValue("prometheus", "main"))
+
+			matchLabels, _, err := unstructured.NestedStringMap(serviceMonitors[0].Object, "spec", "selector", "matchLabels")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(matchLabels).To(Equal(monitoring.GetServices(ctx)[0].GetLabels()))
+
+			endpoints, _, err := unstructured.NestedSlice(serviceMonitors[0].Object, "spec", "endpoints")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(endpoints).To(ConsistOf(map[string]interface{}{
+				"port":     MetricsPortName,
+				"path":     MetricsPath,
+				"interval": "30s",
+			}))
+		})
+
+		It("Should create default rules", func() {
+			monitoring, err := New(ctx, harbor, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			prometheusRules := monitoring.GetPrometheusRules(ctx)
+			Expect(prometheusRules).To(HaveLen(1))
+			Expect(prometheusRules[0].GetName()).To(Equal("my-monitoring"))
+			Expect(getAlerts(prometheusRules[0])).To(ConsistOf("HarborCoreUnhealthy", "HarborRegistryHighErrorRate"))
+
+			disableRules := true
+			harbor.Spec.Monitoring.DisableRules = &disableRules
+			Expect(monitoring.GetPrometheusRules(ctx)).To(BeEmpty())
+		})
+	})
+
+	Context("Core and jobservice", func() {
+		BeforeEach(func() {
+			harbor.Spec.Monitoring.Components = []goharborv1alpha1.MetricsComponent{
+				goharborv1alpha1.CoreMetricsComponent,
+				goharborv1alpha1.JobServiceMetricsComponent,
+			}
+		})
+
+		It("Should enable metrics of the components", func() {
+			Expect(GetEnv(harbor, goharborv1alpha1.JobServiceMetricsComponent)).To(ContainElement(corev1.EnvVar{Name: "METRIC_SUBSYSTEM", Value: "jobservice"}))
+			Expect(GetContainerPorts(harbor, goharborv1alpha1.CoreMetricsComponent)).To(ConsistOf(corev1.ContainerPort{Name: MetricsPortName, ContainerPort: MetricsPort}))
+		})
+
+		It("Should monitor every component", func() {
+			monitoring, err := New(ctx, harbor, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			names := []string{}
+			for _, serviceMonitor := range monitoring.GetServiceMonitors(ctx) {
+				names = append(names, serviceMonitor.GetName())
+			}
+
+			Expect(names).To(ConsistOf("my-registry-metrics", "my-core-metrics", "my-jobservice-metrics"))
+			// The queue latency is reported by the Harbor exporter only, which is not deployed
+			Expect(getAlerts(monitoring.GetPrometheusRules(ctx)[0])).To(ConsistOf("HarborCoreUnhealthy", "HarborRegistryHighErrorRate"))
+		})
+	})
+})
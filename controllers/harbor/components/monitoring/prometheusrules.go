@@ -0,0 +1,98 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/pkg/factories/application"
+	"github.com/goharbor/harbor-operator/pkg/metrics"
+)
+
+const (
+	// RegistryErrorRateThreshold is the ratio of registry requests failing with 5xx status codes triggering an alert.
+	RegistryErrorRateThreshold = 0.05
+)
+
+func (m *Monitoring) GetPrometheusRules(ctx context.Context) []*unstructured.Unstructured {
+	if m.harbor.Spec.Monitoring.AreRulesDisabled() {
+		return []*unstructured.Unstructured{}
+	}
+
+	rules := []interface{}{
+		m.getCoreUnhealthyRule(),
+	}
+
+	if m.harbor.Spec.Components.Registry != nil {
+		rules = append(rules, m.getRegistryErrorRateRule())
+	}
+
+	prometheusRule := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"groups": []interface{}{
+					map[string]interface{}{
+						"name":  fmt.Sprintf("harbor.%s.%s", m.harbor.GetNamespace(), m.harbor.GetName()),
+						"rules": rules,
+					},
+				},
+			},
+		},
+	}
+
+	labels := map[string]string{}
+
+	for key, value := range m.harbor.Spec.Monitoring.Labels {
+		labels[key] = value
+	}
+
+	labels["app"] = goharborv1alpha1.MonitoringName
+	labels["harbor"] = m.harbor.GetName()
+	labels["operator"] = application.GetName(ctx)
+
+	prometheusRule.SetGroupVersionKind(PrometheusRuleGVK)
+	prometheusRule.SetName(GetPrometheusRuleName(m.harbor))
+	prometheusRule.SetNamespace(m.harbor.GetNamespace())
+	prometheusRule.SetLabels(labels)
+
+	return []*unstructured.Unstructured{prometheusRule}
+}
+
+func (m *Monitoring) getAlertLabels(severity string) map[string]interface{} {
+	return map[string]interface{}{
+		"severity":  severity,
+		"namespace": m.harbor.GetNamespace(),
+		"harbor":    m.harbor.GetName(),
+	}
+}
+
+// getCoreUnhealthyRule relies on the health reported by the operator, so it does not require core metrics.
+func (m *Monitoring) getCoreUnhealthyRule() map[string]interface{} {
+	return map[string]interface{}{
+		"alert": "HarborCoreUnhealthy",
+		"expr": fmt.Sprintf(`%s_harbor_component_healthy{namespace=%q,harbor=%q,component=%q} == 0`,
+			metrics.Namespace, m.harbor.GetNamespace(), m.harbor.GetName(), goharborv1alpha1.CoreName),
+		"for":    "5m",
+		"labels": m.getAlertLabels("critical"),
+		"annotations": map[string]interface{}{
+			"summary": fmt.Sprintf("Harbor core of %s/%s is unhealthy", m.harbor.GetNamespace(), m.harbor.GetName()),
+		},
+	}
+}
+
+func (m *Monitoring) getRegistryErrorRateRule() map[string]interface{} {
+	selector := fmt.Sprintf(`namespace=%q,service=%q`, m.harbor.GetNamespace(), GetMetricsServiceName(m.harbor, goharborv1alpha1.RegistryName))
+
+	return map[string]interface{}{
+		"alert": "HarborRegistryHighErrorRate",
+		"expr": fmt.Sprintf(`sum(rate(registry_http_requests_total{%s,code=~"5.."}[5m])) / sum(rate(registry_http_requests_total{%s}[5m])) > %g`,
+			selector, selector, RegistryErrorRateThreshold),
+		"for":    "10m",
+		"labels": m.getAlertLabels("warning"),
+		"annotations": map[string]interface{}{
+			"summary": fmt.Sprintf("More than %g%% of the requests to the registry of %s/%s fail", RegistryErrorRateThreshold*100, m.harbor.GetNamespace(), m.harbor.GetName()), // nolint:gomnd
+		},
+	}
+}
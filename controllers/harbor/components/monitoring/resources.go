@@ -0,0 +1,35 @@
+package monitoring
+
+import (
+	"context"
+
+	certv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha2"
+	routev1 "github.com/openshift/api/route/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1beta1"
+)
+
+func (*Monitoring) GetConfigMaps(ctx context.Context) []*corev1.ConfigMap {
+	return []*corev1.ConfigMap{}
+}
+
+func (*Monitoring) GetSecrets(ctx context.Context) []*corev1.Secret {
+	return []*corev1.Secret{}
+}
+
+func (*Monitoring) GetCertificates(ctx context.Context) []*certv1.Certificate {
+	return []*certv1.Certificate{}
+}
+
+func (*Monitoring) GetIngresses(ctx context.Context) []*netv1.Ingress {
+	return []*netv1.Ingress{}
+}
+
+func (*Monitoring) GetRoutes(ctx context.Context) ([]*routev1.Route, error) {
+	return []*routev1.Route{}, nil
+}
+
+func (*Monitoring) GetDeployments(ctx context.Context) []*appsv1.Deployment {
+	return []*appsv1.Deployment{}
+}
@@ -0,0 +1,71 @@
+package monitoring
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func (m *Monitoring) GetServiceMonitors(ctx context.Context) []*unstructured.Unstructured {
+	targets := m.getTargets()
+	serviceMonitors := make([]*unstructured.Unstructured, 0, len(targets))
+
+	for _, target := range targets {
+		endpoint := map[string]interface{}{
+			"port": MetricsPortName,
+			"path": MetricsPath,
+		}
+
+		if m.harbor.Spec.Monitoring.Interval != "" {
+			endpoint["interval"] = m.harbor.Spec.Monitoring.Interval
+		}
+
+		serviceMonitor := &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"endpoints": []interface{}{endpoint},
+					"namespaceSelector": map[string]interface{}{
+						"matchNames": []interface{}{m.harbor.GetNamespace()},
+					},
+					"selector": map[string]interface{}{
+						"matchLabels": toInterfaceMap(m.getMetricsLabels(ctx, target.component)),
+					},
+				},
+			},
+		}
+
+		serviceMonitor.SetGroupVersionKind(ServiceMonitorGVK)
+		serviceMonitor.SetName(GetMetricsServiceName(m.harbor, target.component))
+		serviceMonitor.SetNamespace(m.harbor.GetNamespace())
+		serviceMonitor.SetLabels(m.getLabels(ctx, target.component))
+
+		serviceMonitors = append(serviceMonitors, serviceMonitor)
+	}
+
+	return serviceMonitors
+}
+
+// getLabels returns the labels of prometheus-operator resources, including the ones matched by Prometheus.
+func (m *Monitoring) getLabels(ctx context.Context, component string) map[string]string {
+	labels := map[string]string{}
+
+	for key, value := range m.harbor.Spec.Monitoring.Labels {
+		labels[key] = value
+	}
+
+	for key, value := range m.getMetricsLabels(ctx, component) {
+		labels[key] = value
+	}
+
+	return labels
+}
+
+func toInterfaceMap(values map[string]string) map[string]interface{} {
+	result := make(map[string]interface{}, len(values))
+
+	for key, value := range values {
+		result[key] = value
+	}
+
+	return result
+}
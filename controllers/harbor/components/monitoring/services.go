@@ -0,0 +1,89 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/registry"
+	"github.com/goharbor/harbor-operator/pkg/factories/application"
+)
+
+// metricsTarget is a component serving metrics.
+type metricsTarget struct {
+	component string
+	port      int32
+}
+
+func (m *Monitoring) getTargets() []metricsTarget {
+	targets := []metricsTarget{}
+
+	if m.harbor.Spec.Components.Registry != nil {
+		targets = append(targets, metricsTarget{
+			component: goharborv1alpha1.RegistryName,
+			port:      registry.MetricsPort,
+		})
+	}
+
+	if m.harbor.Spec.Monitoring.IsEnabled(goharborv1alpha1.CoreMetricsComponent) {
+		targets = append(targets, metricsTarget{
+			component: goharborv1alpha1.CoreName,
+			port:      MetricsPort,
+		})
+	}
+
+	if m.harbor.Spec.Monitoring.IsEnabled(goharborv1alpha1.JobServiceMetricsComponent) {
+		targets = append(targets, metricsTarget{
+			component: goharborv1alpha1.JobServiceName,
+			port:      MetricsPort,
+		})
+	}
+
+	return targets
+}
+
+// getMetricsLabels returns the labels of the metrics service of the component, selected by its ServiceMonitor.
+func (m *Monitoring) getMetricsLabels(ctx context.Context, component string) map[string]string {
+	return map[string]string{
+		"app":      fmt.Sprintf("%s-%s", component, metricsSuffix),
+		"harbor":   m.harbor.GetName(),
+		"operator": application.GetName(ctx),
+	}
+}
+
+func (m *Monitoring) GetServices(ctx context.Context) []*corev1.Service {
+	operatorName := application.GetName(ctx)
+
+	targets := m.getTargets()
+	services := make([]*corev1.Service, 0, len(targets))
+
+	for _, target := range targets {
+		services = append(services, &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      GetMetricsServiceName(m.harbor, target.component),
+				Namespace: m.harbor.GetNamespace(),
+				Labels:    m.getMetricsLabels(ctx, target.component),
+			},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{
+					{
+						Name:       MetricsPortName,
+						Port:       target.port,
+						TargetPort: intstr.FromInt(int(target.port)),
+					},
+				},
+				Selector: map[string]string{
+					"app":      target.component,
+					"harbor":   m.harbor.GetName(),
+					"operator": operatorName,
+				},
+			},
+		})
+	}
+
+	return services
+}
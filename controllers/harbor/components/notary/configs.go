@@ -2,17 +2,14 @@ package notary
 
 import (
 	"context"
-	"crypto/sha256"
-	"fmt"
-	"io/ioutil"
-	"sync"
 
-	"github.com/markbates/pkger"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
-	"github.com/goharbor/harbor-operator/pkg/factories/application"
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/internaltls"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/render"
 )
 
 const (
@@ -20,80 +17,156 @@ const (
 	signerConfigKey = "signer.json"
 )
 
-var (
-	once         sync.Once
-	serverConfig []byte
-	signerConfig []byte
-)
+const serverConfigTemplate = `{
+  "server": {
+    "http_addr": {{ printf ":%d" .Port | quote }}
+{{- if .InternalTLS }},
+    "tls_cert_file": {{ quote .CertificateFile }},
+    "tls_key_file": {{ quote .KeyFile }}
+{{- end }}
+  },
+  "trust_service": {
+    "type": "remote",
+    "hostname": {{ quote .SignerHost }},
+    "port": "80",
+    "tls_ca_file": "/etc/ssl/notary/ca.crt",
+    "key_algorithm": {{ quote .KeyAlgorithm }}
+  },
+  "logging": {
+    "level": {{ quote .LogLevel }}
+  },
+  "storage": {
+    "backend": "postgres",
+    "db_url": {{ quote .DatabaseURL }}
+  },
+  "auth": {
+    "type": "token",
+    "options": {
+      "realm": {{ printf "%s/service/token" .CorePublicURL | quote }},
+      {{- /* https://github.com/goharbor/harbor/blob/b1437c1341c32d94a5cde1f6438c772dcfff2c78/src/core/service/token/creator.go#L39 */}}
+      "service": "harbor-notary",
+      "issuer": "harbor-token-issuer",
+      "rootcertbundle": "/etc/ssl/notary/auth-token.crt"
+    }
+  }
+}
+`
+
+const signerConfigTemplate = `{
+  "server": {
+    "grpc_addr": {{ printf ":%d" .Port | quote }},
+    "tls_cert_file": "/etc/ssl/notary/tls.crt",
+    "tls_key_file": "/etc/ssl/notary/tls.key"
+  },
+  "logging": {
+    "level": {{ quote .LogLevel }}
+  },
+  "storage": {
+    "backend": "postgres",
+    "db_url": {{ quote .DatabaseURL }},
+    "default_alias": "defaultalias"
+  }
+}
+`
+
+type serverConfigData struct {
+	Port            int
+	InternalTLS     bool
+	CertificateFile string
+	KeyFile         string
+	SignerHost      string
+	KeyAlgorithm    string
+	DatabaseURL     string
+	CorePublicURL   string
+	LogLevel        string
+}
 
-func InitConfigMaps() {
-	// We can't use a constant containing file path. Pkger don't understant if it's not the value passed as parameter.
-	// const templatePath = "/my/Path"
-	// pkger.Open(templatePath) --> Doesn't work
-	serverFile, serverErr := pkger.Open("/assets/templates/notary/server.json")
-	if serverErr != nil {
-		panic(errors.Wrapf(serverErr, "cannot open Notary Server configuration template %s", "/assets/templates/notary/server.json"))
+type signerConfigData struct {
+	Port        int
+	DatabaseURL string
+	LogLevel    string
+}
+
+// Configurations contain database passwords, they are stored in secrets.
+func (n *Notary) RenderConfigs(ctx context.Context, reader client.Reader) error {
+	serverDatabaseURL, err := n.getDatabaseURL(ctx, reader, n.harbor.Spec.Components.Notary.Server.DatabaseSecret, render.DatabaseKeys{
+		Host:     goharborv1alpha1.HarborNotaryServerDatabaseHostKey,
+		Port:     goharborv1alpha1.HarborNotaryServerDatabasePortKey,
+		Name:     goharborv1alpha1.HarborNotaryServerDatabaseNameKey,
+		User:     goharborv1alpha1.HarborNotaryServerDatabaseUserKey,
+		Password: goharborv1alpha1.HarborNotaryServerDatabasePasswordKey,
+		SSL:      goharborv1alpha1.HarborNotaryServerDatabaseSSLKey,
+	})
+	if err != nil {
+		return errors.Wrap(err, "cannot get server database")
 	}
-	defer serverFile.Close()
 
-	serverConfig, serverErr = ioutil.ReadAll(serverFile)
-	if serverErr != nil {
-		panic(errors.Wrapf(serverErr, "cannot read Notary Server configuration template %s", "/assets/templates/notary/server.json"))
+	signerDatabaseURL, err := n.getDatabaseURL(ctx, reader, n.harbor.Spec.Components.Notary.Signer.DatabaseSecret, render.DatabaseKeys{
+		Host:     goharborv1alpha1.HarborNotarySignerDatabaseHostKey,
+		Port:     goharborv1alpha1.HarborNotarySignerDatabasePortKey,
+		Name:     goharborv1alpha1.HarborNotarySignerDatabaseNameKey,
+		User:     goharborv1alpha1.HarborNotarySignerDatabaseUserKey,
+		Password: goharborv1alpha1.HarborNotarySignerDatabasePasswordKey,
+		SSL:      goharborv1alpha1.HarborNotarySignerDatabaseSSLKey,
+	})
+	if err != nil {
+		return errors.Wrap(err, "cannot get signer database")
 	}
 
-	signerFile, signerErr := pkger.Open("/assets/templates/notary/signer.json")
-	if signerErr != nil {
-		panic(errors.Wrapf(signerErr, "cannot open Notary Signer configuration template %s", "/assets/templates/notary/signer.json"))
+	serverConfig, err := render.Template(serverConfigKey, serverConfigTemplate, serverConfigData{
+		Port:            notaryServerPort,
+		InternalTLS:     n.harbor.Spec.InternalTLS.IsEnabled(),
+		CertificateFile: internaltls.CertificateFile,
+		KeyFile:         internaltls.KeyFile,
+		SignerHost:      n.harbor.NormalizeComponentName(NotarySignerName),
+		KeyAlgorithm:    notarySignerKeyAlgorithm,
+		DatabaseURL:     serverDatabaseURL,
+		CorePublicURL:   n.harbor.Spec.PublicURL,
+		LogLevel:        string(n.harbor.Spec.Components.Notary.Server.GetLogLevel()),
+	})
+	if err != nil {
+		return errors.Wrap(err, "cannot render notary server configuration")
 	}
-	defer signerFile.Close()
 
-	signerConfig, signerErr = ioutil.ReadAll(signerFile)
-	if signerErr != nil {
-		panic(errors.Wrapf(signerErr, "cannot read Notary Signer configuration template %s", "/assets/templates/notary/signer.json"))
+	serverConfig, err = render.Override(ctx, reader, n.harbor.Namespace, n.harbor.Spec.Components.Notary.Server.ConfigOverrides, serverConfigKey, serverConfig)
+	if err != nil {
+		return errors.Wrap(err, "cannot override notary server configuration")
 	}
-}
 
-func (n *Notary) GetConfigMaps(ctx context.Context) []*corev1.ConfigMap {
-	once.Do(InitConfigMaps)
-
-	operatorName := application.GetName(ctx)
-	harborName := n.harbor.Name
-
-	return []*corev1.ConfigMap{
-		{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      n.harbor.NormalizeComponentName(NotaryServerName),
-				Namespace: n.harbor.Namespace,
-				Labels: map[string]string{
-					"app":      NotaryServerName,
-					"harbor":   harborName,
-					"operator": operatorName,
-				},
-			},
-			BinaryData: map[string][]byte{
-				serverConfigKey: serverConfig,
-			},
-		},
-		{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      n.harbor.NormalizeComponentName(NotarySignerName),
-				Namespace: n.harbor.Namespace,
-				Labels: map[string]string{
-					"app":      NotarySignerName,
-					"harbor":   harborName,
-					"operator": operatorName,
-				},
-			},
-			BinaryData: map[string][]byte{
-				signerConfigKey: signerConfig,
-			},
-		},
+	signerConfig, err := render.Template(signerConfigKey, signerConfigTemplate, signerConfigData{
+		Port:        notarySignerPort,
+		DatabaseURL: signerDatabaseURL,
+		LogLevel:    string(n.harbor.Spec.Components.Notary.Signer.GetLogLevel()),
+	})
+	if err != nil {
+		return errors.Wrap(err, "cannot render notary signer configuration")
 	}
+
+	signerConfig, err = render.Override(ctx, reader, n.harbor.Namespace, n.harbor.Spec.Components.Notary.Signer.ConfigOverrides, signerConfigKey, signerConfig)
+	if err != nil {
+		return errors.Wrap(err, "cannot override notary signer configuration")
+	}
+
+	n.serverConfigs = render.Configs{
+		serverConfigKey: serverConfig,
+	}
+
+	n.signerConfigs = render.Configs{
+		signerConfigKey: signerConfig,
+	}
+
+	return nil
 }
 
-func (n *Notary) GetConfigMapsCheckSum() string {
-	value := fmt.Sprintf("%x\n%x", serverConfig, signerConfig)
-	sum := sha256.New().Sum([]byte(value))
+func (n *Notary) getDatabaseURL(ctx context.Context, reader client.Reader, secretName string, keys render.DatabaseKeys) (string, error) {
+	secret, err := render.GetSecret(ctx, reader, n.harbor.Namespace, secretName)
+	if err != nil {
+		return "", err
+	}
 
-	return fmt.Sprintf("%x", sum)
+	return render.GetPostgresURL(secret, keys), nil
+}
+
+func (n *Notary) GetConfigMaps(ctx context.Context) []*corev1.ConfigMap {
+	return []*corev1.ConfigMap{}
 }
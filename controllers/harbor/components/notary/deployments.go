@@ -2,19 +2,19 @@ package notary
 
 import (
 	"context"
-	"strconv"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/internaltls"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/render"
 	"github.com/goharbor/harbor-operator/pkg/factories/application"
 )
 
 const (
 	migrationDatabaseURL = "postgresql://$(username):$(password)@$(host):$(port)/$(database)?sslmode=$(ssl)"
-	initImage            = "hairyhenderson/gomplate"
 	notaryServerPort     = 4443
 	notarySignerPort     = 7899
 )
@@ -55,9 +55,8 @@ func (n *Notary) GetDeployments(ctx context.Context) []*appsv1.Deployment { // n
 				Template: corev1.PodTemplateSpec{
 					ObjectMeta: metav1.ObjectMeta{
 						Annotations: map[string]string{
-							"configuration/checksum": n.GetConfigMapsCheckSum(),
-							"secret/checksum":        n.GetSecretsCheckSum(),
-							"operator/version":       application.GetVersion(ctx),
+							"secret/checksum":  n.GetServerSecretsCheckSum(),
+							"operator/version": application.GetVersion(ctx),
 						},
 						Labels: map[string]string{
 							"app":      NotaryServerName,
@@ -68,14 +67,12 @@ func (n *Notary) GetDeployments(ctx context.Context) []*appsv1.Deployment { // n
 					Spec: corev1.PodSpec{
 						NodeSelector:                 n.harbor.Spec.Components.Notary.Server.NodeSelector,
 						AutomountServiceAccountToken: &varFalse,
-						Volumes: []corev1.Volume{
+						Volumes: append([]corev1.Volume{
 							{
-								Name: "config-template",
+								Name: "config",
 								VolumeSource: corev1.VolumeSource{
-									ConfigMap: &corev1.ConfigMapVolumeSource{
-										LocalObjectReference: corev1.LocalObjectReference{
-											Name: n.harbor.NormalizeComponentName(NotaryServerName),
-										},
+									Secret: &corev1.SecretVolumeSource{
+										SecretName: render.GetConfigSecretName(n.harbor, NotaryServerName),
 										Items: []corev1.KeyToPath{
 											{
 												Key:  serverConfigKey,
@@ -84,9 +81,6 @@ func (n *Notary) GetDeployments(ctx context.Context) []*appsv1.Deployment { // n
 										},
 									},
 								},
-							}, {
-								Name:         "config",
-								VolumeSource: corev1.VolumeSource{},
 							}, {
 								Name: "notary-certificate",
 								VolumeSource: corev1.VolumeSource{
@@ -102,7 +96,7 @@ func (n *Notary) GetDeployments(ctx context.Context) []*appsv1.Deployment { // n
 									},
 								},
 							},
-						},
+						}, internaltls.GetVolumes(n.harbor, NotaryServerName)...),
 						InitContainers: []corev1.Container{
 							{
 								Name:  "init-db",
@@ -123,47 +117,6 @@ func (n *Notary) GetDeployments(ctx context.Context) []*appsv1.Deployment { // n
 										},
 									},
 								},
-							}, {
-								Name:       "configuration",
-								Image:      initImage,
-								WorkingDir: "/workdir",
-								Args:       []string{"--input-dir", "/workdir", "--output-dir", "/processed"},
-								VolumeMounts: []corev1.VolumeMount{
-									{
-										Name:      "config-template",
-										MountPath: "/workdir",
-										ReadOnly:  true,
-									}, {
-										Name:      "config",
-										MountPath: "/processed",
-										ReadOnly:  false,
-									},
-								},
-								EnvFrom: []corev1.EnvFromSource{
-									{
-										SecretRef: &corev1.SecretEnvSource{
-											Optional: &varFalse,
-											LocalObjectReference: corev1.LocalObjectReference{
-												Name: n.harbor.Spec.Components.Notary.Server.DatabaseSecret,
-											},
-										},
-									},
-								},
-								Env: []corev1.EnvVar{
-									{
-										Name:  "core_public_url",
-										Value: n.harbor.Spec.PublicURL,
-									}, {
-										Name:  "notary_server_port",
-										Value: strconv.Itoa(notaryServerPort),
-									}, {
-										Name:  "notary_signer_url",
-										Value: n.harbor.NormalizeComponentName(NotarySignerName),
-									}, {
-										Name:  "notary_signer_key_algorithm",
-										Value: notarySignerKeyAlgorithm,
-									},
-								},
 							},
 						},
 						Containers: []corev1.Container{
@@ -176,7 +129,7 @@ func (n *Notary) GetDeployments(ctx context.Context) []*appsv1.Deployment { // n
 									"/etc/notary/server-config.json",
 								},
 								ImagePullPolicy: corev1.PullAlways,
-								VolumeMounts: []corev1.VolumeMount{
+								VolumeMounts: append([]corev1.VolumeMount{
 									{
 										Name:      "token-certificate",
 										MountPath: "/etc/ssl/notary/auth-token.crt",
@@ -190,14 +143,13 @@ func (n *Notary) GetDeployments(ctx context.Context) []*appsv1.Deployment { // n
 										MountPath: "/etc/notary/server-config.json",
 										SubPath:   serverConfigKey,
 									},
-								},
+								}, internaltls.GetVolumeMounts(n.harbor)...),
 							},
 						},
 						Priority: n.Option.GetPriority(),
 					},
 				},
 				RevisionHistoryLimit: &revisionHistoryLimit,
-				Paused:               n.harbor.Spec.Paused,
 			},
 		},
 		{
@@ -222,9 +174,8 @@ func (n *Notary) GetDeployments(ctx context.Context) []*appsv1.Deployment { // n
 				Template: corev1.PodTemplateSpec{
 					ObjectMeta: metav1.ObjectMeta{
 						Annotations: map[string]string{
-							"configuration/checksum": n.GetConfigMapsCheckSum(),
-							"secret/checksum":        n.GetSecretsCheckSum(),
-							"operator/version":       application.GetVersion(ctx),
+							"secret/checksum":  n.GetSignerSecretsCheckSum(),
+							"operator/version": application.GetVersion(ctx),
 						},
 						Labels: map[string]string{
 							"app":      NotarySignerName,
@@ -237,12 +188,10 @@ func (n *Notary) GetDeployments(ctx context.Context) []*appsv1.Deployment { // n
 						AutomountServiceAccountToken: &varFalse,
 						Volumes: []corev1.Volume{
 							{
-								Name: "config-template",
+								Name: "config",
 								VolumeSource: corev1.VolumeSource{
-									ConfigMap: &corev1.ConfigMapVolumeSource{
-										LocalObjectReference: corev1.LocalObjectReference{
-											Name: n.harbor.NormalizeComponentName(NotarySignerName),
-										},
+									Secret: &corev1.SecretVolumeSource{
+										SecretName: render.GetConfigSecretName(n.harbor, NotarySignerName),
 										Items: []corev1.KeyToPath{
 											{
 												Key:  signerConfigKey,
@@ -251,9 +200,6 @@ func (n *Notary) GetDeployments(ctx context.Context) []*appsv1.Deployment { // n
 										},
 									},
 								},
-							}, {
-								Name:         "config",
-								VolumeSource: corev1.VolumeSource{},
 							}, {
 								Name: "notary-certificate",
 								VolumeSource: corev1.VolumeSource{
@@ -283,38 +229,6 @@ func (n *Notary) GetDeployments(ctx context.Context) []*appsv1.Deployment { // n
 										},
 									},
 								},
-							}, {
-								Name:       "configuration",
-								Image:      initImage,
-								WorkingDir: "/workdir",
-								Args:       []string{"--input-dir", "/workdir", "--output-dir", "/processed"},
-								VolumeMounts: []corev1.VolumeMount{
-									{
-										Name:      "config-template",
-										MountPath: "/workdir",
-										ReadOnly:  true,
-									}, {
-										Name:      "config",
-										MountPath: "/processed",
-										ReadOnly:  false,
-									},
-								},
-								EnvFrom: []corev1.EnvFromSource{
-									{
-										SecretRef: &corev1.SecretEnvSource{
-											Optional: &varFalse,
-											LocalObjectReference: corev1.LocalObjectReference{
-												Name: n.harbor.Spec.Components.Notary.Signer.DatabaseSecret,
-											},
-										},
-									},
-								},
-								Env: []corev1.EnvVar{
-									{
-										Name:  "notary_signer_port",
-										Value: strconv.Itoa(notarySignerPort),
-									},
-								},
 							},
 						},
 						Containers: []corev1.Container{
@@ -354,7 +268,6 @@ func (n *Notary) GetDeployments(ctx context.Context) []*appsv1.Deployment { // n
 					},
 				},
 				RevisionHistoryLimit: &revisionHistoryLimit,
-				Paused:               n.harbor.Spec.Paused,
 			},
 		},
 	}
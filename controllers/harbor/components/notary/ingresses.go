@@ -11,10 +11,15 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/internaltls"
 	"github.com/goharbor/harbor-operator/pkg/factories/application"
 )
 
 func (n *Notary) GetIngresses(ctx context.Context) []*netv1.Ingress {
+	if n.harbor.Spec.Expose.GetType() != goharborv1alpha1.IngressExposeType {
+		return []*netv1.Ingress{}
+	}
+
 	operatorName := application.GetName(ctx)
 	harborName := n.harbor.Name
 
@@ -29,7 +34,7 @@ func (n *Notary) GetIngresses(ctx context.Context) []*netv1.Ingress {
 	if u.Scheme == "https" {
 		tls = []netv1.IngressTLS{
 			{
-				SecretName: n.harbor.Spec.TLSSecretName,
+				SecretName: n.harbor.GetTLSSecretName(),
 			},
 		}
 	}
@@ -37,8 +42,9 @@ func (n *Notary) GetIngresses(ctx context.Context) []*netv1.Ingress {
 	return []*netv1.Ingress{
 		{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      n.harbor.NormalizeComponentName(goharborv1alpha1.NotaryName),
-				Namespace: n.harbor.Namespace,
+				Name:        n.harbor.NormalizeComponentName(goharborv1alpha1.NotaryName),
+				Namespace:   n.harbor.Namespace,
+				Annotations: internaltls.GetIngressAnnotations(n.harbor),
 				Labels: map[string]string{
 					"app":                         goharborv1alpha1.NotaryName,
 					"harbor":                      harborName,
@@ -58,7 +64,7 @@ func (n *Notary) GetIngresses(ctx context.Context) []*netv1.Ingress {
 										Path: "/",
 										Backend: netv1.IngressBackend{
 											ServiceName: n.harbor.NormalizeComponentName(NotaryServerName),
-											ServicePort: intstr.FromInt(PublicPort),
+											ServicePort: intstr.FromInt(int(internaltls.GetPublicPort(n.harbor, PublicPort))),
 										},
 									},
 								},
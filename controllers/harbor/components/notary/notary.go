@@ -4,6 +4,7 @@ import (
 	"context"
 
 	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/render"
 )
 
 const (
@@ -12,8 +13,10 @@ const (
 )
 
 type Notary struct {
-	harbor *goharborv1alpha1.Harbor
-	Option Option
+	harbor        *goharborv1alpha1.Harbor
+	serverConfigs render.Configs
+	signerConfigs render.Configs
+	Option        Option
 }
 
 type Option interface {
@@ -0,0 +1,64 @@
+package notary
+
+import (
+	"context"
+	"net/url"
+
+	routev1 "github.com/openshift/api/route/v1"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/pkg/factories/application"
+)
+
+func (n *Notary) GetRoutes(ctx context.Context) ([]*routev1.Route, error) {
+	if n.harbor.Spec.Expose.GetType() != goharborv1alpha1.RouteExposeType {
+		return []*routev1.Route{}, nil
+	}
+
+	operatorName := application.GetName(ctx)
+	harborName := n.harbor.Name
+
+	u, err := url.Parse(n.harbor.Spec.Components.Notary.PublicURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid url")
+	}
+
+	host := u.Hostname()
+
+	var tls *routev1.TLSConfig
+	if u.Scheme == "https" {
+		tls = &routev1.TLSConfig{
+			Termination:                   routev1.TLSTerminationType(n.harbor.Spec.Expose.GetRouteTermination()),
+			InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+		}
+	}
+
+	return []*routev1.Route{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      n.harbor.NormalizeComponentName(goharborv1alpha1.NotaryName),
+				Namespace: n.harbor.Namespace,
+				Labels: map[string]string{
+					"app":      goharborv1alpha1.NotaryName,
+					"harbor":   harborName,
+					"operator": operatorName,
+				},
+			},
+			Spec: routev1.RouteSpec{
+				Host: host,
+				Path: "/",
+				To: routev1.RouteTargetReference{
+					Kind: "Service",
+					Name: n.harbor.NormalizeComponentName(NotaryServerName),
+				},
+				Port: &routev1.RoutePort{
+					TargetPort: intstr.FromInt(notaryServerPort),
+				},
+				TLS: tls,
+			},
+		},
+	}, nil
+}
@@ -6,15 +6,58 @@ import (
 	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/render"
+	"github.com/goharbor/harbor-operator/pkg/factories/application"
 )
 
 func (n *Notary) GetSecrets(ctx context.Context) []*corev1.Secret {
-	return []*corev1.Secret{}
+	operatorName := application.GetName(ctx)
+	harborName := n.harbor.Name
+
+	return []*corev1.Secret{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      render.GetConfigSecretName(n.harbor, NotaryServerName),
+				Namespace: n.harbor.Namespace,
+				Labels: map[string]string{
+					"app":      NotaryServerName,
+					"harbor":   harborName,
+					"operator": operatorName,
+				},
+			},
+			Type: corev1.SecretTypeOpaque,
+			Data: map[string][]byte{
+				serverConfigKey: n.serverConfigs[serverConfigKey],
+			},
+		}, {
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      render.GetConfigSecretName(n.harbor, NotarySignerName),
+				Namespace: n.harbor.Namespace,
+				Labels: map[string]string{
+					"app":      NotarySignerName,
+					"harbor":   harborName,
+					"operator": operatorName,
+				},
+			},
+			Type: corev1.SecretTypeOpaque,
+			Data: map[string][]byte{
+				signerConfigKey: n.signerConfigs[signerConfigKey],
+			},
+		},
+	}
+}
+
+func (n *Notary) GetServerSecretsCheckSum() string {
+	value := fmt.Sprintf("%s\n%s", n.harbor.Spec.Components.Notary.Server.DatabaseSecret, n.serverConfigs.CheckSum())
+	sum := sha256.New().Sum([]byte(value))
+
+	return fmt.Sprintf("%x", sum)
 }
 
-func (n *Notary) GetSecretsCheckSum() string {
-	// TODO get generation of the secret
-	value := fmt.Sprintf("%s\n%s", n.harbor.Spec.Components.Notary.Server.DatabaseSecret, n.harbor.Spec.Components.Notary.Signer.DatabaseSecret)
+func (n *Notary) GetSignerSecretsCheckSum() string {
+	value := fmt.Sprintf("%s\n%s", n.harbor.Spec.Components.Notary.Signer.DatabaseSecret, n.signerConfigs.CheckSum())
 	sum := sha256.New().Sum([]byte(value))
 
 	return fmt.Sprintf("%x", sum)
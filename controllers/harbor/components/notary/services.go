@@ -7,6 +7,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/internaltls"
 	"github.com/goharbor/harbor-operator/pkg/factories/application"
 )
 
@@ -34,7 +35,7 @@ func (n *Notary) GetServices(ctx context.Context) []*corev1.Service {
 				Ports: []corev1.ServicePort{
 					{
 						Name:       NotaryServerName,
-						Port:       PublicPort,
+						Port:       internaltls.GetPublicPort(n.harbor, PublicPort),
 						TargetPort: intstr.FromInt(notaryServerPort),
 					},
 				},
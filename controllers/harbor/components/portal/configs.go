@@ -2,10 +2,146 @@ package portal
 
 import (
 	"context"
+	"crypto/sha256"
+	"fmt"
 
+	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/internaltls"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/render"
+	"github.com/goharbor/harbor-operator/pkg/factories/application"
+)
+
+const (
+	configName = "nginx.conf"
 )
 
-func (*Portal) GetConfigMaps(ctx context.Context) []*corev1.ConfigMap {
-	return []*corev1.ConfigMap{}
+// https://github.com/goharbor/harbor/blob/v2.0.0/make/photon/prepare/templates/portal/nginx.conf.jinja
+const configTemplate = `worker_processes auto;
+pid /tmp/nginx.pid;
+error_log /dev/stderr {{ .ErrorLogLevel }};
+
+events {
+    worker_connections 1024;
+}
+
+http {
+    client_body_temp_path /tmp/client_body_temp;
+    proxy_temp_path /tmp/proxy_temp;
+    fastcgi_temp_path /tmp/fastcgi_temp;
+    uwsgi_temp_path /tmp/uwsgi_temp;
+    scgi_temp_path /tmp/scgi_temp;
+
+    server {
+        listen {{ .Port }} ssl;
+        server_name localhost;
+
+        ssl_certificate {{ .CertificateFile }};
+        ssl_certificate_key {{ .KeyFile }};
+        ssl_protocols TLSv1.2;
+        ssl_prefer_server_ciphers on;
+        ssl_session_cache shared:SSL:10m;
+
+        root /usr/share/nginx/html;
+        index index.html index.htm;
+        include /etc/nginx/mime.types;
+
+        gzip on;
+        gzip_min_length 1000;
+        gzip_proxied expired no-cache no-store private auth;
+        gzip_types text/plain text/css application/json application/javascript application/x-javascript text/xml application/xml application/xml+rss text/javascript;
+
+        location / {
+            try_files $uri $uri/ /index.html;
+        }
+
+        location = /index.html {
+            add_header Cache-Control "no-store, no-cache, must-revalidate";
+        }
+    }
+}
+`
+
+type configData struct {
+	Port            int
+	CertificateFile string
+	KeyFile         string
+	ErrorLogLevel   string
+}
+
+// http://nginx.org/en/docs/ngx_core_module.html#error_log
+var errorLogLevels = map[goharborv1alpha1.LogLevel]string{
+	goharborv1alpha1.DebugLogLevel:   "debug",
+	goharborv1alpha1.InfoLogLevel:    "info",
+	goharborv1alpha1.WarningLogLevel: "warn",
+	goharborv1alpha1.ErrorLogLevel:   "error",
+	goharborv1alpha1.FatalLogLevel:   "crit",
+}
+
+// The nginx configuration embedded in the image is used unless internal TLS is enabled,
+// so the log level is ignored without internal TLS.
+func (p *Portal) RenderConfigs(ctx context.Context, reader client.Reader) error {
+	if !p.harbor.Spec.InternalTLS.IsEnabled() {
+		p.configs = render.Configs{}
+
+		return nil
+	}
+
+	config, err := render.Template(configName, configTemplate, configData{
+		Port:            port,
+		CertificateFile: internaltls.CertificateFile,
+		KeyFile:         internaltls.KeyFile,
+		ErrorLogLevel:   errorLogLevels[p.harbor.Spec.Components.Portal.GetLogLevel()],
+	})
+	if err != nil {
+		return errors.Wrap(err, "cannot render portal configuration")
+	}
+
+	p.configs = render.Configs{
+		configName: config,
+	}
+
+	return nil
+}
+
+func (p *Portal) GetConfigMaps(ctx context.Context) []*corev1.ConfigMap {
+	if !p.harbor.Spec.InternalTLS.IsEnabled() {
+		return []*corev1.ConfigMap{}
+	}
+
+	operatorName := application.GetName(ctx)
+	harborName := p.harbor.Name
+
+	return []*corev1.ConfigMap{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      p.harbor.NormalizeComponentName(goharborv1alpha1.PortalName),
+				Namespace: p.harbor.Namespace,
+				Labels: map[string]string{
+					"app":      goharborv1alpha1.PortalName,
+					"harbor":   harborName,
+					"operator": operatorName,
+				},
+			},
+
+			BinaryData: map[string][]byte{
+				configName: p.configs[configName],
+			},
+		},
+	}
+}
+
+func (p *Portal) GetConfigMapsCheckSum() string {
+	if !p.harbor.Spec.InternalTLS.IsEnabled() {
+		return ""
+	}
+
+	value := p.configs.CheckSum()
+	sum := sha256.New().Sum([]byte(value))
+
+	return fmt.Sprintf("%x", sum)
 }
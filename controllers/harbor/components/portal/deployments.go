@@ -2,6 +2,7 @@ package portal
 
 import (
 	"context"
+	"path"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -9,11 +10,13 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/internaltls"
 	"github.com/goharbor/harbor-operator/pkg/factories/application"
 )
 
 const (
-	port = 8080
+	port       = 8080
+	configPath = "/etc/nginx"
 )
 
 var (
@@ -25,6 +28,28 @@ func (p *Portal) GetDeployments(ctx context.Context) []*appsv1.Deployment { // n
 	operatorName := application.GetName(ctx)
 	harborName := p.harbor.GetName()
 
+	volumes := internaltls.GetVolumes(p.harbor, goharborv1alpha1.PortalName)
+	volumeMounts := internaltls.GetVolumeMounts(p.harbor)
+
+	if p.harbor.Spec.InternalTLS.IsEnabled() {
+		volumes = append(volumes, corev1.Volume{
+			Name: "config",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: p.harbor.NormalizeComponentName(goharborv1alpha1.PortalName),
+					},
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      "config",
+			MountPath: path.Join(configPath, configName),
+			SubPath:   configName,
+			ReadOnly:  true,
+		})
+	}
+
 	return []*appsv1.Deployment{
 		{
 			ObjectMeta: metav1.ObjectMeta{
@@ -48,7 +73,7 @@ func (p *Portal) GetDeployments(ctx context.Context) []*appsv1.Deployment { // n
 				Template: corev1.PodTemplateSpec{
 					ObjectMeta: metav1.ObjectMeta{
 						Annotations: map[string]string{
-							"configuration/checksum": "",
+							"configuration/checksum": p.GetConfigMapsCheckSum(),
 							"secret/checksum":        "",
 							"operator/version":       application.GetVersion(ctx),
 						},
@@ -61,6 +86,7 @@ func (p *Portal) GetDeployments(ctx context.Context) []*appsv1.Deployment { // n
 					Spec: corev1.PodSpec{
 						NodeSelector:                 p.harbor.Spec.Components.Portal.NodeSelector,
 						AutomountServiceAccountToken: &varFalse,
+						Volumes:                      volumes,
 						Containers: []corev1.Container{
 							{
 								Name:  "portal",
@@ -75,26 +101,28 @@ func (p *Portal) GetDeployments(ctx context.Context) []*appsv1.Deployment { // n
 								LivenessProbe: &corev1.Probe{
 									Handler: corev1.Handler{
 										HTTPGet: &corev1.HTTPGetAction{
-											Path: "/",
-											Port: intstr.FromInt(port),
+											Path:   "/",
+											Port:   intstr.FromInt(port),
+											Scheme: internaltls.GetURIScheme(p.harbor),
 										},
 									},
 								},
 								ReadinessProbe: &corev1.Probe{
 									Handler: corev1.Handler{
 										HTTPGet: &corev1.HTTPGetAction{
-											Path: "/",
-											Port: intstr.FromInt(port),
+											Path:   "/",
+											Port:   intstr.FromInt(port),
+											Scheme: internaltls.GetURIScheme(p.harbor),
 										},
 									},
 								},
+								VolumeMounts: volumeMounts,
 							},
 						},
 						Priority: p.Option.GetPriority(),
 					},
 				},
 				RevisionHistoryLimit: &revisionHistoryLimit,
-				Paused:               p.harbor.Spec.Paused,
 			},
 		},
 	}
@@ -11,10 +11,15 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/internaltls"
 	"github.com/goharbor/harbor-operator/pkg/factories/application"
 )
 
 func (p *Portal) GetIngresses(ctx context.Context) []*netv1.Ingress { // nolint:funlen
+	if p.harbor.Spec.Expose.GetType() != goharborv1alpha1.IngressExposeType {
+		return []*netv1.Ingress{}
+	}
+
 	operatorName := application.GetName(ctx)
 	harborName := p.harbor.Name
 
@@ -29,7 +34,7 @@ func (p *Portal) GetIngresses(ctx context.Context) []*netv1.Ingress { // nolint:
 	if u.Scheme == "https" {
 		tls = []netv1.IngressTLS{
 			{
-				SecretName: p.harbor.Spec.TLSSecretName,
+				SecretName: p.harbor.GetTLSSecretName(),
 			},
 		}
 	}
@@ -37,8 +42,9 @@ func (p *Portal) GetIngresses(ctx context.Context) []*netv1.Ingress { // nolint:
 	return []*netv1.Ingress{
 		{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      p.harbor.NormalizeComponentName(goharborv1alpha1.PortalName),
-				Namespace: p.harbor.Namespace,
+				Name:        p.harbor.NormalizeComponentName(goharborv1alpha1.PortalName),
+				Namespace:   p.harbor.Namespace,
+				Annotations: internaltls.GetIngressAnnotations(p.harbor),
 				Labels: map[string]string{
 					"app":      goharborv1alpha1.PortalName,
 					"harbor":   harborName,
@@ -57,7 +63,7 @@ func (p *Portal) GetIngresses(ctx context.Context) []*netv1.Ingress { // nolint:
 										Path: "/",
 										Backend: netv1.IngressBackend{
 											ServiceName: p.harbor.NormalizeComponentName(goharborv1alpha1.PortalName),
-											ServicePort: intstr.FromInt(PublicPort),
+											ServicePort: intstr.FromInt(int(internaltls.GetPublicPort(p.harbor, PublicPort))),
 										},
 									},
 								},
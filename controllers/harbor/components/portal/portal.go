@@ -4,11 +4,13 @@ import (
 	"context"
 
 	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/render"
 )
 
 type Portal struct {
-	harbor *goharborv1alpha1.Harbor
-	Option Option
+	harbor  *goharborv1alpha1.Harbor
+	configs render.Configs
+	Option  Option
 }
 
 type Option interface {
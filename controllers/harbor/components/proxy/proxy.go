@@ -0,0 +1,71 @@
+package proxy
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/notary"
+)
+
+// https://github.com/goharbor/harbor/blob/v2.0.0/make/photon/prepare/utils/configs.py
+var defaultNoProxy = []string{"127.0.0.1", "localhost", ".local", ".internal"}
+
+// GetNoProxy returns the hosts reached without proxy: local addresses,
+// the services of every Harbor component and hosts listed in the spec.
+func GetNoProxy(harbor *goharborv1alpha1.Harbor) string {
+	hosts := append([]string{}, defaultNoProxy...)
+
+	for _, component := range []string{
+		goharborv1alpha1.CoreName,
+		goharborv1alpha1.PortalName,
+		goharborv1alpha1.RegistryName,
+		goharborv1alpha1.JobServiceName,
+		goharborv1alpha1.ChartMuseumName,
+		goharborv1alpha1.ClairName,
+		notary.NotaryServerName,
+		notary.NotarySignerName,
+	} {
+		hosts = append(hosts, harbor.NormalizeComponentName(component))
+	}
+
+	if harbor.Spec.Proxy != nil {
+		hosts = append(hosts, harbor.Spec.Proxy.NoProxy...)
+	}
+
+	seen := make(map[string]bool, len(hosts))
+	result := make([]string, 0, len(hosts))
+
+	for _, host := range hosts {
+		if !seen[host] {
+			seen[host] = true
+
+			result = append(result, host)
+		}
+	}
+
+	return strings.Join(result, ",")
+}
+
+// GetEnv returns the proxy environment variables of the component.
+// Components not listed in the spec do not use any proxy.
+// https://github.com/goharbor/harbor/blob/v2.0.0/make/photon/prepare/templates/core/env.jinja
+func GetEnv(harbor *goharborv1alpha1.Harbor, component goharborv1alpha1.ProxyComponent) []corev1.EnvVar {
+	if !harbor.Spec.Proxy.IsEnabled(component) {
+		return nil
+	}
+
+	return []corev1.EnvVar{
+		{
+			Name:  "HTTP_PROXY",
+			Value: harbor.Spec.Proxy.HTTPProxy,
+		}, {
+			Name:  "HTTPS_PROXY",
+			Value: harbor.Spec.Proxy.HTTPSProxy,
+		}, {
+			Name:  "NO_PROXY",
+			Value: GetNoProxy(harbor),
+		},
+	}
+}
@@ -0,0 +1,64 @@
+package proxy
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+)
+
+func TestProxy(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecsWithDefaultAndCustomReporters(t, "Proxy Suite", []Reporter{envtest.NewlineReporter{}})
+}
+
+var _ = Describe("Proxy", func() {
+	var harbor *goharborv1alpha1.Harbor
+
+	BeforeEach(func() {
+		harbor = &goharborv1alpha1.Harbor{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "my",
+				Namespace: "registry",
+			},
+		}
+	})
+
+	Context("Without proxy", func() {
+		It("Should not set any variable", func() {
+			Expect(GetEnv(harbor, goharborv1alpha1.CoreProxyComponent)).To(BeEmpty())
+		})
+	})
+
+	Context("With proxy", func() {
+		BeforeEach(func() {
+			harbor.Spec.Proxy = &goharborv1alpha1.HarborProxy{
+				HTTPProxy:  "http://proxy:3128",
+				HTTPSProxy: "http://proxy:3128",
+				NoProxy:    []string{"postgresql", "localhost"},
+				Components: []goharborv1alpha1.ProxyComponent{goharborv1alpha1.CoreProxyComponent},
+			}
+		})
+
+		It("Should only set variables of listed components", func() {
+			Expect(GetEnv(harbor, goharborv1alpha1.JobServiceProxyComponent)).To(BeEmpty())
+			Expect(GetEnv(harbor, goharborv1alpha1.CoreProxyComponent)).To(ConsistOf(
+				corev1.EnvVar{Name: "HTTP_PROXY", Value: "http://proxy:3128"},
+				corev1.EnvVar{Name: "HTTPS_PROXY", Value: "http://proxy:3128"},
+				corev1.EnvVar{Name: "NO_PROXY", Value: GetNoProxy(harbor)},
+			))
+		})
+
+		It("Should bypass the proxy for Harbor services", func() {
+			Expect(GetNoProxy(harbor)).To(Equal("127.0.0.1,localhost,.local,.internal," +
+				"my-core,my-portal,my-registry,my-jobservice,my-chartmuseum,my-clair,my-notary-server,my-notary-signer," +
+				"postgresql"))
+		})
+	})
+})
@@ -4,15 +4,16 @@ import (
 	"context"
 	"crypto/sha256"
 	"fmt"
-	"io/ioutil"
-	"sync"
 
-	"github.com/markbates/pkger"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
 
 	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/internaltls"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/render"
 	"github.com/goharbor/harbor-operator/pkg/factories/application"
 )
 
@@ -22,42 +23,242 @@ const (
 	registryCtlConfigName     = "ctl-config.yaml"
 )
 
-var (
-	once              sync.Once
-	registryConfig    []byte
-	registryCtlConfig []byte
-)
+const registryConfigTemplate = `version: 0.1
+log:
+  accesslog:
+    disabled: true
+  level: {{ .LogLevel }}
+  formatter: {{ .LogFormat }}
+http:
+  debug:
+    addr: {{ quote .MetricsAddress }}
+    prometheus:
+      enabled: true
+      path: /metrics
+  headers:
+    X-Content-Type-Options: [nosniff]
+  net: tcp
+  addr: {{ quote .APIAddress }}
+  prefix: /
+{{- if .InternalTLS }}
+  tls:
+    certificate: {{ quote .CertificateFile }}
+    key: {{ quote .KeyFile }}
+{{- end }}
+health:
+  storagedriver:
+    enabled: false
+    interval: 10s
+    threshold: 3
+notifications:
+  endpoints:
+  - name: harbor-core
+    disabled: false
+    url: {{ printf "%s/service/notifications" .CoreURL | quote }}
+    timeout: 3000ms
+    threshold: 5
+    backoff: 1s
+    ignoredmediatypes:
+    - application/vnd.docker.image.rootfs.diff.tar.gzip
+    - application/vnd.docker.image.rootfs.foreign.diff.tar.gzip
+    - application/vnd.oci.image.layer.v1.tar
+    - application/vnd.oci.image.layer.v1.tar+gzip
+    - application/vnd.oci.image.layer.v1.tar+zstd
+    - application/vnd.oci.image.layer.nondistributable.v1.tar
+    - application/vnd.oci.image.layer.nondistributable.v1.tar+gzip
+    - application/vnd.oci.image.layer.nondistributable.v1.tar+zstd
+    - application/octet-stream
+auth:
+  token:
+    issuer: harbor-token-issuer
+    rootcertbundle: /etc/registry/root.crt
+    service: harbor-registry
+{{- with .Redis }}
 
-func InitConfigMaps() {
-	{
-		file, err := pkger.Open("/assets/templates/registry/config.yaml")
-		if err != nil {
-			panic(errors.Wrapf(err, "cannot open Registry configuration template %s", "/assets/templates/registry/config.yaml"))
-		}
-		defer file.Close()
+redis:
+  addr: {{ quote .Addr }}
+  {{- if .Password }}
+  password: {{ quote .Password }}
+  {{- end }}
+  db: {{ .DB }}
+  dialtimeout: 10ms
+  readtimeout: 10ms
+  writetimeout: 10ms
+  pool:
+    maxidle: 16
+    maxactive: 64
+    idletimeout: 300s
+{{- end }}
 
-		registryConfig, err = ioutil.ReadAll(file)
-		if err != nil {
-			panic(errors.Wrapf(err, "cannot read Registry configuration template %s", "/assets/templates/registry/config.yaml"))
-		}
+validation:
+  disabled: true
+compatibility:
+  schema1:
+    enabled: true
+
+storage:
+{{ toYaml .Storage | indent 2 }}
+`
+
+const registryCtlConfigTemplate = `
+{{- if .InternalTLS }}
+protocol: "https"
+https_config:
+  cert: {{ quote .CertificateFile }}
+  key: {{ quote .KeyFile }}
+{{- else }}
+protocol: "http"
+{{- end }}
+port: {{ .Port }}
+log_level: {{ .LogLevel }}
+`
+
+type registryConfigData struct {
+	MetricsAddress  string
+	APIAddress      string
+	CoreURL         string
+	InternalTLS     bool
+	CertificateFile string
+	KeyFile         string
+	Redis           *render.Redis
+	Storage         map[string]interface{}
+	LogLevel        goharborv1alpha1.LogLevel
+	LogFormat       goharborv1alpha1.LogFormat
+}
+
+type registryCtlConfigData struct {
+	Port            int
+	InternalTLS     bool
+	CertificateFile string
+	KeyFile         string
+	LogLevel        goharborv1alpha1.LogLevel
+}
+
+func (r *Registry) RenderConfigs(ctx context.Context, reader client.Reader) error {
+	redis, err := r.getRedis(ctx, reader)
+	if err != nil {
+		return errors.Wrap(err, "cannot get cache")
+	}
+
+	storage, err := r.getStorage(ctx, reader, redis != nil)
+	if err != nil {
+		return errors.Wrap(err, "cannot get storage")
+	}
+
+	registryConfig, err := render.Template(registryConfigName, registryConfigTemplate, registryConfigData{
+		MetricsAddress:  fmt.Sprintf(":%d", MetricsPort),
+		APIAddress:      fmt.Sprintf(":%d", apiPort),
+		CoreURL:         fmt.Sprintf("%s://%s", r.harbor.Spec.InternalTLS.GetScheme(), r.harbor.NormalizeComponentName(goharborv1alpha1.CoreName)),
+		InternalTLS:     r.harbor.Spec.InternalTLS.IsEnabled(),
+		CertificateFile: internaltls.CertificateFile,
+		KeyFile:         internaltls.KeyFile,
+		Redis:           redis,
+		Storage:         storage,
+		LogLevel:        r.harbor.Spec.Components.Registry.GetLogLevel(),
+		LogFormat:       r.harbor.Spec.Components.Registry.GetLogFormat(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "cannot render registry configuration")
+	}
+
+	registryConfig, err = render.Override(ctx, reader, r.harbor.Namespace, r.harbor.Spec.Components.Registry.ConfigOverrides, registryConfigName, registryConfig)
+	if err != nil {
+		return errors.Wrap(err, "cannot override registry configuration")
+	}
+
+	registryCtlConfig, err := render.Template(registryCtlConfigName, registryCtlConfigTemplate, registryCtlConfigData{
+		Port:            ctlAPIPort,
+		InternalTLS:     r.harbor.Spec.InternalTLS.IsEnabled(),
+		CertificateFile: internaltls.CertificateFile,
+		KeyFile:         internaltls.KeyFile,
+		LogLevel:        r.harbor.Spec.Components.Registry.GetLogLevel(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "cannot render registryctl configuration")
+	}
+
+	r.configs = render.Configs{
+		registryCtlConfigName: registryCtlConfig,
+	}
+
+	r.secretConfigs = render.Configs{
+		registryConfigName: registryConfig,
+	}
+
+	return nil
+}
+
+func (r *Registry) getRedis(ctx context.Context, reader client.Reader) (*render.Redis, error) {
+	if r.harbor.Spec.Components.Registry.CacheSecret == "" {
+		return nil, nil
+	}
+
+	secret, err := render.GetSecret(ctx, reader, r.harbor.Namespace, r.harbor.Spec.Components.Registry.CacheSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	redisURL := render.GetSecretValue(secret, goharborv1alpha1.HarborRegistryURLKey, "")
+	if redisURL == "" {
+		return nil, nil
 	}
-	{
-		file, err := pkger.Open("/assets/templates/registry/ctl-config.yaml")
-		if err != nil {
-			panic(errors.Wrapf(err, "cannot open Registry configuration template %s", "/assets/templates/registry/ctl-config.yaml"))
-		}
-		defer file.Close()
 
-		registryCtlConfig, err = ioutil.ReadAll(file)
+	return render.ParseRedisURL(redisURL)
+}
+
+// Each key of the storage secret is a storage driver, and its value the driver configuration.
+// Keys of the storage secret override default settings.
+// https://docs.docker.com/registry/configuration/#storage
+func (r *Registry) getStorage(ctx context.Context, reader client.Reader, cache bool) (map[string]interface{}, error) {
+	blobDescriptor := "inmemory"
+	if cache {
+		blobDescriptor = "redis"
+	}
+
+	storage := map[string]interface{}{
+		"delete": map[string]interface{}{
+			"enabled": true,
+		},
+		"redirect": map[string]interface{}{
+			"disable": false,
+		},
+		"cache": map[string]interface{}{
+			"blobdescriptor": blobDescriptor,
+		},
+		"maintenance": map[string]interface{}{
+			"uploadpurging": map[string]interface{}{
+				"enabled": false,
+			},
+			"readonly": map[string]interface{}{
+				"enabled": false,
+			},
+		},
+	}
+
+	if r.harbor.Spec.Components.Registry.StorageSecret == "" {
+		return storage, nil
+	}
+
+	secret, err := render.GetSecret(ctx, reader, r.harbor.Namespace, r.harbor.Spec.Components.Registry.StorageSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	for driver, value := range secret.Data {
+		var config interface{}
+
+		err := yaml.Unmarshal(value, &config)
 		if err != nil {
-			panic(errors.Wrapf(err, "cannot read Registry configuration template %s", "/assets/templates/registry/ctl-config.yaml"))
+			return nil, render.NewError(errors.Wrapf(err, "invalid configuration of storage driver %s", driver))
 		}
+
+		storage[driver] = config
 	}
+
+	return storage, nil
 }
 
 func (r *Registry) GetConfigMaps(ctx context.Context) []*corev1.ConfigMap {
-	once.Do(InitConfigMaps)
-
 	operatorName := application.GetName(ctx)
 	harborName := r.harbor.Name
 
@@ -74,15 +275,14 @@ func (r *Registry) GetConfigMaps(ctx context.Context) []*corev1.ConfigMap {
 			},
 
 			BinaryData: map[string][]byte{
-				registryConfigName:    registryConfig,
-				registryCtlConfigName: registryCtlConfig,
+				registryCtlConfigName: r.configs[registryCtlConfigName],
 			},
 		},
 	}
 }
 
 func (r *Registry) GetConfigMapsCheckSum() string {
-	value := fmt.Sprintf("%x\n%x", registryCtlConfig, registryConfig)
+	value := r.configs.CheckSum()
 	sum := sha256.New().Sum([]byte(value))
 
 	return fmt.Sprintf("%x", sum)
@@ -11,13 +11,16 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/internaltls"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/proxy"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/render"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/trustedca"
 	"github.com/goharbor/harbor-operator/pkg/factories/application"
 )
 
 const (
-	initImage   = "hairyhenderson/gomplate"
 	apiPort     = 5000 // https://github.com/docker/distribution/blob/749f6afb4572201e3c37325d0ffedb6f32be8950/contrib/compose/docker-compose.yml#L15
-	metricsPort = 5001 // https://github.com/docker/distribution/blob/b12bd4004afc203f1cbd2072317c8fda30b89710/cmd/registry/config-dev.yml#L34
+	MetricsPort = 5001 // https://github.com/docker/distribution/blob/b12bd4004afc203f1cbd2072317c8fda30b89710/cmd/registry/config-dev.yml#L34
 	ctlAPIPort  = 8080 // https://github.com/goharbor/harbor/blob/2fb1cc89d9ef9313842cc68b4b7c36be73681505/src/common/const.go#L134
 )
 
@@ -26,36 +29,15 @@ var (
 	registryConfigPath          = "/etc/registry/"
 	registryCtlConfigPath       = "/etc/registryctl/"
 	varFalse                    = false
-	varTrue                     = true
 )
 
 func (r *Registry) GetDeployments(ctx context.Context) []*appsv1.Deployment { // nolint:funlen
 	operatorName := application.GetName(ctx)
 	harborName := r.harbor.GetName()
 
-	cacheEnv := corev1.EnvVar{
-		Name: "REDIS_URL",
-	}
-	if len(r.harbor.Spec.Components.Registry.CacheSecret) > 0 {
-		cacheEnv.ValueFrom = &corev1.EnvVarSource{
-			SecretKeyRef: &corev1.SecretKeySelector{
-				Key:      goharborv1alpha1.HarborRegistryURLKey,
-				Optional: &varTrue,
-				LocalObjectReference: corev1.LocalObjectReference{
-					Name: r.harbor.Spec.Components.Registry.CacheSecret,
-				},
-			},
-		}
-	}
-
-	var storageVolumeSource corev1.VolumeSource
-	if r.harbor.Spec.Components.Registry.StorageSecret == "" {
-		storageVolumeSource.EmptyDir = &corev1.EmptyDirVolumeSource{}
-	} else {
-		storageVolumeSource.Secret = &corev1.SecretVolumeSource{
-			SecretName: r.harbor.Spec.Components.Registry.StorageSecret,
-		}
-	}
+	volumes := append(internaltls.GetVolumes(r.harbor, goharborv1alpha1.RegistryName), trustedca.GetVolumes(r.harbor)...)
+	registryVolumeMounts := append(internaltls.GetVolumeMounts(r.harbor), trustedca.GetVolumeMounts(r.harbor)...)
+	registryEnv := append(internaltls.GetEnv(r.harbor), proxy.GetEnv(r.harbor, goharborv1alpha1.RegistryProxyComponent)...)
 
 	return []*appsv1.Deployment{
 		{
@@ -93,24 +75,35 @@ func (r *Registry) GetDeployments(ctx context.Context) []*appsv1.Deployment { //
 					Spec: corev1.PodSpec{
 						NodeSelector:                 r.harbor.Spec.Components.Registry.NodeSelector,
 						AutomountServiceAccountToken: &varFalse,
-						Volumes: []corev1.Volume{
+						Volumes: append([]corev1.Volume{
 							{
 								Name: "config",
-								VolumeSource: corev1.VolumeSource{
-									EmptyDir: &corev1.EmptyDirVolumeSource{},
-								},
-							}, {
-								Name: "config-template",
 								VolumeSource: corev1.VolumeSource{
 									ConfigMap: &corev1.ConfigMapVolumeSource{
 										LocalObjectReference: corev1.LocalObjectReference{
 											Name: r.harbor.NormalizeComponentName(goharborv1alpha1.RegistryName),
 										},
+										Items: []corev1.KeyToPath{
+											{
+												Key:  registryCtlConfigName,
+												Path: registryCtlConfigName,
+											},
+										},
 									},
 								},
 							}, {
-								Name:         "config-storage",
-								VolumeSource: storageVolumeSource,
+								Name: "registry-config",
+								VolumeSource: corev1.VolumeSource{
+									Secret: &corev1.SecretVolumeSource{
+										SecretName: render.GetConfigSecretName(r.harbor, goharborv1alpha1.RegistryName),
+										Items: []corev1.KeyToPath{
+											{
+												Key:  registryConfigName,
+												Path: registryConfigName,
+											},
+										},
+									},
+								},
 							}, {
 								Name: "certificate",
 								VolumeSource: corev1.VolumeSource{
@@ -119,56 +112,7 @@ func (r *Registry) GetDeployments(ctx context.Context) []*appsv1.Deployment { //
 									},
 								},
 							},
-						},
-						InitContainers: []corev1.Container{
-							{
-								Name:            "configuration",
-								Image:           initImage,
-								WorkingDir:      "/workdir",
-								Args:            []string{"--input-dir", "/workdir", "--output-dir", "/processed"},
-								SecurityContext: &corev1.SecurityContext{},
-								VolumeMounts: []corev1.VolumeMount{
-									{
-										Name:      "config-template",
-										MountPath: path.Join("/workdir", registryConfigName),
-										ReadOnly:  true,
-										SubPath:   registryConfigName,
-									}, {
-										Name:      "config-template",
-										MountPath: path.Join("/workdir", registryCtlConfigName),
-										ReadOnly:  true,
-										SubPath:   registryCtlConfigName,
-									}, {
-										Name:      "config-storage",
-										MountPath: "/opt/configuration/storage",
-										ReadOnly:  true,
-									}, {
-										Name:      "config",
-										MountPath: "/processed",
-										ReadOnly:  false,
-									},
-								},
-								Env: []corev1.EnvVar{
-									{
-										Name:  "STORAGE_CONFIG",
-										Value: "/opt/configuration/storage",
-									}, {
-										Name:  "CORE_HOSTNAME",
-										Value: r.harbor.NormalizeComponentName(goharborv1alpha1.CoreName),
-									}, {
-										Name:  "METRICS_ADDRESS",
-										Value: fmt.Sprintf(":%d", metricsPort),
-									}, {
-										Name:  "API_ADDRESS",
-										Value: fmt.Sprintf(":%d", apiPort),
-									}, {
-										Name:  "REGISTRYCTL_PORT",
-										Value: fmt.Sprintf("%d", ctlAPIPort),
-									},
-									cacheEnv,
-								},
-							},
-						},
+						}, volumes...),
 						Containers: []corev1.Container{
 							{
 								Name:  "registryctl",
@@ -178,7 +122,7 @@ func (r *Registry) GetDeployments(ctx context.Context) []*appsv1.Deployment { //
 										ContainerPort: ctlAPIPort,
 									},
 								},
-								Env: []corev1.EnvVar{
+								Env: append([]corev1.EnvVar{
 									{
 										Name: "CORE_SECRET",
 										ValueFrom: &corev1.EnvVarSource{
@@ -214,28 +158,30 @@ func (r *Registry) GetDeployments(ctx context.Context) []*appsv1.Deployment { //
 										Name:  "REGISTRY_LOG_FIELDS_HARBOR",
 										Value: harborName,
 									},
-								},
+								}, internaltls.GetEnv(r.harbor)...),
 								ImagePullPolicy: corev1.PullAlways,
 								LivenessProbe: &corev1.Probe{
 									Handler: corev1.Handler{
 										HTTPGet: &corev1.HTTPGetAction{
-											Path: "/api/health",
-											Port: intstr.FromInt(ctlAPIPort),
+											Path:   "/api/health",
+											Port:   intstr.FromInt(ctlAPIPort),
+											Scheme: internaltls.GetURIScheme(r.harbor),
 										},
 									},
 								},
 								ReadinessProbe: &corev1.Probe{
 									Handler: corev1.Handler{
 										HTTPGet: &corev1.HTTPGetAction{
-											Path: "/api/health",
-											Port: intstr.FromInt(ctlAPIPort),
+											Path:   "/api/health",
+											Port:   intstr.FromInt(ctlAPIPort),
+											Scheme: internaltls.GetURIScheme(r.harbor),
 										},
 									},
 								},
-								VolumeMounts: []corev1.VolumeMount{
+								VolumeMounts: append([]corev1.VolumeMount{
 									{
 										MountPath: path.Join(registryConfigPath, defaultRegistryConfigName),
-										Name:      "config",
+										Name:      "registry-config",
 										SubPath:   registryConfigName,
 									}, {
 										MountPath: path.Join(registryCtlConfigPath, registryCtlConfigName),
@@ -246,7 +192,7 @@ func (r *Registry) GetDeployments(ctx context.Context) []*appsv1.Deployment { //
 										Name:      "certificate",
 										SubPath:   "tls.crt",
 									},
-								},
+								}, internaltls.GetVolumeMounts(r.harbor)...),
 								Command: []string{"/home/harbor/harbor_registryctl"},
 								Args:    []string{"-c", path.Join(registryCtlConfigPath, registryCtlConfigName)},
 							}, {
@@ -256,10 +202,10 @@ func (r *Registry) GetDeployments(ctx context.Context) []*appsv1.Deployment { //
 									{
 										ContainerPort: apiPort,
 									}, {
-										ContainerPort: metricsPort,
+										ContainerPort: MetricsPort,
 									},
 								},
-								Env: []corev1.EnvVar{
+								Env: append([]corev1.EnvVar{
 									{
 										Name:  "REGISTRY_HTTP_HOST",
 										Value: r.harbor.Spec.PublicURL,
@@ -273,14 +219,14 @@ func (r *Registry) GetDeployments(ctx context.Context) []*appsv1.Deployment { //
 										Name:  "REGISTRY_LOG_FIELDS_HARBOR",
 										Value: harborName,
 									},
-								},
+								}, registryEnv...),
 								ImagePullPolicy: corev1.PullAlways,
 								LivenessProbe: &corev1.Probe{
 									Handler: corev1.Handler{
 										HTTPGet: &corev1.HTTPGetAction{
 											Path:   "/",
 											Port:   intstr.FromInt(apiPort),
-											Scheme: corev1.URISchemeHTTP,
+											Scheme: internaltls.GetURIScheme(r.harbor),
 										},
 									},
 								},
@@ -289,21 +235,21 @@ func (r *Registry) GetDeployments(ctx context.Context) []*appsv1.Deployment { //
 										HTTPGet: &corev1.HTTPGetAction{
 											Path:   "/",
 											Port:   intstr.FromInt(apiPort),
-											Scheme: corev1.URISchemeHTTP,
+											Scheme: internaltls.GetURIScheme(r.harbor),
 										},
 									},
 								},
-								VolumeMounts: []corev1.VolumeMount{
+								VolumeMounts: append([]corev1.VolumeMount{
 									{
 										MountPath: path.Join(registryConfigPath, registryConfigName),
-										Name:      "config",
+										Name:      "registry-config",
 										SubPath:   registryConfigName,
 									}, {
 										MountPath: "/etc/registry/root.crt",
 										Name:      "certificate",
 										SubPath:   "tls.crt",
 									},
-								},
+								}, registryVolumeMounts...),
 								Command: []string{"/usr/bin/registry"},
 								Args:    []string{"serve", path.Join(registryConfigPath, registryConfigName)},
 							},
@@ -312,7 +258,6 @@ func (r *Registry) GetDeployments(ctx context.Context) []*appsv1.Deployment { //
 					},
 				},
 				RevisionHistoryLimit: &revisionHistoryLimit,
-				Paused:               r.harbor.Spec.Paused,
 			},
 		},
 	}
@@ -11,10 +11,15 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/internaltls"
 	"github.com/goharbor/harbor-operator/pkg/factories/application"
 )
 
 func (r *Registry) GetIngresses(ctx context.Context) []*netv1.Ingress { // nolint:funlen
+	if r.harbor.Spec.Expose.GetType() != goharborv1alpha1.IngressExposeType {
+		return []*netv1.Ingress{}
+	}
+
 	operatorName := application.GetName(ctx)
 	harborName := r.harbor.Name
 
@@ -29,7 +34,7 @@ func (r *Registry) GetIngresses(ctx context.Context) []*netv1.Ingress { // nolin
 	if u.Scheme == "https" {
 		tls = []netv1.IngressTLS{
 			{
-				SecretName: r.harbor.Spec.TLSSecretName,
+				SecretName: r.harbor.GetTLSSecretName(),
 			},
 		}
 	}
@@ -37,8 +42,9 @@ func (r *Registry) GetIngresses(ctx context.Context) []*netv1.Ingress { // nolin
 	return []*netv1.Ingress{
 		{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      r.harbor.NormalizeComponentName(goharborv1alpha1.RegistryName),
-				Namespace: r.harbor.Namespace,
+				Name:        r.harbor.NormalizeComponentName(goharborv1alpha1.RegistryName),
+				Namespace:   r.harbor.Namespace,
+				Annotations: internaltls.GetIngressAnnotations(r.harbor),
 				Labels: map[string]string{
 					"app":      goharborv1alpha1.RegistryName,
 					"harbor":   harborName,
@@ -57,7 +63,7 @@ func (r *Registry) GetIngresses(ctx context.Context) []*netv1.Ingress { // nolin
 										Path: "/v2",
 										Backend: netv1.IngressBackend{
 											ServiceName: r.harbor.NormalizeComponentName(goharborv1alpha1.RegistryName),
-											ServicePort: intstr.FromInt(PublicPort),
+											ServicePort: intstr.FromInt(int(internaltls.GetPublicPort(r.harbor, PublicPort))),
 										},
 									},
 								},
@@ -0,0 +1,64 @@
+package registry
+
+import (
+	"context"
+	"net/url"
+
+	routev1 "github.com/openshift/api/route/v1"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/pkg/factories/application"
+)
+
+func (r *Registry) GetRoutes(ctx context.Context) ([]*routev1.Route, error) {
+	if r.harbor.Spec.Expose.GetType() != goharborv1alpha1.RouteExposeType {
+		return []*routev1.Route{}, nil
+	}
+
+	operatorName := application.GetName(ctx)
+	harborName := r.harbor.Name
+
+	u, err := url.Parse(r.harbor.Spec.PublicURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid url")
+	}
+
+	host := u.Hostname()
+
+	var tls *routev1.TLSConfig
+	if u.Scheme == "https" {
+		tls = &routev1.TLSConfig{
+			Termination:                   routev1.TLSTerminationType(r.harbor.Spec.Expose.GetRouteTermination()),
+			InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+		}
+	}
+
+	return []*routev1.Route{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      r.harbor.NormalizeComponentName(goharborv1alpha1.RegistryName),
+				Namespace: r.harbor.Namespace,
+				Labels: map[string]string{
+					"app":      goharborv1alpha1.RegistryName,
+					"harbor":   harborName,
+					"operator": operatorName,
+				},
+			},
+			Spec: routev1.RouteSpec{
+				Host: host,
+				Path: "/v2",
+				To: routev1.RouteTargetReference{
+					Kind: "Service",
+					Name: r.harbor.NormalizeComponentName(goharborv1alpha1.RegistryName),
+				},
+				Port: &routev1.RoutePort{
+					TargetPort: intstr.FromInt(apiPort),
+				},
+				TLS: tls,
+			},
+		},
+	}, nil
+}
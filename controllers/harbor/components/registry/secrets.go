@@ -10,6 +10,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/render"
 	"github.com/goharbor/harbor-operator/pkg/factories/application"
 )
 
@@ -36,13 +37,26 @@ func (r *Registry) GetSecrets(ctx context.Context) []*corev1.Secret {
 			StringData: map[string]string{
 				"REGISTRY_HTTP_SECRET": password.MustGenerate(keyLength, 5, 5, false, true),
 			},
+		}, {
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      render.GetConfigSecretName(r.harbor, goharborv1alpha1.RegistryName),
+				Namespace: r.harbor.Namespace,
+				Labels: map[string]string{
+					"app":      goharborv1alpha1.RegistryName,
+					"harbor":   harborName,
+					"operator": operatorName,
+				},
+			},
+			Type: corev1.SecretTypeOpaque,
+			Data: map[string][]byte{
+				registryConfigName: r.secretConfigs[registryConfigName],
+			},
 		},
 	}
 }
 
 func (r *Registry) GetSecretsCheckSum() string {
-	// TODO get generation of the secrets
-	value := fmt.Sprintf("%s\n%s", r.harbor.Spec.Components.Registry.CacheSecret, r.harbor.Spec.Components.Registry.StorageSecret)
+	value := fmt.Sprintf("%s\n%s\n%s", r.harbor.Spec.Components.Registry.CacheSecret, r.harbor.Spec.Components.Registry.StorageSecret, r.secretConfigs.CheckSum())
 	sum := sha256.New().Sum([]byte(value))
 
 	return fmt.Sprintf("%x", sum)
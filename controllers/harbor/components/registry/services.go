@@ -8,6 +8,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/internaltls"
 	"github.com/goharbor/harbor-operator/pkg/factories/application"
 )
 
@@ -35,10 +36,10 @@ func (r *Registry) GetServices(ctx context.Context) []*corev1.Service {
 					{
 						Name:       "registry",
 						TargetPort: intstr.FromInt(apiPort),
-						Port:       PublicPort,
+						Port:       internaltls.GetPublicPort(r.harbor, PublicPort),
 					}, {
 						Name: "registry-debug",
-						Port: metricsPort,
+						Port: MetricsPort,
 					}, {
 						Name: "controller",
 						Port: ctlAPIPort,
@@ -0,0 +1,302 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+)
+
+const (
+	defaultPostgresPort = "5432"
+
+	configSecretSuffix = "config"
+)
+
+// GetConfigSecretName returns the name of the secret containing the configuration files of the component.
+func GetConfigSecretName(harbor *goharborv1alpha1.Harbor, component string) string {
+	return harbor.NormalizeComponentName(fmt.Sprintf("%s-%s", component, configSecretSuffix))
+}
+
+// Error is returned when the configuration of a component cannot be rendered.
+type Error struct {
+	err error
+}
+
+func (e *Error) Error() string {
+	return e.err.Error()
+}
+
+// Cause implements github.com/pkg/errors causer interface.
+func (e *Error) Cause() error {
+	return e.err
+}
+
+// NewError marks err as a rendering error.
+func NewError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &Error{err: err}
+}
+
+// IsError returns true if err, or one of its causes, is a rendering error.
+func IsError(err error) bool {
+	for err != nil {
+		if _, ok := err.(*Error); ok {
+			return true
+		}
+
+		cause, ok := err.(interface{ Cause() error })
+		if !ok {
+			return false
+		}
+
+		err = cause.Cause()
+	}
+
+	return false
+}
+
+// Configs holds the configuration files of a component, by file name.
+type Configs map[string][]byte
+
+// CheckSum returns a checksum of all configuration files.
+func (c Configs) CheckSum() string {
+	names := make([]string, 0, len(c))
+	for name := range c {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	h := sha256.New()
+
+	for _, name := range names {
+		fmt.Fprintf(h, "%s\n%x\n", name, c[name])
+	}
+
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+var funcs = template.FuncMap{
+	"quote":  strconv.Quote,
+	"indent": indent,
+	"toYaml": toYaml,
+}
+
+// Template renders the template text with data.
+func Template(name, text string, data interface{}) ([]byte, error) {
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, NewError(errors.Wrapf(err, "cannot parse template %s", name))
+	}
+
+	var buffer bytes.Buffer
+
+	err = tmpl.Execute(&buffer, data)
+	if err != nil {
+		return nil, NewError(errors.Wrapf(err, "cannot render %s", name))
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func indent(spaces int, value string) string {
+	prefix := strings.Repeat(" ", spaces)
+
+	lines := strings.Split(strings.TrimSuffix(value, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = prefix + line
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func toYaml(value interface{}) (string, error) {
+	data, err := yaml.Marshal(value)
+
+	return string(data), errors.Wrap(err, "cannot marshal yaml")
+}
+
+// GetSecret returns the secret referenced by a Harbor spec.
+func GetSecret(ctx context.Context, reader client.Reader, namespace, name string) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+
+	err := reader.Get(ctx, client.ObjectKey{
+		Namespace: namespace,
+		Name:      name,
+	}, secret)
+	if err != nil {
+		if client.IgnoreNotFound(err) == nil {
+			return nil, NewError(errors.Wrapf(err, "secret %s", name))
+		}
+
+		return nil, errors.Wrapf(err, "cannot get secret %s", name)
+	}
+
+	return secret, nil
+}
+
+// GetSecretValue returns the value of the key of the secret, or defaultValue if missing.
+func GetSecretValue(secret *corev1.Secret, key string, defaultValue string) string {
+	if value, ok := secret.Data[key]; ok {
+		return string(value)
+	}
+
+	if value, ok := secret.StringData[key]; ok {
+		return value
+	}
+
+	return defaultValue
+}
+
+// Redis describes a connection to a redis server.
+type Redis struct {
+	Addr     string
+	Password string
+	DB       int
+}
+
+// ParseRedisURL parses urls like redis://[:password@]host:port[/db].
+func ParseRedisURL(redisURL string) (*Redis, error) {
+	u, err := url.Parse(redisURL)
+	if err != nil {
+		return nil, NewError(errors.Wrap(err, "invalid redis url"))
+	}
+
+	redis := &Redis{
+		Addr: u.Host,
+	}
+
+	if u.User != nil {
+		redis.Password, _ = u.User.Password()
+	}
+
+	db := strings.TrimPrefix(u.Path, "/")
+	if db != "" {
+		redis.DB, err = strconv.Atoi(db)
+		if err != nil {
+			return nil, NewError(errors.Wrapf(err, "invalid redis database %s", db))
+		}
+	}
+
+	return redis, nil
+}
+
+// DatabaseKeys are the keys of a secret describing a postgresql connection.
+type DatabaseKeys struct {
+	Host     string
+	Port     string
+	Name     string
+	User     string
+	Password string
+	SSL      string
+}
+
+// GetPostgresURL returns the url of the postgresql database described by the secret.
+func GetPostgresURL(secret *corev1.Secret, keys DatabaseKeys) string {
+	u := &url.URL{
+		Scheme: "postgresql",
+		User:   url.UserPassword(GetSecretValue(secret, keys.User, ""), GetSecretValue(secret, keys.Password, "")),
+		Host:   net.JoinHostPort(GetSecretValue(secret, keys.Host, ""), GetSecretValue(secret, keys.Port, defaultPostgresPort)),
+		Path:   "/" + GetSecretValue(secret, keys.Name, ""),
+	}
+
+	if sslMode := GetSecretValue(secret, keys.SSL, ""); sslMode != "" {
+		u.RawQuery = url.Values{"sslmode": []string{sslMode}}.Encode()
+	}
+
+	return u.String()
+}
+
+// Override applies configuration overrides to the rendered configuration file named name.
+// The configuration is written back in JSON or YAML, depending on the file extension.
+func Override(ctx context.Context, reader client.Reader, namespace string, overrides *goharborv1alpha1.ConfigOverrides, name string, config []byte) ([]byte, error) {
+	if overrides == nil {
+		return config, nil
+	}
+
+	mergePatch, jsonPatch := overrides.MergePatch, overrides.JSONPatch
+
+	if overrides.ConfigMapRef != nil {
+		configMap := &corev1.ConfigMap{}
+
+		err := reader.Get(ctx, client.ObjectKey{
+			Namespace: namespace,
+			Name:      overrides.ConfigMapRef.Name,
+		}, configMap)
+		if err != nil {
+			if client.IgnoreNotFound(err) == nil {
+				return nil, NewError(errors.Wrapf(err, "configmap %s", overrides.ConfigMapRef.Name))
+			}
+
+			return nil, errors.Wrapf(err, "cannot get configmap %s", overrides.ConfigMapRef.Name)
+		}
+
+		mergePatch = configMap.Data[goharborv1alpha1.ConfigOverridesMergePatchKey]
+		jsonPatch = configMap.Data[goharborv1alpha1.ConfigOverridesJSONPatchKey]
+	}
+
+	if mergePatch == "" && jsonPatch == "" {
+		return config, nil
+	}
+
+	document, err := yaml.YAMLToJSON(config)
+	if err != nil {
+		return nil, NewError(errors.Wrapf(err, "cannot parse %s", name))
+	}
+
+	if mergePatch != "" {
+		patch, err := goharborv1alpha1.ParseMergePatch(mergePatch)
+		if err != nil {
+			return nil, NewError(errors.Wrapf(err, "invalid merge patch for %s", name))
+		}
+
+		document, err = jsonpatch.MergePatch(document, patch)
+		if err != nil {
+			return nil, NewError(errors.Wrapf(err, "cannot merge patch %s", name))
+		}
+	}
+
+	if jsonPatch != "" {
+		patch, err := goharborv1alpha1.ParseJSONPatch(jsonPatch)
+		if err != nil {
+			return nil, NewError(errors.Wrapf(err, "invalid json patch for %s", name))
+		}
+
+		document, err = patch.Apply(document)
+		if err != nil {
+			return nil, NewError(errors.Wrapf(err, "cannot apply json patch to %s", name))
+		}
+	}
+
+	if path.Ext(name) == ".json" {
+		var buffer bytes.Buffer
+
+		err = json.Indent(&buffer, document, "", "  ")
+
+		return buffer.Bytes(), errors.Wrapf(err, "cannot format %s", name)
+	}
+
+	config, err = yaml.JSONToYAML(document)
+
+	return config, errors.Wrapf(err, "cannot format %s", name)
+}
@@ -2,13 +2,17 @@ package components
 
 import (
 	"context"
+	"time"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
 	"github.com/goharbor/harbor-operator/pkg/factories/logger"
+	"github.com/goharbor/harbor-operator/pkg/metrics"
 )
 
 type ComponentRunner struct {
@@ -27,6 +31,7 @@ func (r *Components) ParallelRun(ctx context.Context, harbor *goharborv1alpha1.H
 	g.Go(run.getRunFunc(ctx, harbor, r.ChartMuseum, goharborv1alpha1.ChartMuseumName))
 	g.Go(run.getRunFunc(ctx, harbor, r.Clair, goharborv1alpha1.ClairName))
 	g.Go(run.getRunFunc(ctx, harbor, r.Notary, goharborv1alpha1.NotaryName))
+	g.Go(run.getRunFunc(ctx, harbor, r.Monitoring, goharborv1alpha1.MonitoringName))
 
 	return g.Wait()
 }
@@ -50,22 +55,35 @@ func (r Run) getRunFunc(ctx context.Context, harbor *goharborv1alpha1.Harbor, ru
 	}
 }
 
+// RenderConfigs renders configuration files of all components.
+func (r *Components) RenderConfigs(ctx context.Context, harbor *goharborv1alpha1.Harbor, reader client.Reader) error {
+	return r.ParallelRun(ctx, harbor, func(ctx context.Context, harbor *goharborv1alpha1.Harbor, runner *ComponentRunner) error {
+		return runner.RenderConfigs(ctx, reader)
+	})
+}
+
 type ComponentRun func(context.Context, *goharborv1alpha1.Harbor, []Resource) error
 
 // ParallelRun run a function over all resources of a component.
 // This is a wrapper which use errgroup.
 // The main goal of this method is to centralize action over Resource
 // and not forget any resources anywhere else in the code.
-func (c *ComponentRunner) ParallelRun(ctx context.Context, harbor *goharborv1alpha1.Harbor, servicesRun, configMapsRun, ingressesRun, secretsRun, certificatesRun, deploymentsRun ComponentRun, waitBeforeDeployments bool) error {
+func (c *ComponentRunner) ParallelRun(ctx context.Context, harbor *goharborv1alpha1.Harbor, servicesRun, configMapsRun, ingressesRun, routesRun, secretsRun, certificatesRun, deploymentsRun ComponentRun, waitBeforeDeployments bool) error {
 	if c == nil {
 		return nil
 	}
 
+	routes, err := c.GetRoutes(ctx)
+	if err != nil {
+		return err
+	}
+
 	var g errgroup.Group
 
 	g.Go(servicesRun.getRunFunc(ctx, harbor, c.GetServices(ctx), "services"))
 	g.Go(configMapsRun.getRunFunc(ctx, harbor, c.GetConfigMaps(ctx), "configmaps"))
 	g.Go(ingressesRun.getRunFunc(ctx, harbor, c.GetIngresses(ctx), "ingresses"))
+	g.Go(routesRun.getRunFunc(ctx, harbor, routes, "routes"))
 	g.Go(secretsRun.getRunFunc(ctx, harbor, c.GetSecrets(ctx), "secrets"))
 	g.Go(certificatesRun.getRunFunc(ctx, harbor, c.GetCertificates(ctx), "certificates"))
 
@@ -81,6 +99,24 @@ func (c *ComponentRunner) ParallelRun(ctx context.Context, harbor *goharborv1alp
 	return g.Wait()
 }
 
+// MonitoringParallelRun runs functions over the prometheus-operator resources of a component, if any.
+func (c *ComponentRunner) MonitoringParallelRun(ctx context.Context, harbor *goharborv1alpha1.Harbor, serviceMonitorsRun, prometheusRulesRun ComponentRun) error {
+	if c == nil {
+		return nil
+	}
+
+	if _, ok := c.Component.(MonitoringComponent); !ok {
+		return nil
+	}
+
+	var g errgroup.Group
+
+	g.Go(serviceMonitorsRun.getRunFunc(ctx, harbor, c.GetServiceMonitors(ctx), "servicemonitors"))
+	g.Go(prometheusRulesRun.getRunFunc(ctx, harbor, c.GetPrometheusRules(ctx), "prometheusrules"))
+
+	return g.Wait()
+}
+
 func (c *ComponentRun) getRunFunc(ctx context.Context, harbor *goharborv1alpha1.Harbor, resources []Resource, kind string) func() error {
 	return func() error {
 		if c == nil {
@@ -96,7 +132,16 @@ func (c *ComponentRun) getRunFunc(ctx context.Context, harbor *goharborv1alpha1.
 
 		logger.Set(&ctx, logger.Get(ctx).WithValues("Resource.Kind", kind))
 
-		return errors.Wrap((*c)(ctx, harbor, resources), kind)
+		start := time.Now()
+
+		err := (*c)(ctx, harbor, resources)
+
+		metrics.ObserveComponentRun(types.NamespacedName{
+			Namespace: harbor.GetNamespace(),
+			Name:      harbor.GetName(),
+		}, ComponentName(ctx), kind, time.Since(start), err)
+
+		return errors.Wrap(err, kind)
 	}
 }
 
@@ -133,6 +178,20 @@ func (c *ComponentRunner) GetIngresses(ctx context.Context) []Resource {
 	return resources
 }
 
+func (c *ComponentRunner) GetRoutes(ctx context.Context) ([]Resource, error) {
+	routes, err := c.Component.GetRoutes(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get routes")
+	}
+
+	resources := make([]Resource, len(routes))
+	for i, r := range routes {
+		resources[i] = r
+	}
+
+	return resources, nil
+}
+
 func (c *ComponentRunner) GetSecrets(ctx context.Context) []Resource {
 	secrets := c.Component.GetSecrets(ctx)
 
@@ -165,3 +224,35 @@ func (c *ComponentRunner) GetDeployments(ctx context.Context) []Resource {
 
 	return resources
 }
+
+func (c *ComponentRunner) GetServiceMonitors(ctx context.Context) []Resource {
+	monitoring, ok := c.Component.(MonitoringComponent)
+	if !ok {
+		return nil
+	}
+
+	serviceMonitors := monitoring.GetServiceMonitors(ctx)
+
+	resources := make([]Resource, len(serviceMonitors))
+	for i, r := range serviceMonitors {
+		resources[i] = r
+	}
+
+	return resources
+}
+
+func (c *ComponentRunner) GetPrometheusRules(ctx context.Context) []Resource {
+	monitoring, ok := c.Component.(MonitoringComponent)
+	if !ok {
+		return nil
+	}
+
+	prometheusRules := monitoring.GetPrometheusRules(ctx)
+
+	resources := make([]Resource, len(prometheusRules))
+	for i, r := range prometheusRules {
+		resources[i] = r
+	}
+
+	return resources
+}
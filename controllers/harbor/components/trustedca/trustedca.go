@@ -0,0 +1,95 @@
+package trustedca
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+)
+
+const (
+	// VolumeName is the name of the pod volume containing the trusted CA certificates.
+	VolumeName = "trusted-ca"
+	// Path is the file loaded in the trust store of Harbor images at startup.
+	// https://github.com/goharbor/harbor/blob/v2.0.0/make/photon/common/install_cert.sh
+	Path = "/harbor_cust_cert/trusted_ca.crt"
+	// CertsPath is read by Go programs along with system certificates,
+	// even when the container command bypasses the entrypoint of the image.
+	CertsPath = "/etc/ssl/certs/harbor_trusted_ca.crt"
+)
+
+var varFalse = false
+
+// GetVolumes returns the volume containing the trusted CA certificates.
+func GetVolumes(harbor *goharborv1alpha1.Harbor) []corev1.Volume {
+	ca := harbor.Spec.TrustedCA
+	if ca == nil {
+		return nil
+	}
+
+	if ca.Secret != nil {
+		return []corev1.Volume{
+			{
+				Name: VolumeName,
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{
+						SecretName: ca.Secret.Name,
+						Items: []corev1.KeyToPath{
+							{
+								Key:  ca.Secret.Key,
+								Path: ca.Secret.Key,
+							},
+						},
+						Optional: &varFalse,
+					},
+				},
+			},
+		}
+	}
+
+	return []corev1.Volume{
+		{
+			Name: VolumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: ca.ConfigMap.LocalObjectReference,
+					Items: []corev1.KeyToPath{
+						{
+							Key:  ca.ConfigMap.Key,
+							Path: ca.ConfigMap.Key,
+						},
+					},
+					Optional: &varFalse,
+				},
+			},
+		},
+	}
+}
+
+// GetVolumeMounts mounts the trusted CA certificates where Harbor images load them.
+func GetVolumeMounts(harbor *goharborv1alpha1.Harbor) []corev1.VolumeMount {
+	ca := harbor.Spec.TrustedCA
+	if ca == nil {
+		return nil
+	}
+
+	var key string
+	if ca.Secret != nil {
+		key = ca.Secret.Key
+	} else {
+		key = ca.ConfigMap.Key
+	}
+
+	return []corev1.VolumeMount{
+		{
+			Name:      VolumeName,
+			MountPath: Path,
+			SubPath:   key,
+			ReadOnly:  true,
+		}, {
+			Name:      VolumeName,
+			MountPath: CertsPath,
+			SubPath:   key,
+			ReadOnly:  true,
+		},
+	}
+}
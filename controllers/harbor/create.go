@@ -3,13 +3,17 @@ package harbor
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
-	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 
 	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
 	"github.com/goharbor/harbor-operator/controllers/harbor/components"
@@ -23,6 +27,11 @@ func (r *Reconciler) MutateAnnotations(ctx context.Context, resource metav1.Obje
 	}
 	// Warning annotation
 	annotations[goharborv1alpha1.WarningLabel] = fmt.Sprintf("⚠️ This Resource is managed by *%s* ⚠️", r.GetName())
+
+	if hash := getDesiredStateHash(ctx); hash != "" {
+		annotations[goharborv1alpha1.DesiredStateHashAnnotation] = hash
+	}
+
 	resource.SetAnnotations(annotations)
 }
 
@@ -40,6 +49,8 @@ func (r *Reconciler) MutateLabels(ctx context.Context, resource metav1.Object) {
 	resource.SetLabels(labels)
 }
 
+// CreateResource creates the resource if missing.
+// Existing resources which drifted from the desired state are corrected or reported, depending on the drift policy.
 func (r *Reconciler) CreateResource(ctx context.Context, harbor *goharborv1alpha1.Harbor, resource components.Resource) error {
 	kind, version := resource.
 		GetObjectKind().
@@ -52,29 +63,102 @@ func (r *Reconciler) CreateResource(ctx context.Context, harbor *goharborv1alpha
 	})
 	defer span.Finish()
 
-	r.MutateAnnotations(ctx, resource)
-	r.MutateLabels(ctx, resource)
+	if r.isCachedUpToDate(ctx, harbor, resource) {
+		return nil
+	}
 
-	// Set Harbor instance as the owner and controller of the resource
-	err := controllerutil.SetControllerReference(harbor, resource, r.Scheme)
+	desired, live, err := r.PrepareResource(ctx, harbor, resource)
 	if err != nil {
-		return errors.Wrap(err, "cannot set controller reference")
+		return errors.Wrapf(err, "cannot prepare %s/%s", resource.GroupVersionKind().GroupKind(), resource.GetName())
 	}
 
-	err = r.Client.Create(ctx, resource)
-	if err != nil {
-		if apierrs.IsAlreadyExists(err) {
+	var fields []string
+
+	if live != nil {
+		fields = GetDriftedFields(desired.Object, live.Object)
+		if len(fields) == 0 {
+			return nil
+		}
+
+		drift := Drift{
+			Kind:   desired.GetKind(),
+			Name:   desired.GetName(),
+			Fields: fields,
+		}
+
+		if r.Config.DriftPolicy == ReportDriftPolicy {
+			addDrift(ctx, drift)
+
 			return nil
 		}
 
+		logger.Get(ctx).Info("correcting drift", "fields", fields)
+	}
+
+	err = r.ServerSideApply(ctx, desired)
+	if err != nil {
+		r.ResourceErrorEvent(ctx, harbor, resource, err)
+
 		return errors.Wrapf(err, "cannot create/update %s/%s", resource.GroupVersionKind().GroupKind(), resource.GetName())
 	}
 
+	if live != nil {
+		r.ResourceEvent(ctx, harbor, resource, corev1.EventTypeWarning, DriftCorrectedReason, fmt.Sprintf("changes of %s reverted", strings.Join(fields, ", ")))
+
+		return nil
+	}
+
 	logger.Get(ctx).Info("resource created")
+	r.ResourceEvent(ctx, harbor, resource, corev1.EventTypeNormal, CreatedReason, "created")
 
 	return nil
 }
 
+// isCachedUpToDate returns true if the cached resource has the desired state hash of its component and no drift.
+// Resources are then left untouched without querying the API server.
+func (r *Reconciler) isCachedUpToDate(ctx context.Context, harbor *goharborv1alpha1.Harbor, resource components.Resource) bool {
+	hash := getDesiredStateHash(ctx)
+	if hash == "" {
+		return false
+	}
+
+	gvk, err := apiutil.GVKForObject(resource, r.Scheme)
+	if err != nil {
+		return false
+	}
+
+	// Only typed resources are cached
+	cached, err := r.Scheme.New(gvk)
+	if err != nil {
+		return false
+	}
+
+	err = r.Client.Get(ctx, client.ObjectKey{
+		Namespace: resource.GetNamespace(),
+		Name:      resource.GetName(),
+	}, cached)
+	if err != nil {
+		return false
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cached)
+	if err != nil {
+		return false
+	}
+
+	live := &unstructured.Unstructured{Object: content}
+	if live.GetAnnotations()[goharborv1alpha1.DesiredStateHashAnnotation] != hash {
+		return false
+	}
+
+	desired, err := r.getDesiredState(ctx, harbor, gvk, resource.DeepCopyObject().(components.Resource), live)
+	if err != nil {
+		return false
+	}
+
+	return len(GetDriftedFields(desired.Object, live.Object)) == 0
+}
+
 func (r *Reconciler) CreateResources(ctx context.Context, harbor *goharborv1alpha1.Harbor, resources []components.Resource) error {
 	var g errgroup.Group
 
@@ -95,21 +179,32 @@ func (r *Reconciler) CreateResources(ctx context.Context, harbor *goharborv1alph
 // +kubebuilder:rbac:groups="apps",resources="deployments",verbs=create
 // +kubebuilder:rbac:groups="cert-manager.io",resources="certificates",verbs=create
 // +kubebuilder:rbac:groups="networking.k8s.io",resources="ingresses",verbs=create
+// +kubebuilder:rbac:groups="route.openshift.io",resources="routes",verbs=create
 
 func (r *Reconciler) CreateComponent(ctx context.Context, harbor *goharborv1alpha1.Harbor, component *components.ComponentRunner) error {
-	return component.ParallelRun(ctx, harbor, r.CreateResources, r.CreateResources, r.CreateResources, r.CreateResources, r.CreateResources, r.CreateResources, true)
-}
+	if isComponentPaused(ctx, harbor) {
+		return nil
+	}
 
-func (r *Reconciler) Create(ctx context.Context, harbor *goharborv1alpha1.Harbor) error {
-	span, ctx := opentracing.StartSpanFromContext(ctx, "apply")
-	defer span.Finish()
+	deployment := r.WithTrustedCAChecksum(r.WithInternalTLSChecksum(func(ctx context.Context, harbor *goharborv1alpha1.Harbor, resources []components.Resource) error {
+		err := r.CreateResources(ctx, harbor, resources)
+		if err != nil {
+			return err
+		}
+
+		// Certificates may have been renewed, or the trusted CA changed, since the last apply
+		err = r.RolloutRenewedCertificates(ctx, harbor, resources)
+		if err != nil {
+			return err
+		}
+
+		return r.RolloutChangedTrustedCA(ctx, harbor, resources)
+	}))
 
-	harborResource, err := components.GetComponents(ctx, harbor)
+	err := component.ParallelRun(ctx, harbor, r.CreateResources, r.CreateResources, r.CreateResources, r.WithRoutesTLS(r.CreateResources), r.CreateResources, r.CreateResources, deployment, true)
 	if err != nil {
-		return errors.Wrap(err, "cannot get resources to manage")
+		return err
 	}
 
-	err = harborResource.ParallelRun(ctx, harbor, r.CreateComponent)
-
-	return errors.Wrap(err, "cannot deploy component")
+	return r.CreateMonitoring(ctx, harbor, component)
 }
@@ -2,125 +2,220 @@ package harbor
 
 import (
 	"context"
-	"fmt"
+	"sync"
 
 	certv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha2"
+	routev1 "github.com/openshift/api/route/v1"
 	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1beta1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 
 	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/monitoring"
 	"github.com/goharbor/harbor-operator/pkg/factories/logger"
 )
 
+const (
+	// PruneListLimit is the number of resources listed per page while pruning.
+	PruneListLimit = 100
+)
+
 var (
-	gvkToDelete = []schema.GroupVersionKind{
-		{
-			Group:   corev1.SchemeGroupVersion.Group,
-			Version: corev1.SchemeGroupVersion.Version,
-			Kind:    "Service",
-		}, {
-			Group:   corev1.SchemeGroupVersion.Group,
-			Version: corev1.SchemeGroupVersion.Version,
-			Kind:    "ConfigMap",
-		}, {
-			Group:   netv1.SchemeGroupVersion.Group,
-			Version: netv1.SchemeGroupVersion.Version,
-			Kind:    "Ingress",
-		}, {
-			Group:   corev1.SchemeGroupVersion.Group,
-			Version: corev1.SchemeGroupVersion.Version,
-			Kind:    "Secret",
-		}, {
-			Group:   certv1.SchemeGroupVersion.Group,
-			Version: certv1.SchemeGroupVersion.Version,
-			Kind:    "Certificate",
-		}, {
-			Group:   appsv1.SchemeGroupVersion.Group,
-			Version: appsv1.SchemeGroupVersion.Version,
-			Kind:    "Deployment",
-		},
+	coreGVKs = []schema.GroupVersionKind{
+		corev1.SchemeGroupVersion.WithKind("Service"),
+		corev1.SchemeGroupVersion.WithKind("ConfigMap"),
+		netv1.SchemeGroupVersion.WithKind("Ingress"),
+		corev1.SchemeGroupVersion.WithKind("Secret"),
+		certv1.SchemeGroupVersion.WithKind("Certificate"),
+		appsv1.SchemeGroupVersion.WithKind("Deployment"),
 	}
+
+	routeGVK = routev1.SchemeGroupVersion.WithKind("Route")
 )
 
-// +kubebuilder:rbac:groups="",resources="configmaps",verbs=delete
-// +kubebuilder:rbac:groups="",resources="secrets",verbs=delete
-// +kubebuilder:rbac:groups="",resources="services",verbs=delete
-// +kubebuilder:rbac:groups="apps",resources="deployments",verbs=delete
-// +kubebuilder:rbac:groups="cert-manager.io",resources="certificates",verbs=delete
-// +kubebuilder:rbac:groups="networking.k8s.io",resources="ingresses",verbs=delete
+// ResourceKey identifies a resource of the namespace of a Harbor.
+type ResourceKey struct {
+	schema.GroupKind
+	Name string
+}
 
-func (r *Reconciler) DeleteResourceCollection(ctx context.Context, harbor *goharborv1alpha1.Harbor, componentName string, gvk schema.GroupVersionKind) error {
-	u := &unstructured.UnstructuredList{}
-	u.SetGroupVersionKind(gvk)
+// GetOwnedGVKs returns the kinds of resources created by components, whose API is available.
+func (r *Reconciler) GetOwnedGVKs() []schema.GroupVersionKind {
+	gvks := append([]schema.GroupVersionKind{}, coreGVKs...)
 
-	matchingLabel := client.MatchingLabels{
-		goharborv1alpha1.ComponentNameLabel: componentName,
+	if r.routeAPIAvailable {
+		gvks = append(gvks, routeGVK)
 	}
-	inNamespace := client.InNamespace(harbor.GetNamespace())
 
-	// TODO Use r.Client.DeleteAllOf function
+	if r.serviceMonitorAPIAvailable {
+		gvks = append(gvks, monitoring.ServiceMonitorGVK)
+	}
 
-	limit := 5
+	if r.prometheusRuleAPIAvailable {
+		gvks = append(gvks, monitoring.PrometheusRuleGVK)
+	}
 
-	err := r.Client.List(ctx, u, inNamespace, matchingLabel, client.Limit(limit))
+	return gvks
+}
 
-	if apierrors.IsNotFound(err) {
-		logger.Get(ctx).Info("Cannot list resource to delete, endpoint not found", "GVK.Group", gvk.Group, "GVK.Version", gvk.Version, "GVK.Kind", gvk.Kind)
-		return nil
-	}
+// GetDesiredResources returns the keys of all resources of the components.
+// Configurations must have been rendered.
+func (r *Reconciler) GetDesiredResources(ctx context.Context, harbor *goharborv1alpha1.Harbor, harborResources *components.Components) (map[ResourceKey]bool, error) {
+	var lock sync.Mutex
 
-	countToDelete := len(u.Items)
-	if countToDelete == 0 {
-		return nil
-	}
+	desired := map[ResourceKey]bool{}
 
-	count := 0
-	err = u.EachListItem(func(object runtime.Object) error {
-		err := r.Client.Delete(ctx, object)
-		err = client.IgnoreNotFound(err)
-		if err == nil {
-			count++
+	err := harborResources.ParallelRun(ctx, harbor, func(ctx context.Context, harbor *goharborv1alpha1.Harbor, component *components.ComponentRunner) error {
+		resources, err := getComponentResources(ctx, component)
+		if err != nil {
+			return err
+		}
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		for _, resource := range resources {
+			gvk, err := apiutil.GVKForObject(resource, r.Scheme)
+			if err != nil {
+				return errors.Wrapf(err, "cannot get kind of %s", resource.GetName())
+			}
+
+			desired[ResourceKey{GroupKind: gvk.GroupKind(), Name: resource.GetName()}] = true
 		}
-		return err
+
+		return nil
 	})
 
-	logger.Get(ctx).Info(fmt.Sprintf("%d/%d resources deleted", count, countToDelete), "GVK.Group", gvk.Group, "GVK.Version", gvk.Version, "GVK.Kind", gvk.Kind)
+	return desired, err
+}
 
+// +kubebuilder:rbac:groups="",resources="configmaps",verbs=list;delete
+// +kubebuilder:rbac:groups="",resources="secrets",verbs=list;delete
+// +kubebuilder:rbac:groups="",resources="services",verbs=list;delete
+// +kubebuilder:rbac:groups="apps",resources="deployments",verbs=list;delete
+// +kubebuilder:rbac:groups="cert-manager.io",resources="certificates",verbs=list;delete
+// +kubebuilder:rbac:groups="networking.k8s.io",resources="ingresses",verbs=list;delete
+// +kubebuilder:rbac:groups="route.openshift.io",resources="routes",verbs=list;delete
+
+// Prune deletes the resources created for the harbor which are no longer desired,
+// such as resources of removed components, of the unused exposure type or of disabled metrics.
+// Resources of paused components are kept.
+func (r *Reconciler) Prune(ctx context.Context, harbor *goharborv1alpha1.Harbor, harborResources *components.Components) error {
+	desired, err := r.GetDesiredResources(ctx, harbor, harborResources)
 	if err != nil {
-		return errors.Wrap(err, "cannot delete object")
+		return errors.Wrap(err, "cannot get desired resources")
 	}
 
-	if limit == countToDelete {
-		return errors.New("some resource to delete may remain")
+	var g errgroup.Group
+
+	for _, gvk := range r.GetOwnedGVKs() {
+		gvk := gvk
+
+		g.Go(func() error {
+			err := r.PruneKind(ctx, harbor, gvk, desired)
+			return errors.Wrapf(err, "cannot prune %s", gvk.Kind)
+		})
 	}
 
-	return nil
+	return g.Wait()
 }
 
-func (r *Reconciler) DeleteComponent(ctx context.Context, harbor *goharborv1alpha1.Harbor, componentName string) error {
-	var g errgroup.Group
+// PruneKind deletes the resources of the kind created for the harbor which are not desired.
+// Resources are created for the harbor when they have a component label and are controlled by the harbor.
+func (r *Reconciler) PruneKind(ctx context.Context, harbor *goharborv1alpha1.Harbor, gvk schema.GroupVersionKind, desired map[ResourceKey]bool) error {
+	hasComponent, err := labels.NewRequirement(goharborv1alpha1.ComponentNameLabel, selection.Exists, nil)
+	if err != nil {
+		return errors.Wrap(err, "invalid selector")
+	}
 
-	l := logger.Get(ctx).WithValues("Component", componentName)
-	logger.Set(&ctx, l)
+	selector := client.MatchingLabelsSelector{Selector: labels.NewSelector().Add(*hasComponent)}
 
-	l.Info("Deleting component")
+	var toDelete []unstructured.Unstructured
 
-	for _, gvk := range gvkToDelete {
-		gvk := gvk
+	continueToken := ""
 
-		g.Go(func() error {
-			err := r.DeleteResourceCollection(ctx, harbor, componentName, gvk)
-			return errors.Wrapf(err, "deletecollection failed for %s", gvk.String())
-		})
+	for {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk)
+
+		err := r.Client.List(ctx, list, client.InNamespace(harbor.GetNamespace()), selector, client.Limit(PruneListLimit), client.Continue(continueToken))
+		if err != nil {
+			if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+				logger.Get(ctx).Info("Cannot list resource to prune, endpoint not found", "GVK.Group", gvk.Group, "GVK.Version", gvk.Version, "GVK.Kind", gvk.Kind)
+				return nil
+			}
+
+			return errors.Wrap(err, "cannot list resources")
+		}
+
+		for _, item := range list.Items {
+			if isPrunable(harbor, &item, desired) { // nolint:scopelint
+				toDelete = append(toDelete, item)
+			}
+		}
+
+		continueToken = list.GetContinue()
+		if continueToken == "" {
+			break
+		}
 	}
 
-	return g.Wait()
+	deleted := map[string]int{}
+
+	for _, item := range toDelete {
+		item := item
+		uid := item.GetUID()
+
+		// The precondition avoids deleting a resource recreated in the meantime
+		err := r.Client.Delete(ctx, &item, client.Preconditions{UID: &uid})
+		if client.IgnoreNotFound(err) != nil && !apierrors.IsConflict(err) {
+			r.recordDeleted(harbor, gvk, deleted)
+
+			return errors.Wrapf(err, "cannot delete %s", item.GetName())
+		}
+
+		if err == nil {
+			logger.Get(ctx).Info("resource pruned", "GVK.Kind", gvk.Kind, "Name", item.GetName())
+			deleted[item.GetLabels()[goharborv1alpha1.ComponentNameLabel]]++
+		}
+	}
+
+	r.recordDeleted(harbor, gvk, deleted)
+
+	return nil
+}
+
+func (r *Reconciler) recordDeleted(harbor *goharborv1alpha1.Harbor, gvk schema.GroupVersionKind, deleted map[string]int) {
+	for componentName, count := range deleted {
+		r.Recorder.Eventf(harbor, corev1.EventTypeNormal, DeletedReason, "%s: %d %s deleted", componentName, count, gvk.Kind)
+	}
+}
+
+// isPrunable returns true if the resource was created for the harbor, is not desired anymore
+// and its component is not paused.
+func isPrunable(harbor *goharborv1alpha1.Harbor, resource *unstructured.Unstructured, desired map[ResourceKey]bool) bool {
+	if !metav1.IsControlledBy(resource, harbor) {
+		return false
+	}
+
+	if harbor.IsComponentPaused(resource.GetLabels()[goharborv1alpha1.ComponentNameLabel]) {
+		return false
+	}
+
+	return !desired[ResourceKey{
+		GroupKind: resource.GroupVersionKind().GroupKind(),
+		Name:      resource.GetName(),
+	}]
 }
@@ -0,0 +1,107 @@
+package harbor
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/monitoring"
+)
+
+var _ = Describe("Prune", func() {
+	var harbor *goharborv1alpha1.Harbor
+
+	deploymentGK := schema.GroupKind{Group: "apps", Kind: "Deployment"}
+
+	newResource := func(name, component string, owner *goharborv1alpha1.Harbor) *unstructured.Unstructured {
+		resource := &unstructured.Unstructured{}
+		resource.SetGroupVersionKind(deploymentGK.WithVersion("v1"))
+		resource.SetName(name)
+		resource.SetLabels(map[string]string{
+			goharborv1alpha1.ComponentNameLabel: component,
+		})
+
+		controller := true
+		resource.SetOwnerReferences([]metav1.OwnerReference{{
+			APIVersion: goharborv1alpha1.GroupVersion.String(),
+			Kind:       "Harbor",
+			Name:       owner.GetName(),
+			UID:        owner.GetUID(),
+			Controller: &controller,
+		}})
+
+		return resource
+	}
+
+	BeforeEach(func() {
+		harbor = &goharborv1alpha1.Harbor{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "my-harbor",
+				UID:  types.UID("3b8e4c2a"),
+			},
+		}
+	})
+
+	Context("isPrunable", func() {
+		It("Should prune resources no longer desired", func() {
+			desired := map[ResourceKey]bool{
+				{GroupKind: deploymentGK, Name: "my-harbor-core"}: true,
+			}
+
+			Expect(isPrunable(harbor, newResource("my-harbor-core", goharborv1alpha1.CoreName, harbor), desired)).To(BeFalse())
+			Expect(isPrunable(harbor, newResource("my-harbor-chartmuseum", goharborv1alpha1.ChartMuseumName, harbor), desired)).To(BeTrue())
+		})
+
+		It("Should keep resources of other harbors", func() {
+			other := harbor.DeepCopy()
+			other.SetName("other-harbor")
+			other.SetUID(types.UID("9f1d0b7e"))
+
+			Expect(isPrunable(harbor, newResource("other-harbor-chartmuseum", goharborv1alpha1.ChartMuseumName, other), nil)).To(BeFalse())
+		})
+
+		It("Should keep resources without controller", func() {
+			resource := newResource("my-harbor-chartmuseum", goharborv1alpha1.ChartMuseumName, harbor)
+			resource.SetOwnerReferences(nil)
+
+			Expect(isPrunable(harbor, resource, nil)).To(BeFalse())
+		})
+
+		It("Should keep resources of paused components", func() {
+			harbor.Spec.PausedComponents = []goharborv1alpha1.PausableComponent{goharborv1alpha1.ChartMuseumName}
+
+			Expect(isPrunable(harbor, newResource("my-harbor-chartmuseum", goharborv1alpha1.ChartMuseumName, harbor), nil)).To(BeFalse())
+		})
+
+		It("Should compare kinds", func() {
+			desired := map[ResourceKey]bool{
+				{GroupKind: schema.GroupKind{Kind: "Service"}, Name: "my-harbor-core"}: true,
+			}
+
+			Expect(isPrunable(harbor, newResource("my-harbor-core", goharborv1alpha1.CoreName, harbor), desired)).To(BeTrue())
+		})
+	})
+
+	Context("GetOwnedGVKs", func() {
+		It("Should only return available APIs", func() {
+			r, _ := setupTest(context.TODO())
+
+			Expect(r.GetOwnedGVKs()).ToNot(ContainElement(routeGVK))
+			Expect(r.GetOwnedGVKs()).ToNot(ContainElement(monitoring.ServiceMonitorGVK))
+
+			r.routeAPIAvailable = true
+			r.serviceMonitorAPIAvailable = true
+
+			Expect(r.GetOwnedGVKs()).To(ContainElement(routeGVK))
+			Expect(r.GetOwnedGVKs()).To(ContainElement(monitoring.ServiceMonitorGVK))
+			Expect(r.GetOwnedGVKs()).ToNot(ContainElement(monitoring.PrometheusRuleGVK))
+		})
+	})
+})
@@ -0,0 +1,267 @@
+package harbor
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/pkg/factories/logger"
+)
+
+// DriftPolicy defines what is done when owned resources were changed outside of the operator.
+type DriftPolicy string
+
+const (
+	// CorrectDriftPolicy applies the desired state again.
+	CorrectDriftPolicy DriftPolicy = "correct"
+	// ReportDriftPolicy keeps the changes and reports them in the Drifted condition.
+	ReportDriftPolicy DriftPolicy = "report"
+)
+
+const (
+	DefaultDriftPolicy = CorrectDriftPolicy
+)
+
+// Reasons of the Drifted condition.
+const (
+	ManualChangesReason = "ManualChanges"
+	NoDriftReason       = "NoDrift"
+)
+
+// driftIgnoredFields are not compared to detect drifts.
+// Their values are checksums of other resources, rolled out by dedicated mechanisms when they change,
+// or change with the operator itself.
+// Certificates of routes are compared: their checksum is part of the desired state hash, so renewed
+// certificates are applied with the component instead of being detected as drifts.
+var driftIgnoredFields = []string{
+	fmt.Sprintf("metadata.labels[%s]", goharborv1alpha1.OperatorVersionLabel),
+	fmt.Sprintf("spec.template.metadata.annotations[%s]", InternalTLSChecksumAnnotation),
+	fmt.Sprintf("spec.template.metadata.annotations[%s]", TrustedCAChecksumAnnotation),
+}
+
+// Drift describes the fields of a resource which differ from the desired state.
+type Drift struct {
+	Kind   string
+	Name   string
+	Fields []string
+}
+
+func (d Drift) String() string {
+	return fmt.Sprintf("%s %s: %s", d.Kind, d.Name, strings.Join(d.Fields, ", "))
+}
+
+// GetDriftMessage returns the message of the Drifted condition.
+func GetDriftMessage(drifts []Drift) string {
+	messages := make([]string, len(drifts))
+	for i, drift := range drifts {
+		messages[i] = drift.String()
+	}
+
+	sort.Strings(messages)
+
+	return strings.Join(messages, "; ")
+}
+
+var driftContext = "drifts"
+
+type driftCollector struct {
+	lock   sync.Mutex
+	drifts []Drift
+}
+
+// withDriftCollector returns a context collecting the drifts reported by resources.
+func withDriftCollector(ctx context.Context) (context.Context, *driftCollector) {
+	collector := &driftCollector{}
+
+	return context.WithValue(ctx, &driftContext, collector), collector
+}
+
+func addDrift(ctx context.Context, drift Drift) {
+	collector, ok := ctx.Value(&driftContext).(*driftCollector)
+	if !ok {
+		return
+	}
+
+	collector.lock.Lock()
+	defer collector.lock.Unlock()
+
+	collector.drifts = append(collector.drifts, drift)
+}
+
+func (c *driftCollector) get() []Drift {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return append([]Drift(nil), c.drifts...)
+}
+
+// GetDriftedFields returns the paths of the fields of desired whose value differs in live.
+// Fields only set in live, such as defaults set by the API server or fields managed
+// by other controllers, are not drifts.
+func GetDriftedFields(desired, live map[string]interface{}) []string {
+	fields := getDriftedFields("", desired, live)
+
+	result := make([]string, 0, len(fields))
+
+	for _, field := range fields {
+		if !isDriftIgnoredField(field) {
+			result = append(result, field)
+		}
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+func getDriftedFields(path string, desired, live interface{}) []string {
+	switch desired := desired.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		liveMap, ok := live.(map[string]interface{})
+		if !ok && live != nil {
+			return []string{path}
+		}
+
+		var fields []string
+
+		// Missing maps are compared field by field, to report each missing field
+		for key, value := range desired {
+			fields = append(fields, getDriftedFields(childPath(path, key), value, liveMap[key])...)
+		}
+
+		return fields
+	case []interface{}:
+		liveList, ok := live.([]interface{})
+		if !ok {
+			if len(desired) == 0 && live == nil {
+				return nil
+			}
+
+			return []string{path}
+		}
+
+		if len(liveList) != len(desired) {
+			return []string{path}
+		}
+
+		var fields []string
+
+		for i, value := range desired {
+			fields = append(fields, getDriftedFields(fmt.Sprintf("%s[%d]", path, i), value, liveList[i])...)
+		}
+
+		return fields
+	default:
+		if isSameValue(desired, live) {
+			return nil
+		}
+
+		return []string{path}
+	}
+}
+
+func childPath(path, key string) string {
+	if strings.ContainsAny(key, "./") {
+		return fmt.Sprintf("%s[%s]", path, key)
+	}
+
+	if path == "" {
+		return key
+	}
+
+	return fmt.Sprintf("%s.%s", path, key)
+}
+
+func isSameValue(desired, live interface{}) bool {
+	if reflect.DeepEqual(desired, live) {
+		return true
+	}
+
+	if d, ok := toFloat(desired); ok {
+		l, ok := toFloat(live)
+		return ok && d == l
+	}
+
+	// Quantities are normalized by the API server
+	d, ok := desired.(string)
+	if !ok {
+		return false
+	}
+
+	l, ok := live.(string)
+	if !ok {
+		return false
+	}
+
+	dq, err := resource.ParseQuantity(d)
+	if err != nil {
+		return false
+	}
+
+	lq, err := resource.ParseQuantity(l)
+	if err != nil {
+		return false
+	}
+
+	return dq.Cmp(lq) == 0
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch value := value.(type) {
+	case int64:
+		return float64(value), true
+	case int32:
+		return float64(value), true
+	case int:
+		return float64(value), true
+	case float64:
+		return value, true
+	}
+
+	return 0, false
+}
+
+func isDriftIgnoredField(field string) bool {
+	for _, ignored := range driftIgnoredFields {
+		if field == ignored || strings.HasPrefix(field, ignored+".") || strings.HasPrefix(field, ignored+"[") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// UpdateDriftedStatus reports the drifts in the Drifted condition, when drifts are not corrected.
+func (r *Reconciler) UpdateDriftedStatus(ctx context.Context, harbor *goharborv1alpha1.Harbor, drifts []Drift) error {
+	if r.Config.DriftPolicy != ReportDriftPolicy {
+		r.RemoveCondition(ctx, harbor, goharborv1alpha1.DriftedConditionType)
+
+		return nil
+	}
+
+	if len(drifts) == 0 {
+		return r.UpdateCondition(ctx, harbor, goharborv1alpha1.DriftedConditionType, corev1.ConditionFalse, NoDriftReason, "")
+	}
+
+	message := GetDriftMessage(drifts)
+
+	logger.Get(ctx).Info("drift detected", "drifts", message)
+
+	if r.GetConditionStatus(ctx, harbor, goharborv1alpha1.DriftedConditionType) != corev1.ConditionTrue {
+		r.Recorder.Event(harbor, corev1.EventTypeWarning, DriftDetectedReason, message)
+	}
+
+	err := r.UpdateCondition(ctx, harbor, goharborv1alpha1.DriftedConditionType, corev1.ConditionTrue, ManualChangesReason, message)
+
+	return errors.Wrapf(err, "value=%s", corev1.ConditionTrue)
+}
@@ -0,0 +1,254 @@
+package harbor
+
+import (
+	"context"
+	"encoding/base64"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+)
+
+var _ = Describe("Drift", func() {
+	var desired map[string]interface{}
+
+	BeforeEach(func() {
+		desired = map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"labels": map[string]interface{}{
+					goharborv1alpha1.OperatorVersionLabel: "devel",
+				},
+			},
+			"spec": map[string]interface{}{
+				"replicas": int64(1),
+				"template": map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"annotations": map[string]interface{}{
+							InternalTLSChecksumAnnotation: "abc",
+						},
+					},
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{
+								"name":  "core",
+								"image": "goharbor/harbor-core:v1.10.0",
+								"resources": map[string]interface{}{
+									"limits": map[string]interface{}{"cpu": "0.5"},
+								},
+							},
+						},
+						"securityContext": map[string]interface{}{},
+					},
+				},
+			},
+		}
+	})
+
+	live := func() map[string]interface{} {
+		return map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"resourceVersion": "12",
+				"labels": map[string]interface{}{
+					goharborv1alpha1.OperatorVersionLabel: "devel",
+				},
+			},
+			"spec": map[string]interface{}{
+				"replicas":             int64(1),
+				"revisionHistoryLimit": int64(10),
+				"template": map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"annotations": map[string]interface{}{
+							InternalTLSChecksumAnnotation: "abc",
+						},
+					},
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{
+								"name":            "core",
+								"image":           "goharbor/harbor-core:v1.10.0",
+								"imagePullPolicy": "IfNotPresent",
+								"resources": map[string]interface{}{
+									"limits": map[string]interface{}{"cpu": "500m"},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	Context("GetDriftedFields", func() {
+		It("Should ignore defaults and normalized values", func() {
+			Expect(GetDriftedFields(desired, live())).To(BeEmpty())
+		})
+
+		It("Should report changed fields", func() {
+			l := live()
+			l["spec"].(map[string]interface{})["replicas"] = int64(3)
+			container := l["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})["containers"].([]interface{})[0].(map[string]interface{})
+			container["image"] = "goharbor/harbor-core:dev"
+
+			Expect(GetDriftedFields(desired, l)).To(Equal([]string{
+				"spec.replicas",
+				"spec.template.spec.containers[0].image",
+			}))
+		})
+
+		It("Should report removed fields and resized lists", func() {
+			l := live()
+			delete(l, "metadata")
+			l["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})["containers"] = []interface{}{}
+
+			// The version label is ignored
+			Expect(GetDriftedFields(desired, l)).To(Equal([]string{
+				"spec.template.spec.containers",
+			}))
+		})
+
+		It("Should ignore fields derived from other resources", func() {
+			l := live()
+			l["spec"].(map[string]interface{})["template"].(map[string]interface{})["metadata"] = map[string]interface{}{
+				"annotations": map[string]interface{}{
+					InternalTLSChecksumAnnotation: "def",
+					TrustedCAChecksumAnnotation:   "ghi",
+				},
+			}
+
+			Expect(GetDriftedFields(desired, l)).To(BeEmpty())
+		})
+
+		It("Should report changed certificates of routes", func() {
+			desired := map[string]interface{}{
+				"spec": map[string]interface{}{
+					"tls": map[string]interface{}{"termination": "edge", "certificate": "a"},
+				},
+			}
+
+			Expect(GetDriftedFields(desired, map[string]interface{}{
+				"spec": map[string]interface{}{
+					"tls": map[string]interface{}{"termination": "edge", "certificate": "b"},
+				},
+			})).To(Equal([]string{"spec.tls.certificate"}))
+		})
+
+		It("Should name keys containing dots", func() {
+			desired := map[string]interface{}{
+				"data": map[string]interface{}{"config.yaml": "a"},
+			}
+
+			Expect(GetDriftedFields(desired, map[string]interface{}{
+				"data": map[string]interface{}{"config.yaml": "b"},
+			})).To(Equal([]string{"data[config.yaml]"}))
+		})
+	})
+
+	Context("Secrets", func() {
+		It("Should keep generated values", func() {
+			secret := &corev1.Secret{
+				Data: map[string][]byte{
+					"config.yaml": []byte("new"),
+				},
+				StringData: map[string]string{
+					"secret":   "generated",
+					"password": "generated",
+				},
+			}
+
+			live := &unstructured.Unstructured{Object: map[string]interface{}{
+				"data": map[string]interface{}{
+					"config.yaml": base64.StdEncoding.EncodeToString([]byte("old")),
+					"secret":      base64.StdEncoding.EncodeToString([]byte("existing")),
+				},
+			}}
+
+			Expect(keepGeneratedValues(secret, live)).To(Succeed())
+			Expect(secret.StringData).To(BeNil())
+			Expect(secret.Data).To(Equal(map[string][]byte{
+				"config.yaml": []byte("new"),
+				"secret":      []byte("existing"),
+				"password":    []byte("generated"),
+			}))
+		})
+
+		It("Should use generated values for new secrets", func() {
+			secret := &corev1.Secret{
+				StringData: map[string]string{
+					"secret": "generated",
+				},
+			}
+
+			Expect(keepGeneratedValues(secret, nil)).To(Succeed())
+			Expect(secret.Data).To(Equal(map[string][]byte{
+				"secret": []byte("generated"),
+			}))
+		})
+	})
+
+	It("Should prune null fields", func() {
+		Expect(pruneNullFields(map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name":              "core",
+				"creationTimestamp": nil,
+			},
+			"containers": []interface{}{
+				map[string]interface{}{"resources": nil},
+			},
+		})).To(Equal(map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name": "core",
+			},
+			"containers": []interface{}{
+				map[string]interface{}{},
+			},
+		}))
+	})
+
+	Context("UpdateDriftedStatus", func() {
+		var r *Reconciler
+		var ctx context.Context
+		var harbor *goharborv1alpha1.Harbor
+
+		drifts := []Drift{{
+			Kind:   "Deployment",
+			Name:   "my-harbor-core",
+			Fields: []string{"spec.replicas", "spec.template.spec.containers[0].image"},
+		}}
+
+		BeforeEach(func() {
+			r, ctx = setupTest(context.TODO())
+			harbor = &goharborv1alpha1.Harbor{}
+		})
+
+		It("Should not maintain the condition when drifts are corrected", func() {
+			r.Config.DriftPolicy = CorrectDriftPolicy
+
+			Expect(r.UpdateDriftedStatus(ctx, harbor, drifts)).To(Succeed())
+			Expect(harbor.Status.Conditions).To(BeEmpty())
+		})
+
+		It("Should report drifts", func() {
+			r.Config.DriftPolicy = ReportDriftPolicy
+
+			Expect(r.UpdateDriftedStatus(ctx, harbor, drifts)).To(Succeed())
+			Expect(r.UpdateDriftedStatus(ctx, harbor, drifts)).To(Succeed())
+
+			condition := r.GetCondition(ctx, harbor, goharborv1alpha1.DriftedConditionType)
+			Expect(condition.Status).To(Equal(corev1.ConditionTrue))
+			Expect(condition.Reason).To(Equal(ManualChangesReason))
+			Expect(condition.Message).To(Equal("Deployment my-harbor-core: spec.replicas, spec.template.spec.containers[0].image"))
+
+			events := r.Recorder.(*record.FakeRecorder).Events
+			Expect(events).To(HaveLen(1))
+			Expect(<-events).To(HavePrefix("Warning DriftDetected"))
+
+			Expect(r.UpdateDriftedStatus(ctx, harbor, nil)).To(Succeed())
+			Expect(r.GetConditionStatus(ctx, harbor, goharborv1alpha1.DriftedConditionType)).To(Equal(corev1.ConditionFalse))
+		})
+	})
+})
@@ -1,8 +1,16 @@
 package harbor
 
 import (
+	"context"
+
+	certv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha2"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 
@@ -13,36 +21,125 @@ import (
 type EventFilter struct {
 	ClassName string
 	Scheme    *runtime.Scheme
+	// Classes reads the HarborClasses deciding which operator reconciles their Harbors, nil when disabled.
+	Classes client.Reader
+	// Namespaces are the watched namespaces, every namespace when empty.
+	Namespaces []string
+	// Selector selects Harbors by their labels, every Harbor when nil.
+	Selector labels.Selector
 }
 
 // Create returns true if the Create event should be processed
 func (ef *EventFilter) Create(e event.CreateEvent) bool {
+	if IsHarborClass(e.Object) {
+		return true
+	}
+
+	if !ef.IsSelected(e.Meta, e.Object) {
+		return false
+	}
+
 	return ef.HarborClassAnnotationMatch(e.Meta) || ef.IsOwned(e.Meta, e.Object)
 }
 
 // Delete returns true if the Delete event should be processed
 func (ef *EventFilter) Delete(e event.DeleteEvent) bool {
+	if IsHarborClass(e.Object) {
+		return true
+	}
+
+	if !ef.IsSelected(e.Meta, e.Object) {
+		return false
+	}
+
 	return ef.HarborClassAnnotationMatch(e.Meta) || ef.IsOwned(e.Meta, e.Object)
 }
 
 // Update returns true if the Update event should be processed
+// Updates of the status of resources owned by a Harbor are ignored, except issuance of certificates.
 func (ef *EventFilter) Update(e event.UpdateEvent) bool {
+	if IsHarborClass(e.ObjectNew) {
+		return true
+	}
+
+	if IsControlledByHarbor(e.MetaNew) && IsStatusOnlyUpdate(e.ObjectOld, e.ObjectNew) && !IsCertificateTransition(e.ObjectOld, e.ObjectNew) {
+		return false
+	}
+
+	if !ef.IsSelected(e.MetaOld, e.ObjectOld) && !ef.IsSelected(e.MetaNew, e.ObjectNew) {
+		return false
+	}
+
 	return (ef.HarborClassAnnotationMatch(e.MetaOld) || ef.IsOwned(e.MetaOld, e.ObjectOld)) ||
 		(ef.HarborClassAnnotationMatch(e.MetaNew) || ef.IsOwned(e.MetaNew, e.ObjectNew))
 }
 
 // Generic returns true if the Generic event should be processed
 func (ef *EventFilter) Generic(e event.GenericEvent) bool {
+	if IsHarborClass(e.Object) {
+		return true
+	}
+
+	if !ef.IsSelected(e.Meta, e.Object) {
+		return false
+	}
+
 	return ef.HarborClassAnnotationMatch(e.Meta) || ef.IsOwned(e.Meta, e.Object)
 }
 
+// IsSelected returns true if the resource is in a watched namespace and, for Harbors, matches the label selector.
+func (ef *EventFilter) IsSelected(meta metav1.Object, ro runtime.Object) bool {
+	if len(ef.Namespaces) > 0 {
+		found := false
+
+		for _, namespace := range ef.Namespaces {
+			if meta.GetNamespace() == namespace {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	if _, ok := ro.(*goharborv1alpha1.Harbor); !ok || ef.Selector == nil {
+		return true
+	}
+
+	return ef.Selector.Matches(labels.Set(meta.GetLabels()))
+}
+
+// IsManaged returns true if the Harbor is selected and of the class of the operator.
+func (ef *EventFilter) IsManaged(harbor *goharborv1alpha1.Harbor) bool {
+	return ef.IsSelected(harbor, harbor) && ef.HarborClassAnnotationMatch(harbor)
+}
+
+// HarborClassAnnotationMatch returns true if the class of the resource is the class of the operator.
+// When the class is a HarborClass with a controller, the controller must be the class of the operator instead.
 func (ef *EventFilter) HarborClassAnnotationMatch(meta metav1.Object) bool {
 	annotations := meta.GetAnnotations()
 	value, ok := annotations[goharborv1alpha1.HarborClassAnnotation]
 
+	if value != "" && ef.Classes != nil {
+		class := &goharborv1alpha1.HarborClass{}
+
+		err := ef.Classes.Get(context.TODO(), client.ObjectKey{Name: value}, class)
+		if err == nil && class.Spec.Controller != "" {
+			return class.Spec.Controller == ef.ClassName
+		}
+	}
+
 	return value == ef.ClassName || (!ok && ef.ClassName == "")
 }
 
+// IsHarborClass returns true if the resource is a HarborClass, whose events are mapped to its Harbors.
+func IsHarborClass(ro runtime.Object) bool {
+	_, ok := ro.(*goharborv1alpha1.HarborClass)
+	return ok
+}
+
 func (ef *EventFilter) IsOwned(meta metav1.Object, ro runtime.Object) bool {
 	gvk, err := apiutil.GVKForObject(ro, ef.Scheme)
 	if err != nil {
@@ -59,9 +156,76 @@ func (ef *EventFilter) IsOwned(meta metav1.Object, ro runtime.Object) bool {
 	return false
 }
 
+// IsControlledByHarbor returns true if the controller of the resource is a Harbor.
+func IsControlledByHarbor(meta metav1.Object) bool {
+	owner := metav1.GetControllerOf(meta)
+	if owner == nil {
+		return false
+	}
+
+	gv, err := schema.ParseGroupVersion(owner.APIVersion)
+	if err != nil {
+		return false
+	}
+
+	return gv.Group == goharborv1alpha1.GroupVersion.Group && owner.Kind == "Harbor"
+}
+
+// IsStatusOnlyUpdate returns true if resources only differ by their status or resource version.
+func IsStatusOnlyUpdate(oldObject, newObject runtime.Object) bool {
+	oldContent, err := getComparableContent(oldObject)
+	if err != nil {
+		return false
+	}
+
+	newContent, err := getComparableContent(newObject)
+	if err != nil {
+		return false
+	}
+
+	return equality.Semantic.DeepEqual(oldContent, newContent)
+}
+
+// IsCertificateTransition returns true if the certificate became ready or not ready, or was renewed.
+// Its secret was issued then: routes, deployments and the public certificate status are updated from it.
+func IsCertificateTransition(oldObject, newObject runtime.Object) bool {
+	oldCertificate, ok := oldObject.(*certv1.Certificate)
+	if !ok {
+		return false
+	}
+
+	newCertificate, ok := newObject.(*certv1.Certificate)
+	if !ok {
+		return false
+	}
+
+	if !equality.Semantic.DeepEqual(oldCertificate.Status.NotAfter, newCertificate.Status.NotAfter) {
+		return true
+	}
+
+	return (getCertificateIssue(oldCertificate) == nil) != (getCertificateIssue(newCertificate) == nil)
+}
+
+func getComparableContent(object runtime.Object) (map[string]interface{}, error) {
+	// ToUnstructured does not copy unstructured objects
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object.DeepCopyObject())
+	if err != nil {
+		return nil, err
+	}
+
+	delete(content, "status")
+	unstructured.RemoveNestedField(content, "metadata", "resourceVersion")
+	unstructured.RemoveNestedField(content, "metadata", "managedFields")
+
+	return content, nil
+}
+
 func (r *Reconciler) GetEventFilter() *EventFilter {
 	return &EventFilter{
-		ClassName: r.Config.ClassName,
-		Scheme:    r.Scheme,
+		ClassName:  r.Config.ClassName,
+		Scheme:     r.Scheme,
+		Namespaces: r.Config.Namespaces,
+		Selector:   r.Config.Selector,
+		Classes:    r.GetHarborClassReader(),
 	}
 }
@@ -7,6 +7,11 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	certv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha2"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 
 	// +kubebuilder:scaffold:imports
@@ -1137,3 +1142,155 @@ var _ = Describe("event-filter", func() {
 		})
 	})
 })
+
+var _ = Describe("event-filter of owned resources", func() {
+	var ef *EventFilter
+	var oldResource, newResource *appsv1.Deployment
+
+	BeforeEach(func() {
+		r, _ := setupTest(context.TODO())
+		ef = r.GetEventFilter()
+
+		controller := true
+		oldResource = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "my-harbor-core",
+				ResourceVersion: "1",
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: goharborv1alpha1.GroupVersion.String(),
+					Kind:       "Harbor",
+					Name:       "my-harbor",
+					Controller: &controller,
+				}},
+			},
+		}
+		newResource = oldResource.DeepCopy()
+		newResource.SetResourceVersion("2")
+	})
+
+	update := func() bool {
+		return ef.Update(event.UpdateEvent{MetaOld: oldResource.GetObjectMeta(), ObjectOld: oldResource, MetaNew: newResource.GetObjectMeta(), ObjectNew: newResource})
+	}
+
+	It("Should ignore status updates", func() {
+		newResource.Status.ReadyReplicas = 1
+
+		Expect(update()).To(BeFalse())
+	})
+
+	It("Should ignore resync", func() {
+		Expect(update()).To(BeFalse())
+	})
+
+	It("Should process spec updates", func() {
+		replicas := int32(2)
+		newResource.Spec.Replicas = &replicas
+
+		Expect(update()).To(BeTrue())
+	})
+
+	It("Should process metadata updates", func() {
+		newResource.SetAnnotations(map[string]string{goharborv1alpha1.DesiredStateHashAnnotation: "a"})
+
+		Expect(update()).To(BeTrue())
+	})
+
+	Context("Of certificates", func() {
+		var oldCertificate, newCertificate *certv1.Certificate
+
+		BeforeEach(func() {
+			oldCertificate = &certv1.Certificate{
+				ObjectMeta: oldResource.ObjectMeta,
+				Status: certv1.CertificateStatus{
+					Conditions: []certv1.CertificateCondition{{
+						Type:   certv1.CertificateConditionReady,
+						Status: cmmeta.ConditionFalse,
+					}},
+				},
+			}
+			newCertificate = oldCertificate.DeepCopy()
+			newCertificate.SetResourceVersion("2")
+		})
+
+		update := func() bool {
+			return ef.Update(event.UpdateEvent{MetaOld: oldCertificate.GetObjectMeta(), ObjectOld: oldCertificate, MetaNew: newCertificate.GetObjectMeta(), ObjectNew: newCertificate})
+		}
+
+		It("Should process issuance", func() {
+			newCertificate.Status.Conditions[0].Status = cmmeta.ConditionTrue
+
+			Expect(update()).To(BeTrue())
+		})
+
+		It("Should process renewals", func() {
+			notAfter := metav1.Now()
+			newCertificate.Status.NotAfter = &notAfter
+
+			Expect(update()).To(BeTrue())
+		})
+
+		It("Should ignore other status updates", func() {
+			newCertificate.Status.Conditions[0].Message = "pending"
+
+			Expect(update()).To(BeFalse())
+		})
+	})
+})
+
+var _ = Describe("event-filter with namespaces and selector", func() {
+	var ef *EventFilter
+	var h *goharborv1alpha1.Harbor
+
+	BeforeEach(func() {
+		r, _ := setupTest(context.TODO())
+		r.Config.Namespaces = []string{"registry", "staging"}
+		r.Config.Selector = labels.SelectorFromSet(labels.Set{"team": "platform"})
+		ef = r.GetEventFilter()
+
+		h = &goharborv1alpha1.Harbor{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "my-harbor",
+				Namespace: "staging",
+				Labels:    map[string]string{"team": "platform"},
+			},
+		}
+	})
+
+	It("Should match selected Harbors", func() {
+		Expect(ef.Create(event.CreateEvent{Meta: h.GetObjectMeta(), Object: h})).To(BeTrue())
+		Expect(ef.IsManaged(h)).To(BeTrue())
+	})
+
+	It("Should not match Harbors of other namespaces", func() {
+		h.SetNamespace("default")
+
+		Expect(ef.Create(event.CreateEvent{Meta: h.GetObjectMeta(), Object: h})).To(BeFalse())
+		Expect(ef.Generic(event.GenericEvent{Meta: h.GetObjectMeta(), Object: h})).To(BeFalse())
+		Expect(ef.IsManaged(h)).To(BeFalse())
+	})
+
+	It("Should not match Harbors not matching the selector", func() {
+		h.SetLabels(map[string]string{"team": "data"})
+
+		Expect(ef.Delete(event.DeleteEvent{Meta: h.GetObjectMeta(), Object: h})).To(BeFalse())
+		Expect(ef.IsManaged(h)).To(BeFalse())
+	})
+
+	It("Should match Harbors leaving the selection", func() {
+		newHarbor := h.DeepCopy()
+		newHarbor.SetLabels(nil)
+
+		Expect(ef.Update(event.UpdateEvent{MetaOld: h.GetObjectMeta(), ObjectOld: h, MetaNew: newHarbor.GetObjectMeta(), ObjectNew: newHarbor})).To(BeTrue())
+	})
+
+	It("Should not apply the selector to owned resources", func() {
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-harbor-core", Namespace: "staging"},
+		}
+
+		Expect(ef.IsSelected(deployment, deployment)).To(BeTrue())
+
+		deployment.SetNamespace("default")
+		Expect(ef.IsSelected(deployment, deployment)).To(BeFalse())
+	})
+})
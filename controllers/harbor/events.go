@@ -0,0 +1,54 @@
+package harbor
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components"
+)
+
+// Reasons of the events emitted on Harbor resources.
+const (
+	CreatedReason            = "Created"
+	UpdatedReason            = "Updated"
+	DeletedReason            = "Deleted"
+	ApplyFailedReason        = "ApplyFailed"
+	HealthyReason            = "Healthy"
+	UnhealthyReason          = "Unhealthy"
+	CertificateRenewedReason = "CertificateRenewed"
+	CertificateIssuedReason  = "CertificateIssued"
+	CertificateFailedReason  = "CertificateFailed"
+	PushPullSucceededReason  = "PushPullSucceeded"
+	PushPullFailedReason     = "PushPullFailed"
+	PausedReason             = "Paused"
+	ResumedReason            = "Resumed"
+	DriftCorrectedReason     = "DriftCorrected"
+	DriftDetectedReason      = "DriftDetected"
+	TrustedCAChangedReason   = "TrustedCAChanged"
+)
+
+// +kubebuilder:rbac:groups="",resources="events",verbs=create;patch
+
+// getKind returns the kind of the resource, whose type meta is usually empty.
+func (r *Reconciler) getKind(resource components.Resource) string {
+	gvk, err := apiutil.GVKForObject(resource, r.Scheme)
+	if err != nil {
+		return fmt.Sprintf("%T", resource)
+	}
+
+	return gvk.Kind
+}
+
+// ResourceEvent emits an event about a resource of the component in the context.
+func (r *Reconciler) ResourceEvent(ctx context.Context, harbor *goharborv1alpha1.Harbor, resource components.Resource, eventType, reason, message string) {
+	r.Recorder.Eventf(harbor, eventType, reason, "%s: %s %s %s", components.ComponentName(ctx), r.getKind(resource), resource.GetName(), message)
+}
+
+// ResourceErrorEvent emits a warning about a resource which cannot be applied.
+func (r *Reconciler) ResourceErrorEvent(ctx context.Context, harbor *goharborv1alpha1.Harbor, resource components.Resource, err error) {
+	r.Recorder.Eventf(harbor, corev1.EventTypeWarning, ApplyFailedReason, "%s: %s %s: %v", components.ComponentName(ctx), r.getKind(resource), resource.GetName(), err)
+}
@@ -0,0 +1,184 @@
+package harbor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// HarborAdminUsername is the name of the Harbor administrator, whose password is in adminPasswordSecret.
+	HarborAdminUsername = "admin"
+
+	robotAccountPrefix = "robot$"
+)
+
+// harborAPI calls the Harbor API as administrator.
+type harborAPI struct {
+	url       *url.URL
+	client    *http.Client
+	password  string
+	userAgent string
+}
+
+type harborProject struct {
+	ProjectID int64  `json:"project_id"`
+	Name      string `json:"name"`
+}
+
+type harborRobotAccess struct {
+	Resource string `json:"resource"`
+	Action   string `json:"action"`
+}
+
+type harborRobot struct {
+	ID    int64  `json:"-"`
+	Name  string `json:"name"`
+	Token string `json:"token"`
+}
+
+// EnsureProject returns the id of the private project, created when missing.
+func (a *harborAPI) EnsureProject(ctx context.Context, name string) (int64, error) {
+	id, err := a.getProjectID(ctx, name)
+	if err != nil || id != 0 {
+		return id, err
+	}
+
+	_, err = a.do(ctx, http.MethodPost, "/api/projects", nil, map[string]interface{}{
+		"project_name": name,
+		"metadata": map[string]string{
+			"public": "false",
+		},
+	}, nil, http.StatusCreated, http.StatusConflict)
+	if err != nil {
+		return 0, errors.Wrapf(err, "cannot create project %s", name)
+	}
+
+	id, err = a.getProjectID(ctx, name)
+	if err == nil && id == 0 {
+		return 0, errors.Errorf("project %s not found after creation", name)
+	}
+
+	return id, err
+}
+
+func (a *harborAPI) getProjectID(ctx context.Context, name string) (int64, error) {
+	var projects []harborProject
+
+	_, err := a.do(ctx, http.MethodGet, "/api/projects", url.Values{"name": []string{name}}, nil, &projects, http.StatusOK)
+	if err != nil {
+		return 0, errors.Wrapf(err, "cannot get project %s", name)
+	}
+
+	// The name parameter matches projects containing the name
+	for _, project := range projects {
+		if project.Name == name {
+			return project.ProjectID, nil
+		}
+	}
+
+	return 0, nil
+}
+
+// CreateRobot creates a robot account allowed to push and pull in the project.
+func (a *harborAPI) CreateRobot(ctx context.Context, projectID int64, name, description string) (*harborRobot, error) {
+	resource := fmt.Sprintf("/project/%d/repository", projectID)
+
+	robot := &harborRobot{}
+
+	response, err := a.do(ctx, http.MethodPost, fmt.Sprintf("/api/projects/%d/robots", projectID), nil, map[string]interface{}{
+		"name":        name,
+		"description": description,
+		"access": []harborRobotAccess{
+			{Resource: resource, Action: "push"},
+			{Resource: resource, Action: "pull"},
+		},
+	}, robot, http.StatusCreated)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot create robot account %s", name)
+	}
+
+	// The id is only returned in the location of the robot account
+	if location := response.Header.Get("Location"); location != "" {
+		robot.ID, _ = strconv.ParseInt(path.Base(location), 10, 64)
+	}
+
+	if robot.Name == "" {
+		robot.Name = robotAccountPrefix + name
+	}
+
+	return robot, nil
+}
+
+// DeleteRobot deletes the robot account, if it still exists.
+func (a *harborAPI) DeleteRobot(ctx context.Context, projectID, id int64) error {
+	_, err := a.do(ctx, http.MethodDelete, fmt.Sprintf("/api/projects/%d/robots/%d", projectID, id), nil, nil, nil, http.StatusOK, http.StatusNotFound)
+
+	return errors.Wrapf(err, "cannot delete robot account %d", id)
+}
+
+// DeleteTag deletes the tag of the repository, if it still exists.
+func (a *harborAPI) DeleteTag(ctx context.Context, repository, tag string) error {
+	_, err := a.do(ctx, http.MethodDelete, fmt.Sprintf("/api/repositories/%s/tags/%s", repository, tag), nil, nil, nil, http.StatusOK, http.StatusNotFound)
+
+	return errors.Wrapf(err, "cannot delete %s:%s", repository, tag)
+}
+
+// do sends body as JSON, and decodes the response into result when the status code is expected.
+func (a *harborAPI) do(ctx context.Context, method, p string, query url.Values, body, result interface{}, statusCodes ...int) (*http.Response, error) {
+	var reader io.Reader
+
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot marshal request")
+		}
+
+		reader = bytes.NewReader(data)
+	}
+
+	u := a.url.ResolveReference(&url.URL{Path: p, RawQuery: query.Encode()})
+
+	request, err := http.NewRequest(method, u.String(), reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create request")
+	}
+
+	request.SetBasicAuth(HarborAdminUsername, a.password)
+	request.Header.Set("User-Agent", a.userAgent)
+
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := a.client.Do(request.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	for _, statusCode := range statusCodes {
+		if response.StatusCode != statusCode {
+			continue
+		}
+
+		if result != nil && statusCode == statusCodes[0] {
+			err = json.NewDecoder(response.Body).Decode(result)
+			if err != nil {
+				return nil, errors.Wrap(err, "unexpected response")
+			}
+		}
+
+		return response, nil
+	}
+
+	return nil, errors.Errorf("unexpected response: %s", response.Status)
+}
@@ -0,0 +1,73 @@
+package harbor
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/pkg/factories/logger"
+)
+
+var HarborClassGVK = goharborv1alpha1.GroupVersion.WithKind("HarborClass")
+
+// +kubebuilder:rbac:groups=goharbor.io,resources=harborclasses,verbs=get;list;watch
+
+// GetHarborClassReader returns the reader of HarborClasses, nil when they are disabled or their API is not available.
+func (r *Reconciler) GetHarborClassReader() client.Reader {
+	if !r.harborClassAPIAvailable {
+		return nil
+	}
+
+	return r.Client
+}
+
+// ApplyHarborClass returns a copy of the Harbor merged over the defaults of its class.
+// The Harbor is returned as is when it has no class.
+func (r *Reconciler) ApplyHarborClass(ctx context.Context, harbor *goharborv1alpha1.Harbor) (*goharborv1alpha1.Harbor, error) {
+	reader := r.GetHarborClassReader()
+	if reader == nil {
+		return harbor, nil
+	}
+
+	class, err := goharborv1alpha1.GetHarborClass(ctx, reader, harbor)
+	if err != nil {
+		return nil, err
+	}
+
+	if class == nil {
+		return harbor, nil
+	}
+
+	logger.Get(ctx).V(1).Info("merging harbor class", "HarborClass", class.GetName())
+
+	merged, err := harbor.WithClass(class)
+
+	return merged, errors.Wrapf(err, "cannot merge harbor class %s", class.GetName())
+}
+
+// GetHarborsOfClass returns a request for every Harbor of the HarborClass.
+func (r *Reconciler) GetHarborsOfClass(object handler.MapObject) []reconcile.Request {
+	var harbors goharborv1alpha1.HarborList
+
+	err := r.Client.List(context.TODO(), &harbors)
+	if err != nil {
+		r.Log.Error(err, "cannot list harbors", "HarborClass", object.Meta.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+
+	for _, harbor := range harbors.Items {
+		if harbor.GetHarborClassName() == object.Meta.GetName() {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: client.ObjectKey{Namespace: harbor.GetNamespace(), Name: harbor.GetName()},
+			})
+		}
+	}
+
+	return requests
+}
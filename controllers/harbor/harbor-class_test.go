@@ -0,0 +1,219 @@
+package harbor
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+)
+
+var _ = Describe("HarborClass", func() {
+	var r *Reconciler
+	var ctx context.Context
+	var harbor *goharborv1alpha1.Harbor
+	var class *goharborv1alpha1.HarborClass
+
+	BeforeEach(func() {
+		r, ctx = setupTest(context.TODO())
+		r.Log = zap.LoggerTo(GinkgoWriter, true)
+		r.harborClassAPIAvailable = true
+
+		replicas := int32(2)
+
+		class = &goharborv1alpha1.HarborClass{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "internal",
+			},
+			Spec: goharborv1alpha1.HarborClassSpec{
+				ImageRegistry: "registry.example.com/mirror",
+				Defaults: goharborv1alpha1.HarborClassDefaults{
+					CertificateIssuerRef: &cmmeta.ObjectReference{
+						Name: "internal-ca",
+						Kind: "ClusterIssuer",
+					},
+					Deployment: &goharborv1alpha1.HarborDeploymentDefaults{
+						Replicas:         &replicas,
+						ImagePullSecrets: []corev1.LocalObjectReference{{Name: "mirror"}},
+					},
+					Components: goharborv1alpha1.HarborComponents{
+						JobService: &goharborv1alpha1.JobServiceComponent{
+							WorkerCount: 10,
+						},
+						Clair: &goharborv1alpha1.ClairComponent{
+							DatabaseSecret: "clair-database",
+						},
+					},
+				},
+			},
+		}
+
+		image := "goharbor/harbor-portal:dev"
+
+		harbor = &goharborv1alpha1.Harbor{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "my-harbor",
+				Namespace:   "registry",
+				Annotations: map[string]string{goharborv1alpha1.HarborClassAnnotation: "internal"},
+			},
+			Spec: goharborv1alpha1.HarborSpec{
+				PublicURL: "https://registry.example.com",
+				Components: goharborv1alpha1.HarborComponents{
+					Core: &goharborv1alpha1.CoreComponent{
+						DatabaseSecret: "core-database",
+					},
+					Portal: &goharborv1alpha1.PortalComponent{
+						HarborDeployment: goharborv1alpha1.HarborDeployment{
+							Image: &image,
+						},
+					},
+					JobService: &goharborv1alpha1.JobServiceComponent{},
+				},
+			},
+		}
+	})
+
+	Context("ApplyHarborClass", func() {
+		BeforeEach(func() {
+			r.Client = fake.NewFakeClientWithScheme(r.Scheme, class.DeepCopy())
+		})
+
+		It("Should merge the harbor over the defaults", func() {
+			replicas := int32(1)
+			harbor.Spec.Components.Core.Replicas = &replicas
+			harbor.Spec.Components.JobService.WorkerCount = 3
+
+			merged, err := r.ApplyHarborClass(ctx, harbor)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(merged.Spec.PublicURL).To(Equal("https://registry.example.com"))
+			Expect(merged.Spec.CertificateIssuerRef.Name).To(Equal("internal-ca"))
+
+			Expect(*merged.Spec.Components.Core.Replicas).To(BeEquivalentTo(1))
+			Expect(*merged.Spec.Components.Portal.Replicas).To(BeEquivalentTo(2))
+			Expect(merged.Spec.Components.Portal.ImagePullSecrets).To(ConsistOf(corev1.LocalObjectReference{Name: "mirror"}))
+			Expect(merged.Spec.Components.JobService.WorkerCount).To(BeEquivalentTo(3))
+
+			// The harbor is not changed
+			Expect(harbor.Spec.CertificateIssuerRef.Name).To(BeEmpty())
+			Expect(harbor.Spec.Components.Portal.Replicas).To(BeNil())
+		})
+
+		It("Should keep explicit zero values", func() {
+			replicas := int32(0)
+			harbor.Spec.Components.Core.Replicas = &replicas
+
+			merged, err := r.ApplyHarborClass(ctx, harbor)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(*merged.Spec.Components.Core.Replicas).To(BeEquivalentTo(0))
+			Expect(*merged.Spec.Components.Portal.Replicas).To(BeEquivalentTo(2))
+			Expect(merged.Spec.Components.JobService.WorkerCount).To(BeEquivalentTo(10))
+		})
+
+		It("Should keep explicit false values", func() {
+			disabled, enabled := true, false
+			class.Spec.Defaults.Monitoring = &goharborv1alpha1.HarborMonitoring{DisableRules: &disabled}
+			harbor.Spec.Monitoring = &goharborv1alpha1.HarborMonitoring{DisableRules: &enabled}
+			r.Client = fake.NewFakeClientWithScheme(r.Scheme, class.DeepCopy())
+
+			merged, err := r.ApplyHarborClass(ctx, harbor)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(merged.Spec.Monitoring.AreRulesDisabled()).To(BeFalse())
+		})
+
+		It("Should not enable components", func() {
+			merged, err := r.ApplyHarborClass(ctx, harbor)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(merged.Spec.Components.Clair).To(BeNil())
+			Expect(merged.Spec.Components.Registry).To(BeNil())
+		})
+
+		It("Should pull default images from the image registry", func() {
+			merged, err := r.ApplyHarborClass(ctx, harbor)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(*merged.Spec.Components.Core.Image).To(Equal("registry.example.com/mirror/goharbor/harbor-core:v1.10.0"))
+			Expect(*merged.Spec.Components.Portal.Image).To(Equal("goharbor/harbor-portal:dev"))
+		})
+
+		It("Should ignore missing classes", func() {
+			harbor.SetAnnotations(map[string]string{goharborv1alpha1.HarborClassAnnotation: "other"})
+
+			merged, err := r.ApplyHarborClass(ctx, harbor)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(merged).To(Equal(harbor))
+		})
+
+		It("Should ignore classes when disabled", func() {
+			r.harborClassAPIAvailable = false
+
+			merged, err := r.ApplyHarborClass(ctx, harbor)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(merged).To(Equal(harbor))
+		})
+	})
+
+	Context("GetHarborsOfClass", func() {
+		It("Should request harbors of the class", func() {
+			other := harbor.DeepCopy()
+			other.SetName("other-harbor")
+			other.SetAnnotations(nil)
+
+			r.Client = fake.NewFakeClientWithScheme(r.Scheme, class.DeepCopy(), harbor.DeepCopy(), other)
+
+			requests := r.GetHarborsOfClass(handler.MapObject{Meta: class, Object: class})
+			Expect(requests).To(HaveLen(1))
+			Expect(requests[0].NamespacedName).To(Equal(client.ObjectKey{Namespace: "registry", Name: "my-harbor"}))
+		})
+	})
+
+	Context("EventFilter", func() {
+		var ef *EventFilter
+
+		BeforeEach(func() {
+			class.Spec.Controller = "platform"
+			r.Client = fake.NewFakeClientWithScheme(r.Scheme, class.DeepCopy())
+		})
+
+		JustBeforeEach(func() {
+			ef = r.GetEventFilter()
+		})
+
+		It("Should match harbors whose class is reconciled by the operator", func() {
+			ef.ClassName = "platform"
+
+			Expect(ef.Create(event.CreateEvent{Meta: harbor, Object: harbor})).To(BeTrue())
+		})
+
+		It("Should not match harbors whose class is reconciled by another operator", func() {
+			ef.ClassName = "internal"
+
+			Expect(ef.Create(event.CreateEvent{Meta: harbor, Object: harbor})).To(BeFalse())
+		})
+
+		It("Should match the annotation of harbors without HarborClass", func() {
+			ef.ClassName = "other"
+			harbor.SetAnnotations(map[string]string{goharborv1alpha1.HarborClassAnnotation: "other"})
+
+			Expect(ef.Create(event.CreateEvent{Meta: harbor, Object: harbor})).To(BeTrue())
+		})
+
+		It("Should process events of HarborClasses", func() {
+			Expect(ef.Update(event.UpdateEvent{MetaOld: class, ObjectOld: class, MetaNew: class, ObjectNew: class})).To(BeTrue())
+		})
+	})
+})
@@ -2,46 +2,110 @@ package harbor
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
 	certv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha2"
+	routev1 "github.com/openshift/api/route/v1"
+	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/monitoring"
+	"github.com/goharbor/harbor-operator/pkg/event"
 	"github.com/goharbor/harbor-operator/pkg/factories/logger"
 )
 
-const (
-	DefaultRequeueWait = 2 * time.Second
-)
-
 type Config struct {
 	ClassName            string
 	ConcurrentReconciles int
-	WatchChildren        bool
+	// WatchChildren enables the watch of resources owned by Harbors.
+	// When disabled, changes of owned resources are only handled on resync.
+	WatchChildren bool
+	// Namespaces are the namespaces of the Harbors to reconcile, every namespace when empty.
+	Namespaces []string
+	// Selector selects the Harbors to reconcile by their labels, every Harbor when nil.
+	Selector labels.Selector
+	// HarborClasses enables the defaults of HarborClasses and their controller.
+	// Disable it when the operator cannot watch cluster-scoped resources.
+	HarborClasses bool
+	HealthProbe   HealthProbeConfig
+	// SyntheticProbeTimeout is the timeout of the push, pull and delete of the synthetic probe image.
+	SyntheticProbeTimeout time.Duration
+	// DriftPolicy defines what is done with owned resources changed outside of the operator.
+	DriftPolicy DriftPolicy
+	// ReconcileTimeout is the deadline of a reconciliation, DefaultReconcileTimeout when zero.
+	ReconcileTimeout time.Duration
+	// Requeue defines when Harbors are reconciled again, GetDefaultRequeueConfig() when zero.
+	Requeue RequeueConfig
+	// LegacyCertificates are the key settings of the deprecated certificate-encryption configstore slice,
+	// used for the registry token and notary certificates whose key is not set by the Harbor.
+	LegacyCertificates *goharborv1alpha1.CertificateSettings
+}
+
+// GetReconcileTimeout returns the deadline of a reconciliation.
+func (c *Config) GetReconcileTimeout() time.Duration {
+	if c.ReconcileTimeout <= 0 {
+		return DefaultReconcileTimeout
+	}
+
+	return c.ReconcileTimeout
+}
+
+// GetRequeue returns when Harbors are reconciled again.
+func (c *Config) GetRequeue() RequeueConfig {
+	if c.Requeue == (RequeueConfig{}) {
+		return GetDefaultRequeueConfig()
+	}
+
+	return c.Requeue
 }
 
 // Reconciler reconciles a Harbor object
 type Reconciler struct {
 	client.Client
 
+	// APIReader reads objects which are not cached, such as pods.
+	APIReader client.Reader
+
 	Name    string
 	Version string
 
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
 
 	RestConfig *rest.Config
 
 	Config Config
+	// configLock protects the parts of Config reloaded while running.
+	configLock sync.RWMutex
+
+	routeAPIAvailable          bool
+	harborClassAPIAvailable    bool
+	serviceMonitorAPIAvailable bool
+	prometheusRuleAPIAvailable bool
+
+	requeue RequeueTracker
+
+	collector       *Collector
+	healthProber    *HealthProber
+	syntheticProber *SyntheticProber
 }
 
 func (r *Reconciler) GetVersion() string {
@@ -54,24 +118,144 @@ func (r *Reconciler) GetName() string {
 
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.Client = mgr.GetClient()
+	r.APIReader = mgr.GetAPIReader()
 	r.Scheme = mgr.GetScheme()
 	r.RestConfig = mgr.GetConfig()
+	r.Recorder = event.NewRateLimitedRecorder(mgr.GetEventRecorderFor(r.GetName()), event.DefaultInterval, event.DefaultBurst)
+
+	routeAPIAvailable, err := IsRouteAPIAvailable(r.RestConfig)
+	if err != nil {
+		return errors.Wrap(err, "cannot check route availability")
+	}
+
+	r.routeAPIAvailable = routeAPIAvailable
+
+	r.serviceMonitorAPIAvailable, err = IsAPIAvailable(r.RestConfig, monitoring.ServiceMonitorGVK)
+	if err != nil {
+		return errors.Wrap(err, "cannot check servicemonitor availability")
+	}
+
+	r.prometheusRuleAPIAvailable, err = IsAPIAvailable(r.RestConfig, monitoring.PrometheusRuleGVK)
+	if err != nil {
+		return errors.Wrap(err, "cannot check prometheusrule availability")
+	}
+
+	switch {
+	case !r.Config.HarborClasses:
+	case len(r.Config.Namespaces) > 1:
+		// The cache of several namespaces cannot get cluster-scoped resources
+		r.Log.Info("harbor classes are disabled when several namespaces are watched")
+	default:
+		r.harborClassAPIAvailable, err = IsAPIAvailable(r.RestConfig, HarborClassGVK)
+		if err != nil {
+			return errors.Wrap(err, "cannot check harborclass availability")
+		}
+	}
+
+	r.collector = NewCollector(mgr.GetClient())
+
+	err = metrics.Registry.Register(r.collector)
+	if err != nil {
+		return errors.Wrap(err, "cannot register metrics")
+	}
+
+	err = r.setupHealthProber(mgr)
+	if err != nil {
+		return errors.Wrap(err, "cannot setup health prober")
+	}
 
-	return ctrl.NewControllerManagedBy(mgr).
+	r.syntheticProber = NewSyntheticProber(mgr.GetClient(),
+		NewSyntheticProbe(mgr.GetClient(), r.Scheme, fmt.Sprintf("%s(%s)", r.GetName(), r.GetVersion())),
+		r.GetEventFilter(), r.Log.WithName("synthetic"), r.Config.SyntheticProbeTimeout)
+
+	err = mgr.Add(r.syntheticProber)
+	if err != nil {
+		return errors.Wrap(err, "cannot add synthetic prober")
+	}
+
+	builder := ctrl.NewControllerManagedBy(mgr).
+		Watches(r.healthProber.Source(), &handler.EnqueueRequestForObject{}).
+		Watches(r.syntheticProber.Source(), &handler.EnqueueRequestForObject{}).
 		WithEventFilter(r.GetEventFilter()).
-		For(&goharborv1alpha1.Harbor{}).
-		Owns(&appsv1.Deployment{}).
-		Owns(&certv1.Certificate{}).
-		Owns(&corev1.ConfigMap{}).
-		Owns(&netv1.Ingress{}).
-		Owns(&corev1.Secret{}).
-		Owns(&corev1.Service{}).
+		For(&goharborv1alpha1.Harbor{})
+
+	if r.harborClassAPIAvailable {
+		builder = builder.Watches(&source.Kind{Type: &goharborv1alpha1.HarborClass{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.GetHarborsOfClass),
+		})
+	}
+
+	if r.Config.WatchChildren {
+		for _, owned := range r.GetOwnedTypes() {
+			builder = builder.Owns(owned)
+		}
+	} else {
+		r.Log.Info("children are not watched, their changes are handled on resync")
+	}
+
+	return builder.
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: r.Config.ConcurrentReconciles,
 		}).
 		Complete(r)
 }
 
+// GetOwnedTypes returns the types of resources created by components, whose API is available.
+func (r *Reconciler) GetOwnedTypes() []runtime.Object {
+	owned := []runtime.Object{
+		&appsv1.Deployment{},
+		&certv1.Certificate{},
+		&corev1.ConfigMap{},
+		&netv1.Ingress{},
+		&corev1.Secret{},
+		&corev1.Service{},
+	}
+
+	if r.routeAPIAvailable {
+		owned = append(owned, &routev1.Route{})
+	}
+
+	if r.serviceMonitorAPIAvailable {
+		serviceMonitor := &unstructured.Unstructured{}
+		serviceMonitor.SetGroupVersionKind(monitoring.ServiceMonitorGVK)
+
+		owned = append(owned, serviceMonitor)
+	}
+
+	if r.prometheusRuleAPIAvailable {
+		prometheusRule := &unstructured.Unstructured{}
+		prometheusRule.SetGroupVersionKind(monitoring.PrometheusRuleGVK)
+
+		owned = append(owned, prometheusRule)
+	}
+
+	return owned
+}
+
+func (r *Reconciler) setupHealthProber(mgr ctrl.Manager) error {
+	userAgent := fmt.Sprintf("%s(%s)", r.GetName(), r.GetVersion())
+
+	var probe HealthProbe
+
+	switch r.Config.HealthProbe.Mode {
+	case DirectHealthProbeMode:
+		probe = NewDirectHealthProbe(mgr.GetClient(), userAgent)
+	case ProxyHealthProbeMode, "":
+		var err error
+
+		probe, err = NewProxyHealthProbe(r.RestConfig, r.Scheme, userAgent)
+		if err != nil {
+			return err
+		}
+	default:
+		return errors.Errorf("unsupported health probe mode %q", r.Config.HealthProbe.Mode)
+	}
+
+	r.healthProber = NewHealthProber(mgr.GetClient(), probe, r.GetEventFilter(), r.Log.WithName("health"), r.Config.HealthProbe)
+
+	return errors.Wrap(mgr.Add(r.healthProber), "cannot add health prober")
+}
+
 func New(ctx context.Context, name, version string, config *Config) (*Reconciler, error) {
 	return &Reconciler{
 		Name:    name,
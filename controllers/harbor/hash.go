@@ -0,0 +1,167 @@
+package harbor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components"
+)
+
+var desiredStateHashContext = "desired-state-hash"
+
+// withDesiredStateHash returns a context whose resources are annotated with the hash of the desired state of their component.
+func withDesiredStateHash(ctx context.Context, hash string) context.Context {
+	return context.WithValue(ctx, &desiredStateHashContext, hash)
+}
+
+func getDesiredStateHash(ctx context.Context) string {
+	hash, _ := ctx.Value(&desiredStateHashContext).(string)
+	return hash
+}
+
+// getComponentResources returns every resource of the component, configurations must have been rendered.
+func getComponentResources(ctx context.Context, component *components.ComponentRunner) ([]components.Resource, error) {
+	routes, err := component.GetRoutes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []components.Resource
+
+	for _, list := range [][]components.Resource{
+		component.GetServices(ctx),
+		component.GetConfigMaps(ctx),
+		component.GetIngresses(ctx),
+		routes,
+		component.GetSecrets(ctx),
+		component.GetCertificates(ctx),
+		component.GetDeployments(ctx),
+		component.GetServiceMonitors(ctx),
+		component.GetPrometheusRules(ctx),
+	} {
+		resources = append(resources, list...)
+	}
+
+	return resources, nil
+}
+
+// GetDesiredStateHash returns the hash of the resources of the component.
+// Generated passwords are not part of the hash, since a new value is generated for every reconciliation
+// and existing values are kept. Certificates of routes are only part of it through their checksum.
+func (r *Reconciler) GetDesiredStateHash(ctx context.Context, harbor *goharborv1alpha1.Harbor, component *components.ComponentRunner) (string, error) {
+	resources, err := getComponentResources(ctx, component)
+	if err != nil {
+		return "", err
+	}
+
+	err = r.AnnotateRoutesTLSChecksum(ctx, harbor, resources)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot compute routes tls checksum")
+	}
+
+	return r.GetResourcesHash(resources)
+}
+
+// GetResourcesHash returns the hash of the resources, whatever their order.
+// The values of StringData of secrets are ignored.
+func (r *Reconciler) GetResourcesHash(resources []components.Resource) (string, error) {
+	resources = append([]components.Resource(nil), resources...)
+
+	sort.Slice(resources, func(i, j int) bool {
+		return getResourceSortKey(resources[i]) < getResourceSortKey(resources[j])
+	})
+
+	contents := make([]map[string]interface{}, len(resources))
+
+	for i, resource := range resources {
+		gvk, err := apiutil.GVKForObject(resource, r.Scheme)
+		if err != nil {
+			return "", errors.Wrapf(err, "cannot get kind of %s", resource.GetName())
+		}
+
+		resource := resource.DeepCopyObject()
+
+		if secret, ok := resource.(*corev1.Secret); ok {
+			for key := range secret.StringData {
+				secret.StringData[key] = ""
+			}
+		}
+
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(resource)
+		if err != nil {
+			return "", errors.Wrapf(err, "cannot convert %s", resources[i].GetName())
+		}
+
+		content["apiVersion"], content["kind"] = gvk.ToAPIVersionAndKind()
+		contents[i] = content
+	}
+
+	// Maps are serialized with sorted keys
+	data, err := json.Marshal(map[string]interface{}{
+		"operator":  r.GetName(),
+		"version":   r.GetVersion(),
+		"resources": contents,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "cannot serialize resources")
+	}
+
+	return fmt.Sprintf("%x", sha256.Sum256(data)), nil
+}
+
+func getResourceSortKey(resource components.Resource) string {
+	return fmt.Sprintf("%T/%s", resource, resource.GetName())
+}
+
+// GetDesiredStateHashes returns the hash of the desired state of every component, by component name.
+func (r *Reconciler) GetDesiredStateHashes(ctx context.Context, harbor *goharborv1alpha1.Harbor, harborResources *components.Components) (map[string]string, error) {
+	var lock sync.Mutex
+
+	hashes := map[string]string{}
+
+	err := harborResources.ParallelRun(ctx, harbor, func(ctx context.Context, harbor *goharborv1alpha1.Harbor, component *components.ComponentRunner) error {
+		hash, err := r.GetDesiredStateHash(ctx, harbor, component)
+		if err != nil {
+			return err
+		}
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		hashes[components.ComponentName(ctx)] = hash
+
+		return nil
+	})
+
+	return hashes, err
+}
+
+// UpdateComponentsStatus records the desired state applied for each component.
+// Paused components keep the desired state applied before they were paused.
+func (r *Reconciler) UpdateComponentsStatus(ctx context.Context, harbor *goharborv1alpha1.Harbor, hashes map[string]string) {
+	for _, component := range append([]goharborv1alpha1.ComponentStatus(nil), harbor.Status.Components...) {
+		if _, ok := hashes[component.Name]; !ok && !harbor.IsComponentPaused(component.Name) {
+			harbor.Status.RemoveDesiredStateHash(component.Name)
+		}
+	}
+
+	for name, hash := range hashes {
+		if !harbor.IsComponentPaused(name) {
+			harbor.Status.SetDesiredStateHash(name, hash)
+		}
+	}
+
+	sort.Slice(harbor.Status.Components, func(i, j int) bool {
+		return harbor.Status.Components[i].Name < harbor.Status.Components[j].Name
+	})
+}
@@ -0,0 +1,113 @@
+package harbor
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components"
+)
+
+var _ = Describe("Desired state hash", func() {
+	var r *Reconciler
+	var ctx context.Context
+
+	newResources := func(password string) []components.Resource {
+		return []components.Resource{
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-harbor-core"},
+				Data:       map[string]string{"PORT": "8080"},
+			},
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-harbor-core"},
+				StringData: map[string]string{"secret": password},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		r, ctx = setupTest(context.TODO())
+		r.Version = "dev"
+	})
+
+	Context("GetResourcesHash", func() {
+		It("Should ignore generated passwords", func() {
+			hash, err := r.GetResourcesHash(newResources("first"))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(r.GetResourcesHash(newResources("second"))).To(Equal(hash))
+		})
+
+		It("Should ignore the order of resources", func() {
+			resources := newResources("")
+
+			hash, err := r.GetResourcesHash(resources)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(r.GetResourcesHash([]components.Resource{resources[1], resources[0]})).To(Equal(hash))
+		})
+
+		It("Should change with the resources", func() {
+			resources := newResources("")
+
+			hash, err := r.GetResourcesHash(resources)
+			Expect(err).ToNot(HaveOccurred())
+
+			resources[0].(*corev1.ConfigMap).Data["PORT"] = "8443"
+			Expect(r.GetResourcesHash(resources)).ToNot(Equal(hash))
+		})
+
+		It("Should change with the operator version", func() {
+			hash, err := r.GetResourcesHash(newResources(""))
+			Expect(err).ToNot(HaveOccurred())
+
+			r.Version = "v0.6.0"
+			Expect(r.GetResourcesHash(newResources(""))).ToNot(Equal(hash))
+		})
+	})
+
+	Context("UpdateComponentsStatus", func() {
+		var harbor *goharborv1alpha1.Harbor
+
+		BeforeEach(func() {
+			harbor = &goharborv1alpha1.Harbor{}
+			harbor.Status.Components = []goharborv1alpha1.ComponentStatus{
+				{Name: goharborv1alpha1.ChartMuseumName, DesiredStateHash: "a"},
+				{Name: goharborv1alpha1.CoreName, DesiredStateHash: "b"},
+				{Name: goharborv1alpha1.RegistryName, DesiredStateHash: "c"},
+			}
+		})
+
+		It("Should record the applied hashes", func() {
+			r.UpdateComponentsStatus(ctx, harbor, map[string]string{
+				goharborv1alpha1.RegistryName: "d",
+				goharborv1alpha1.CoreName:     "b",
+			})
+
+			Expect(harbor.Status.Components).To(Equal([]goharborv1alpha1.ComponentStatus{
+				{Name: goharborv1alpha1.CoreName, DesiredStateHash: "b"},
+				{Name: goharborv1alpha1.RegistryName, DesiredStateHash: "d"},
+			}))
+		})
+
+		It("Should keep the hashes of paused components", func() {
+			harbor.Spec.PausedComponents = []goharborv1alpha1.PausableComponent{goharborv1alpha1.ChartMuseumName, goharborv1alpha1.RegistryName}
+
+			r.UpdateComponentsStatus(ctx, harbor, map[string]string{
+				goharborv1alpha1.RegistryName: "d",
+				goharborv1alpha1.CoreName:     "e",
+			})
+
+			Expect(harbor.Status.Components).To(Equal([]goharborv1alpha1.ComponentStatus{
+				{Name: goharborv1alpha1.ChartMuseumName, DesiredStateHash: "a"},
+				{Name: goharborv1alpha1.CoreName, DesiredStateHash: "e"},
+				{Name: goharborv1alpha1.RegistryName, DesiredStateHash: "c"},
+			}))
+		})
+	})
+})
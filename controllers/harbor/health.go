@@ -2,16 +2,26 @@ package harbor
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	harbor_core "github.com/goharbor/harbor-operator/controllers/harbor/components/harbor-core"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/internaltls"
 )
 
 const (
@@ -21,6 +31,9 @@ const (
 
 const (
 	HarborHealthEndpoint = "/api/health"
+
+	// InternalCACertificateKey is the key of the internal certificate secrets holding the issuer CA.
+	InternalCACertificateKey = "ca.crt"
 )
 
 type ComponentHealth struct {
@@ -60,14 +73,16 @@ func (h *APIHealth) GetUnhealthyComponents() []string {
 	return components
 }
 
-func (r *Reconciler) GetHealth(ctx context.Context, harbor *goharborv1alpha1.Harbor) (*APIHealth, error) {
-	span, ctx := opentracing.StartSpanFromContext(ctx, "check")
-	defer span.Finish()
+// HealthProbe calls the health API of a Harbor.
+type HealthProbe func(ctx context.Context, harbor *goharborv1alpha1.Harbor) (*APIHealth, error)
 
-	config := rest.CopyConfig(r.RestConfig)
+// NewProxyHealthProbe calls the health API through the API server proxy.
+// It works from outside the cluster, but every call goes through the API server.
+func NewProxyHealthProbe(config *rest.Config, scheme *runtime.Scheme, userAgent string) (HealthProbe, error) {
+	config = rest.CopyConfig(config)
 	config.APIPath = "api"
-	config = rest.AddUserAgent(config, fmt.Sprintf("%s(%s)", r.GetName(), r.GetVersion()))
-	config.NegotiatedSerializer = serializer.NewCodecFactory(r.Scheme)
+	config = rest.AddUserAgent(config, userAgent)
+	config.NegotiatedSerializer = serializer.NewCodecFactory(scheme)
 	config.GroupVersion = &corev1.SchemeGroupVersion
 
 	client, err := rest.UnversionedRESTClientFor(config)
@@ -75,22 +90,134 @@ func (r *Reconciler) GetHealth(ctx context.Context, harbor *goharborv1alpha1.Har
 		return nil, errors.Wrap(err, "cannot get rest client")
 	}
 
-	// https://kubernetes.io/docs/tasks/administer-cluster/access-cluster-services/#manually-constructing-apiserver-proxy-urls
+	return func(ctx context.Context, harbor *goharborv1alpha1.Harbor) (*APIHealth, error) {
+		span, ctx := opentracing.StartSpanFromContext(ctx, "check")
+		defer span.Finish()
+
+		// https://kubernetes.io/docs/tasks/administer-cluster/access-cluster-services/#manually-constructing-apiserver-proxy-urls
+
+		result, err := client.Get().
+			Context(ctx).
+			Resource("services").
+			Namespace(harbor.GetNamespace()).
+			Name(getCoreProxyName(harbor)).
+			SubResource("proxy").
+			Suffix(HarborHealthEndpoint).
+			DoRaw()
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot get health response")
+		}
+
+		return parseHealth(result)
+	}, nil
+}
+
+// NewDirectHealthProbe calls the health API on the core service, from inside the cluster.
+// With internal TLS, the core certificate is verified with the CA of its secret.
+func NewDirectHealthProbe(reader client.Reader, userAgent string) HealthProbe {
+	return func(ctx context.Context, harbor *goharborv1alpha1.Harbor) (*APIHealth, error) {
+		span, ctx := opentracing.StartSpanFromContext(ctx, "check")
+		defer span.Finish()
+
+		transport, err := newCoreTransport(ctx, reader, harbor)
+		if err != nil {
+			return nil, err
+		}
+		defer transport.CloseIdleConnections()
+
+		healthURL := getCoreURL(harbor).ResolveReference(&url.URL{Path: HarborHealthEndpoint})
+
+		request, err := http.NewRequest(http.MethodGet, healthURL.String(), nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot create health request")
+		}
+
+		request.Header.Set("User-Agent", userAgent)
+
+		response, err := (&http.Client{Transport: transport}).Do(request.WithContext(ctx))
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot get health response")
+		}
+		defer response.Body.Close()
+
+		result, err := ioutil.ReadAll(response.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot read health response")
+		}
+
+		if response.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("cannot get health response: %s", response.Status)
+		}
+
+		return parseHealth(result)
+	}
+}
+
+// getCoreURL returns the url of the core service.
+func getCoreURL(harbor *goharborv1alpha1.Harbor) *url.URL {
+	return &url.URL{
+		Scheme: strings.ToLower(string(internaltls.GetURIScheme(harbor))),
+		Host: fmt.Sprintf("%s.%s.svc:%d",
+			harbor.NormalizeComponentName(goharborv1alpha1.CoreName), harbor.GetNamespace(),
+			internaltls.GetPublicPort(harbor, harbor_core.PublicPort)),
+	}
+}
+
+// getCoreProxyName returns the name of the core service in API server proxy URLs.
+// With internal TLS, the scheme and port must be set, otherwise the API server sends plain HTTP on the first port.
+func getCoreProxyName(harbor *goharborv1alpha1.Harbor) string {
+	name := harbor.NormalizeComponentName(goharborv1alpha1.CoreName)
+
+	if !harbor.Spec.InternalTLS.IsEnabled() {
+		return name
+	}
+
+	return fmt.Sprintf("https:%s:%d", name, internaltls.PublicPort)
+}
+
+// newCoreTransport returns a transport calling the core service without proxy.
+func newCoreTransport(ctx context.Context, reader client.Reader, harbor *goharborv1alpha1.Harbor) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+
+	if harbor.Spec.InternalTLS.IsEnabled() {
+		tlsConfig, err := getCoreTLSConfig(ctx, reader, harbor)
+		if err != nil {
+			return nil, err
+		}
+
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return transport, nil
+}
 
-	result, err := client.Get().
-		Context(ctx).
-		Resource("services").
-		Namespace(harbor.GetNamespace()).
-		Name(harbor.NormalizeComponentName(goharborv1alpha1.CoreName)).
-		SubResource("proxy").
-		Suffix(HarborHealthEndpoint).
-		DoRaw()
+func getCoreTLSConfig(ctx context.Context, reader client.Reader, harbor *goharborv1alpha1.Harbor) (*tls.Config, error) {
+	secretName := internaltls.GetSecretName(harbor, goharborv1alpha1.CoreName)
+	secret := &corev1.Secret{}
+
+	err := reader.Get(ctx, client.ObjectKey{
+		Namespace: harbor.GetNamespace(),
+		Name:      secretName,
+	}, secret)
 	if err != nil {
-		return nil, errors.Wrap(err, "cannot get health response")
+		return nil, errors.Wrapf(err, "cannot get secret %s", secretName)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(secret.Data[InternalCACertificateKey]) {
+		return nil, errors.Errorf("no CA certificate in secret %s", secretName)
 	}
 
+	return &tls.Config{
+		RootCAs:    pool,
+		MinVersion: tls.VersionTLS12,
+	}, nil
+}
+
+func parseHealth(result []byte) (*APIHealth, error) {
 	health := &APIHealth{}
-	err = json.Unmarshal(result, health)
+	err := json.Unmarshal(result, health)
 
 	return health, errors.Wrap(err, "unexpected health response")
 }
@@ -0,0 +1,67 @@
+package harbor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+)
+
+var _ = Describe("Proxy health probe", func() {
+	var r *Reconciler
+	var ctx context.Context
+	var harbor *goharborv1alpha1.Harbor
+	var server *httptest.Server
+	var paths chan string
+
+	BeforeEach(func() {
+		r, ctx = setupTest(context.TODO())
+
+		paths = make(chan string, 1)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			paths <- req.URL.Path
+			_, _ = w.Write([]byte(`{"status":"healthy","components":[]}`))
+		}))
+
+		harbor = &goharborv1alpha1.Harbor{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "my",
+				Namespace: "registry",
+			},
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	probe := func() {
+		check, err := NewProxyHealthProbe(&rest.Config{Host: server.URL}, r.Scheme, "test")
+		Expect(err).ToNot(HaveOccurred())
+
+		health, err := check(ctx, harbor)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(health.IsHealthy()).To(BeTrue())
+	}
+
+	It("Should call the core service", func() {
+		probe()
+
+		Expect(<-paths).To(Equal("/api/v1/namespaces/registry/services/my-core/proxy/api/health"))
+	})
+
+	It("Should call the https port of the core service with internal TLS", func() {
+		harbor.Spec.InternalTLS.Enabled = true
+
+		probe()
+
+		Expect(<-paths).To(Equal("/api/v1/namespaces/registry/services/https:my-core:443/proxy/api/health"))
+	})
+})
@@ -0,0 +1,145 @@
+package harbor
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/internaltls"
+	"github.com/goharbor/harbor-operator/pkg/factories/logger"
+)
+
+const (
+	// InternalTLSChecksumAnnotation is set on pod templates so renewed internal certificates trigger a rolling restart.
+	InternalTLSChecksumAnnotation = "internal-tls/checksum"
+)
+
+// GetInternalTLSCheckSum returns the checksum of the internal certificates mounted by the deployment.
+// Certificates not yet issued are ignored.
+func (r *Reconciler) GetInternalTLSCheckSum(ctx context.Context, deployment *appsv1.Deployment) (string, error) {
+	h := sha256.New()
+	found := false
+
+	for _, volume := range deployment.Spec.Template.Spec.Volumes {
+		if volume.Name != internaltls.VolumeName || volume.Secret == nil {
+			continue
+		}
+
+		secret := &corev1.Secret{}
+
+		err := r.Client.Get(ctx, client.ObjectKey{
+			Namespace: deployment.GetNamespace(),
+			Name:      volume.Secret.SecretName,
+		}, secret)
+		if err != nil {
+			if client.IgnoreNotFound(err) == nil {
+				continue
+			}
+
+			return "", errors.Wrapf(err, "cannot get secret %s", volume.Secret.SecretName)
+		}
+
+		found = true
+
+		h.Write(secret.Data[corev1.TLSCertKey])
+		h.Write(secret.Data["ca.crt"])
+	}
+
+	if !found {
+		return "", nil
+	}
+
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// WithInternalTLSChecksum returns a ComponentRun annotating deployments with the checksum of their internal certificates before calling run.
+func (r *Reconciler) WithInternalTLSChecksum(run components.ComponentRun) components.ComponentRun {
+	return func(ctx context.Context, harbor *goharborv1alpha1.Harbor, resources []components.Resource) error {
+		if !harbor.Spec.InternalTLS.IsEnabled() {
+			return run(ctx, harbor, resources)
+		}
+
+		for _, resource := range resources {
+			deployment, ok := resource.(*appsv1.Deployment)
+			if !ok {
+				return errors.Errorf("unexpected resource %+v", resource)
+			}
+
+			checksum, err := r.GetInternalTLSCheckSum(ctx, deployment)
+			if err != nil {
+				return errors.Wrapf(err, "cannot compute internal tls checksum of %s", deployment.GetName())
+			}
+
+			if checksum == "" {
+				continue
+			}
+
+			if deployment.Spec.Template.Annotations == nil {
+				deployment.Spec.Template.Annotations = map[string]string{}
+			}
+
+			deployment.Spec.Template.Annotations[InternalTLSChecksumAnnotation] = checksum
+		}
+
+		return run(ctx, harbor, resources)
+	}
+}
+
+// RolloutRenewedCertificates patches existing deployments whose internal certificates changed.
+// Resources must have been annotated by WithInternalTLSChecksum.
+func (r *Reconciler) RolloutRenewedCertificates(ctx context.Context, harbor *goharborv1alpha1.Harbor, resources []components.Resource) error {
+	if !harbor.Spec.InternalTLS.IsEnabled() {
+		return nil
+	}
+
+	return r.rolloutChecksum(ctx, harbor, resources, InternalTLSChecksumAnnotation, CertificateRenewedReason, "its internal certificate was renewed")
+}
+
+// rolloutChecksum patches existing deployments whose checksum annotation differs from the one of resources.
+func (r *Reconciler) rolloutChecksum(ctx context.Context, harbor *goharborv1alpha1.Harbor, resources []components.Resource, annotation, reason, cause string) error {
+	for _, resource := range resources {
+		checksum, ok := resource.(*appsv1.Deployment).Spec.Template.Annotations[annotation]
+		if !ok {
+			continue
+		}
+
+		deployment := &appsv1.Deployment{}
+
+		err := r.Client.Get(ctx, client.ObjectKey{
+			Namespace: resource.GetNamespace(),
+			Name:      resource.GetName(),
+		}, deployment)
+		if err != nil {
+			return errors.Wrapf(err, "cannot get deployment %s", resource.GetName())
+		}
+
+		if deployment.Spec.Template.Annotations[annotation] == checksum {
+			continue
+		}
+
+		patch := client.MergeFrom(deployment.DeepCopy())
+
+		if deployment.Spec.Template.Annotations == nil {
+			deployment.Spec.Template.Annotations = map[string]string{}
+		}
+
+		deployment.Spec.Template.Annotations[annotation] = checksum
+
+		err = r.Client.Patch(ctx, deployment, patch)
+		if err != nil {
+			return errors.Wrapf(err, "cannot rollout deployment %s", resource.GetName())
+		}
+
+		logger.Get(ctx).Info(cause+", rolling out", "Deployment", resource.GetName())
+		r.ResourceEvent(ctx, harbor, resource, corev1.EventTypeNormal, reason, "rolled out, "+cause)
+	}
+
+	return nil
+}
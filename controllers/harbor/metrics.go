@@ -0,0 +1,188 @@
+package harbor
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components"
+	"github.com/goharbor/harbor-operator/pkg/metrics"
+)
+
+var (
+	conditionDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "harbor", "condition"),
+		"Status of the conditions of Harbor resources, 1 when True.",
+		[]string{metrics.NamespaceLabel, metrics.HarborLabel, "type"}, nil,
+	)
+
+	componentHealthDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "harbor", "component_healthy"),
+		"Status of the components reported by the Harbor health API, 1 when healthy.",
+		[]string{metrics.NamespaceLabel, metrics.HarborLabel, metrics.ComponentLabel}, nil,
+	)
+)
+
+var conditionTypes = []goharborv1alpha1.HarborConditionType{
+	goharborv1alpha1.AppliedConditionType,
+	goharborv1alpha1.ReadyConditionType,
+	goharborv1alpha1.FunctionalConditionType,
+}
+
+// Collector exposes the conditions of Harbor resources read from the cache
+// and the last health reported by their API.
+type Collector struct {
+	client client.Reader
+
+	health sync.Map // types.NamespacedName -> *APIHealth
+}
+
+var _ prometheus.Collector = &Collector{}
+
+func NewCollector(reader client.Reader) *Collector {
+	return &Collector{
+		client: reader,
+	}
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- conditionDesc
+	ch <- componentHealthDesc
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	var harbors goharborv1alpha1.HarborList
+
+	err := c.client.List(context.TODO(), &harbors)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(conditionDesc, errors.Wrap(err, "cannot list harbors"))
+		return
+	}
+
+	existing := make(map[types.NamespacedName]bool, len(harbors.Items))
+
+	for _, harbor := range harbors.Items {
+		key := types.NamespacedName{Namespace: harbor.GetNamespace(), Name: harbor.GetName()}
+		existing[key] = true
+
+		for _, conditionType := range conditionTypes {
+			if conditionType == goharborv1alpha1.FunctionalConditionType && harbor.Spec.SyntheticProbe == nil {
+				continue
+			}
+
+			value := 0.
+
+			for _, condition := range harbor.Status.Conditions {
+				if condition.Type == conditionType && condition.Status == corev1.ConditionTrue {
+					value = 1
+				}
+			}
+
+			ch <- prometheus.MustNewConstMetric(conditionDesc, prometheus.GaugeValue, value, key.Namespace, key.Name, string(conditionType))
+		}
+
+		health, ok := c.health.Load(key)
+		if !ok {
+			continue
+		}
+
+		for _, component := range health.(*APIHealth).Components {
+			value := 0.
+			if component.Status == HealthyStatus {
+				value = 1
+			}
+
+			ch <- prometheus.MustNewConstMetric(componentHealthDesc, prometheus.GaugeValue, value, key.Namespace, key.Name, component.Name)
+		}
+	}
+
+	c.health.Range(func(key, _ interface{}) bool {
+		if !existing[key.(types.NamespacedName)] {
+			c.health.Delete(key)
+		}
+
+		return true
+	})
+}
+
+// SetHealth records the health of the Harbor, nil when its API cannot be reached.
+func (c *Collector) SetHealth(harbor types.NamespacedName, health *APIHealth) {
+	if c == nil {
+		return
+	}
+
+	if health == nil {
+		c.health.Delete(harbor)
+		return
+	}
+
+	c.health.Store(harbor, health)
+}
+
+// GetUpgradeVersion returns the version of the Harbor and the images of its deployments, which change on upgrades.
+func GetUpgradeVersion(ctx context.Context, harbor *goharborv1alpha1.Harbor, harborResources *components.Components) (string, error) {
+	var lock sync.Mutex
+
+	images := map[string]bool{}
+
+	err := harborResources.ParallelRun(ctx, harbor, func(ctx context.Context, harbor *goharborv1alpha1.Harbor, component *components.ComponentRunner) error {
+		// Paused components keep their images
+		if harbor.IsComponentPaused(components.ComponentName(ctx)) {
+			return nil
+		}
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		for _, resource := range component.GetDeployments(ctx) {
+			deployment, ok := resource.(*appsv1.Deployment)
+			if !ok {
+				return errors.Errorf("unexpected resource %+v", resource)
+			}
+
+			for _, containers := range [][]corev1.Container{deployment.Spec.Template.Spec.InitContainers, deployment.Spec.Template.Spec.Containers} {
+				for _, container := range containers {
+					images[container.Image] = true
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	version := make([]string, 0, len(images)+1)
+	for image := range images {
+		version = append(version, image)
+	}
+
+	sort.Strings(version)
+
+	return strings.Join(append([]string{harbor.Spec.HarborVersion}, version...), ","), nil
+}
+
+// ObserveUpgrade tracks upgrades of the Harbor, until it is ready with its new version.
+func (r *Reconciler) ObserveUpgrade(ctx context.Context, harbor *goharborv1alpha1.Harbor, harborResources *components.Components) error {
+	version, err := GetUpgradeVersion(ctx, harbor, harborResources)
+	if err != nil {
+		return errors.Wrap(err, "cannot get version")
+	}
+
+	ready := r.GetConditionStatus(ctx, harbor, goharborv1alpha1.ReadyConditionType) == corev1.ConditionTrue
+
+	metrics.ObserveVersion(types.NamespacedName{Namespace: harbor.GetNamespace(), Name: harbor.GetName()}, version, ready, time.Now())
+
+	return nil
+}
@@ -0,0 +1,183 @@
+package harbor
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/pkg/metrics"
+)
+
+// harborLister lists harbors from memory.
+type harborLister struct {
+	harbors []goharborv1alpha1.Harbor
+}
+
+func (l *harborLister) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	return errors.New("not implemented")
+}
+
+func (l *harborLister) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+	list.(*goharborv1alpha1.HarborList).Items = l.harbors
+	return nil
+}
+
+// gatherGauges returns the value of the gauges of the family, by label values.
+func gatherGauges(registry prometheus.Gatherer, family string) map[string]float64 {
+	families, err := registry.Gather()
+	Expect(err).ToNot(HaveOccurred())
+
+	values := map[string]float64{}
+
+	for _, f := range families {
+		if f.GetName() != family {
+			continue
+		}
+
+		for _, metric := range f.GetMetric() {
+			values[labelValues(metric)] = metric.GetGauge().GetValue()
+		}
+	}
+
+	return values
+}
+
+func labelValues(metric *dto.Metric) string {
+	result := ""
+
+	for _, label := range metric.GetLabel() {
+		result += "/" + label.GetValue()
+	}
+
+	return result
+}
+
+var _ = Describe("metrics", func() {
+	var lister *harborLister
+	var collector *Collector
+	var registry *prometheus.Registry
+
+	key := types.NamespacedName{Namespace: "registry", Name: "my"}
+
+	BeforeEach(func() {
+		lister = &harborLister{
+			harbors: []goharborv1alpha1.Harbor{{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: key.Namespace,
+					Name:      key.Name,
+				},
+				Status: goharborv1alpha1.HarborStatus{
+					Conditions: []goharborv1alpha1.HarborCondition{{
+						Type:   goharborv1alpha1.AppliedConditionType,
+						Status: corev1.ConditionTrue,
+					}, {
+						Type:   goharborv1alpha1.ReadyConditionType,
+						Status: corev1.ConditionFalse,
+					}},
+				},
+			}},
+		}
+
+		collector = NewCollector(lister)
+
+		registry = prometheus.NewPedanticRegistry()
+		Expect(registry.Register(collector)).To(Succeed())
+	})
+
+	It("Should expose the conditions", func() {
+		Expect(gatherGauges(registry, "harbor_operator_harbor_condition")).To(Equal(map[string]float64{
+			"/my/registry/Applied": 1,
+			"/my/registry/Ready":   0,
+		}))
+	})
+
+	It("Should expose the health of the components", func() {
+		collector.SetHealth(key, &APIHealth{
+			Status: UnhealthyStatus,
+			Components: []ComponentHealth{
+				{Name: "core", Status: HealthyStatus},
+				{Name: "registry", Status: UnhealthyStatus},
+			},
+		})
+
+		Expect(gatherGauges(registry, "harbor_operator_harbor_component_healthy")).To(Equal(map[string]float64{
+			"/core/my/registry":     1,
+			"/registry/my/registry": 0,
+		}))
+
+		collector.SetHealth(key, nil)
+
+		Expect(gatherGauges(registry, "harbor_operator_harbor_component_healthy")).To(BeEmpty())
+	})
+
+	It("Should forget deleted harbors", func() {
+		collector.SetHealth(key, &APIHealth{
+			Status:     HealthyStatus,
+			Components: []ComponentHealth{{Name: "core", Status: HealthyStatus}},
+		})
+
+		lister.harbors = nil
+
+		Expect(gatherGauges(registry, "harbor_operator_harbor_condition")).To(BeEmpty())
+
+		_, ok := collector.health.Load(key)
+		Expect(ok).To(BeFalse())
+	})
+})
+
+var _ = Describe("upgrade metrics", func() {
+	key := types.NamespacedName{Namespace: "registry", Name: "upgraded"}
+	start := time.Now()
+
+	AfterEach(func() {
+		metrics.Forget(key)
+	})
+
+	inProgress := func() float64 {
+		return gatherGauges(ctrlmetrics.Registry, "harbor_operator_upgrade_in_progress")["/upgraded/registry"]
+	}
+
+	It("Should not consider the first version as an upgrade", func() {
+		metrics.ObserveVersion(key, "v1", false, start)
+
+		Expect(gatherGauges(ctrlmetrics.Registry, "harbor_operator_upgrade_in_progress")).To(HaveKeyWithValue("/upgraded/registry", 0.))
+	})
+
+	It("Should time upgrades until the Harbor is ready", func() {
+		metrics.ObserveVersion(key, "v1", true, start)
+
+		metrics.ObserveVersion(key, "v2", true, start.Add(time.Second))
+		Expect(inProgress()).To(Equal(1.))
+
+		metrics.ObserveVersion(key, "v2", false, start.Add(2*time.Second))
+		Expect(inProgress()).To(Equal(1.))
+
+		metrics.ObserveVersion(key, "v2", true, start.Add(time.Minute))
+		Expect(inProgress()).To(Equal(0.))
+
+		families, err := ctrlmetrics.Registry.Gather()
+		Expect(err).ToNot(HaveOccurred())
+
+		var sum float64
+		for _, family := range families {
+			if family.GetName() == "harbor_operator_upgrade_duration_seconds" {
+				sum = family.GetMetric()[0].GetHistogram().GetSampleSum()
+			}
+		}
+
+		Expect(sum).To(Equal(59.))
+	})
+})
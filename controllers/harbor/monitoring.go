@@ -0,0 +1,42 @@
+package harbor
+
+import (
+	"context"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components"
+	"github.com/goharbor/harbor-operator/pkg/factories/logger"
+)
+
+// WithMonitoringAPI skips prometheus-operator resources when their CRD is not installed.
+func (r *Reconciler) WithMonitoringAPI(available bool, run components.ComponentRun) components.ComponentRun {
+	return func(ctx context.Context, harbor *goharborv1alpha1.Harbor, resources []components.Resource) error {
+		if len(resources) == 0 {
+			return nil
+		}
+
+		if !available {
+			logger.Get(ctx).Info("prometheus-operator API not available, skipping")
+			return nil
+		}
+
+		return run(ctx, harbor, resources)
+	}
+}
+
+// +kubebuilder:rbac:groups="monitoring.coreos.com",resources="servicemonitors",verbs=get;list;watch;update;patch;create;delete
+// +kubebuilder:rbac:groups="monitoring.coreos.com",resources="prometheusrules",verbs=get;list;watch;update;patch;create;delete
+
+// ApplyMonitoring applies the ServiceMonitors and PrometheusRules of the component.
+func (r *Reconciler) ApplyMonitoring(ctx context.Context, harbor *goharborv1alpha1.Harbor, component *components.ComponentRunner) error {
+	return component.MonitoringParallelRun(ctx, harbor,
+		r.WithMonitoringAPI(r.serviceMonitorAPIAvailable, r.ApplyResources),
+		r.WithMonitoringAPI(r.prometheusRuleAPIAvailable, r.ApplyResources))
+}
+
+// CreateMonitoring creates the missing ServiceMonitors and PrometheusRules of the component.
+func (r *Reconciler) CreateMonitoring(ctx context.Context, harbor *goharborv1alpha1.Harbor, component *components.ComponentRunner) error {
+	return component.MonitoringParallelRun(ctx, harbor,
+		r.WithMonitoringAPI(r.serviceMonitorAPIAvailable, r.CreateResources),
+		r.WithMonitoringAPI(r.prometheusRuleAPIAvailable, r.CreateResources))
+}
@@ -0,0 +1,66 @@
+package harbor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components"
+	"github.com/goharbor/harbor-operator/pkg/factories/logger"
+)
+
+// Reasons of the Paused condition.
+const (
+	HarborPausedReason     = "HarborPaused"
+	ComponentsPausedReason = "ComponentsPaused"
+)
+
+// UpdatePausedStatus sets the Paused condition, which is removed when nothing is paused.
+func (r *Reconciler) UpdatePausedStatus(ctx context.Context, harbor *goharborv1alpha1.Harbor) error {
+	wasPaused := r.GetConditionStatus(ctx, harbor, goharborv1alpha1.PausedConditionType) == corev1.ConditionTrue
+
+	var reason, message string
+
+	switch {
+	case harbor.Spec.Paused:
+		reason = HarborPausedReason
+		message = "Resources are neither created, updated nor deleted"
+	case len(harbor.Spec.PausedComponents) > 0:
+		names := make([]string, len(harbor.Spec.PausedComponents))
+		for i, component := range harbor.Spec.PausedComponents {
+			names[i] = string(component)
+		}
+
+		reason = ComponentsPausedReason
+		message = fmt.Sprintf("Resources of %s are neither created, updated nor deleted", strings.Join(names, ", "))
+	default:
+		if wasPaused {
+			r.Recorder.Event(harbor, corev1.EventTypeNormal, ResumedReason, "Reconciliation resumed")
+		}
+
+		r.RemoveCondition(ctx, harbor, goharborv1alpha1.PausedConditionType)
+
+		return nil
+	}
+
+	condition := r.GetCondition(ctx, harbor, goharborv1alpha1.PausedConditionType)
+	if !wasPaused || condition.Reason != reason || condition.Message != message {
+		r.Recorder.Event(harbor, corev1.EventTypeNormal, PausedReason, message)
+	}
+
+	return r.UpdateCondition(ctx, harbor, goharborv1alpha1.PausedConditionType, corev1.ConditionTrue, reason, message)
+}
+
+// isComponentPaused returns true, and logs it, if the component of the context is paused.
+func isComponentPaused(ctx context.Context, harbor *goharborv1alpha1.Harbor) bool {
+	if !harbor.IsComponentPaused(components.ComponentName(ctx)) {
+		return false
+	}
+
+	logger.Get(ctx).V(1).Info("component is paused")
+
+	return true
+}
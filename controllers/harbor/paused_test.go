@@ -0,0 +1,85 @@
+package harbor
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+)
+
+var _ = Describe("Paused", func() {
+	var r *Reconciler
+	var ctx context.Context
+	var harbor *goharborv1alpha1.Harbor
+
+	BeforeEach(func() {
+		r, ctx = setupTest(context.TODO())
+
+		harbor = &goharborv1alpha1.Harbor{}
+		harbor.SetGeneration(2)
+		harbor.Status.ObservedGeneration = 1
+	})
+
+	It("Should pause every component of a paused harbor", func() {
+		harbor.Spec.Paused = true
+
+		Expect(harbor.IsComponentPaused(goharborv1alpha1.CoreName)).To(BeTrue())
+		Expect(harbor.IsComponentPaused(goharborv1alpha1.MonitoringName)).To(BeTrue())
+	})
+
+	It("Should pause only listed components", func() {
+		harbor.Spec.PausedComponents = []goharborv1alpha1.PausableComponent{goharborv1alpha1.RegistryName}
+
+		Expect(harbor.IsComponentPaused(goharborv1alpha1.RegistryName)).To(BeTrue())
+		Expect(harbor.IsComponentPaused(goharborv1alpha1.CoreName)).To(BeFalse())
+	})
+
+	It("Should skip the new generation of a paused harbor", func() {
+		harbor.Spec.Paused = true
+
+		// No client is set, any apply would panic
+		rec := &reconciliation{harbor: harbor, result: &ctrl.Result{}}
+		Expect(r.validatePhase(ctx, rec)).To(Succeed())
+		Expect(rec.skipApply).To(BeTrue())
+		Expect(r.renderPhase(ctx, rec)).To(Succeed())
+		Expect(r.applyPhase(ctx, rec)).To(Succeed())
+		Expect(r.prunePhase(ctx, rec)).To(Succeed())
+		Expect(harbor.Status.ObservedGeneration).To(BeEquivalentTo(1))
+
+		condition := r.GetCondition(ctx, harbor, goharborv1alpha1.PausedConditionType)
+		Expect(condition.Status).To(Equal(corev1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(HarborPausedReason))
+		Expect(r.GetConditionStatus(ctx, harbor, goharborv1alpha1.AppliedConditionType)).To(Equal(corev1.ConditionUnknown))
+	})
+
+	It("Should list paused components", func() {
+		harbor.Spec.PausedComponents = []goharborv1alpha1.PausableComponent{goharborv1alpha1.RegistryName, goharborv1alpha1.NotaryName}
+
+		Expect(r.UpdatePausedStatus(ctx, harbor)).To(Succeed())
+
+		condition := r.GetCondition(ctx, harbor, goharborv1alpha1.PausedConditionType)
+		Expect(condition.Reason).To(Equal(ComponentsPausedReason))
+		Expect(condition.Message).To(ContainSubstring("registry, notary"))
+	})
+
+	It("Should remove the condition once resumed", func() {
+		harbor.Spec.Paused = true
+		Expect(r.UpdatePausedStatus(ctx, harbor)).To(Succeed())
+
+		harbor.Spec.Paused = false
+		Expect(r.UpdatePausedStatus(ctx, harbor)).To(Succeed())
+
+		Expect(harbor.Status.Conditions).To(BeEmpty())
+
+		events := r.Recorder.(*record.FakeRecorder).Events
+		Expect(events).To(HaveLen(2))
+		Expect(<-events).To(HavePrefix("Normal Paused"))
+		Expect(<-events).To(HavePrefix("Normal Resumed"))
+	})
+})
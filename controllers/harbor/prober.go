@@ -0,0 +1,227 @@
+package harbor
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+)
+
+const (
+	DefaultHealthProbeInterval = 30 * time.Second
+	DefaultHealthProbeTimeout  = 5 * time.Second
+
+	healthEventsBufferSize = 1024
+)
+
+type HealthProbeMode string
+
+const (
+	// ProxyHealthProbeMode calls the health API through the API server proxy.
+	ProxyHealthProbeMode HealthProbeMode = "proxy"
+	// DirectHealthProbeMode calls the health API on the core service, the operator must run in the cluster.
+	DirectHealthProbeMode HealthProbeMode = "direct"
+)
+
+type HealthProbeConfig struct {
+	Mode     HealthProbeMode
+	Interval time.Duration
+	Timeout  time.Duration
+}
+
+// HealthResult is the result of the last probe of a Harbor.
+type HealthResult struct {
+	Health    *APIHealth
+	Err       error
+	ProbeTime time.Time
+}
+
+// state returns what changes the Ready condition: the status of the Harbor and of each component.
+func (r *HealthResult) state() map[string]string {
+	if r.Err != nil {
+		return map[string]string{"": "error"}
+	}
+
+	state := map[string]string{"": r.Health.Status}
+
+	for _, component := range r.Health.Components {
+		state[component.Name] = component.Status
+	}
+
+	return state
+}
+
+// HealthProber periodically probes every Harbor in the background and caches results,
+// so reconciliations are not blocked by unresponsive Harbors.
+// Harbors are enqueued through Source when their health changes.
+type HealthProber struct {
+	client client.Reader
+	probe  HealthProbe
+	filter *EventFilter
+	log    logr.Logger
+
+	interval time.Duration
+	timeout  time.Duration
+
+	lock    sync.RWMutex
+	results map[types.NamespacedName]*HealthResult
+
+	events chan event.GenericEvent
+}
+
+var _ manager.Runnable = &HealthProber{}
+
+func NewHealthProber(reader client.Reader, probe HealthProbe, filter *EventFilter, log logr.Logger, config HealthProbeConfig) *HealthProber {
+	if config.Interval <= 0 {
+		config.Interval = DefaultHealthProbeInterval
+	}
+
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultHealthProbeTimeout
+	}
+
+	return &HealthProber{
+		client:   reader,
+		probe:    probe,
+		filter:   filter,
+		log:      log,
+		interval: config.Interval,
+		timeout:  config.Timeout,
+		results:  map[types.NamespacedName]*HealthResult{},
+		events:   make(chan event.GenericEvent, healthEventsBufferSize),
+	}
+}
+
+// Source enqueues Harbors whose health changed.
+func (p *HealthProber) Source() source.Source {
+	return &source.Channel{Source: p.events}
+}
+
+// Get returns the result of the last probe of the Harbor, nil if not probed yet.
+func (p *HealthProber) Get(harbor types.NamespacedName) *HealthResult {
+	if p == nil {
+		return nil
+	}
+
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	return p.results[harbor]
+}
+
+// Start probes Harbors every interval until stop is closed.
+func (p *HealthProber) Start(stop <-chan struct{}) error {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		<-stop
+		cancel()
+	}()
+
+	for {
+		err := p.ProbeAll(ctx)
+		if err != nil {
+			p.log.Error(err, "cannot probe harbors")
+		}
+
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProbeAll probes every Harbor concurrently and forgets deleted ones.
+func (p *HealthProber) ProbeAll(ctx context.Context) error {
+	var harbors goharborv1alpha1.HarborList
+
+	err := p.client.List(ctx, &harbors)
+	if err != nil {
+		return errors.Wrap(err, "cannot list harbors")
+	}
+
+	existing := make(map[types.NamespacedName]bool, len(harbors.Items))
+
+	var wg sync.WaitGroup
+
+	for i := range harbors.Items {
+		harbor := &harbors.Items[i]
+
+		if !harbor.ObjectMeta.DeletionTimestamp.IsZero() || !p.filter.IsManaged(harbor) {
+			continue
+		}
+
+		existing[types.NamespacedName{Namespace: harbor.GetNamespace(), Name: harbor.GetName()}] = true
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			p.Probe(ctx, harbor)
+		}()
+	}
+
+	wg.Wait()
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for key := range p.results {
+		if !existing[key] {
+			delete(p.results, key)
+		}
+	}
+
+	return nil
+}
+
+// Probe probes the Harbor and enqueues it if its health changed.
+func (p *HealthProber) Probe(ctx context.Context, harbor *goharborv1alpha1.Harbor) {
+	probeCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	health, err := p.probe(probeCtx, harbor)
+
+	result := &HealthResult{
+		Health:    health,
+		Err:       err,
+		ProbeTime: time.Now(),
+	}
+
+	key := types.NamespacedName{Namespace: harbor.GetNamespace(), Name: harbor.GetName()}
+
+	p.lock.Lock()
+	previous, ok := p.results[key]
+	p.results[key] = result
+	p.lock.Unlock()
+
+	if ok && reflect.DeepEqual(previous.state(), result.state()) {
+		return
+	}
+
+	p.log.V(1).Info("health changed", "Harbor", key)
+
+	select {
+	case p.events <- event.GenericEvent{
+		Meta:   harbor,
+		Object: harbor,
+	}:
+	case <-ctx.Done():
+	}
+}
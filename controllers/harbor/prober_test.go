@@ -0,0 +1,101 @@
+package harbor
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+)
+
+var _ = Describe("HealthProber", func() {
+	var lister *harborLister
+	var prober *HealthProber
+	var health *APIHealth
+	var probeErr error
+	var ctx context.Context
+
+	key := types.NamespacedName{Namespace: "registry", Name: "my"}
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+
+		lister = &harborLister{
+			harbors: []goharborv1alpha1.Harbor{{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: key.Namespace,
+					Name:      key.Name,
+				},
+			}},
+		}
+
+		health = &APIHealth{
+			Status:     HealthyStatus,
+			Components: []ComponentHealth{{Name: "core", Status: HealthyStatus}},
+		}
+		probeErr = nil
+
+		probe := func(ctx context.Context, harbor *goharborv1alpha1.Harbor) (*APIHealth, error) {
+			return health, probeErr
+		}
+
+		prober = NewHealthProber(lister, probe, &EventFilter{}, zap.LoggerTo(GinkgoWriter, true), HealthProbeConfig{})
+	})
+
+	It("Should cache results", func() {
+		Expect(prober.Get(key)).To(BeNil())
+
+		Expect(prober.ProbeAll(ctx)).To(Succeed())
+
+		result := prober.Get(key)
+		Expect(result).ToNot(BeNil())
+		Expect(result.Err).ToNot(HaveOccurred())
+		Expect(result.Health.IsHealthy()).To(BeTrue())
+	})
+
+	It("Should enqueue the harbor only when its health changes", func() {
+		Expect(prober.ProbeAll(ctx)).To(Succeed())
+		Expect(prober.events).To(HaveLen(1))
+
+		Expect(prober.ProbeAll(ctx)).To(Succeed())
+		Expect(prober.events).To(HaveLen(1))
+
+		health = &APIHealth{
+			Status:     UnhealthyStatus,
+			Components: []ComponentHealth{{Name: "core", Status: UnhealthyStatus, Error: "database"}},
+		}
+
+		Expect(prober.ProbeAll(ctx)).To(Succeed())
+		Expect(prober.events).To(HaveLen(2))
+
+		probeErr = errors.New("timeout")
+
+		Expect(prober.ProbeAll(ctx)).To(Succeed())
+		Expect(prober.events).To(HaveLen(3))
+
+		event := <-prober.events
+		Expect(event.Meta.GetName()).To(Equal(key.Name))
+	})
+
+	It("Should forget deleted harbors", func() {
+		Expect(prober.ProbeAll(ctx)).To(Succeed())
+
+		lister.harbors = nil
+
+		Expect(prober.ProbeAll(ctx)).To(Succeed())
+		Expect(prober.Get(key)).To(BeNil())
+	})
+
+	It("Should skip harbors of other classes", func() {
+		lister.harbors[0].SetAnnotations(map[string]string{goharborv1alpha1.HarborClassAnnotation: "other"})
+
+		Expect(prober.ProbeAll(ctx)).To(Succeed())
+		Expect(prober.Get(key)).To(BeNil())
+	})
+})
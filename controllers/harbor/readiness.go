@@ -0,0 +1,312 @@
+package harbor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	certv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha2"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+)
+
+// Reasons of the Ready condition when the Harbor is not ready, from the most to the least likely root cause.
+const (
+	CertificateNotReadyReason      = "CertificateNotReady"
+	ImagePullErrorReason           = "ImagePullError"
+	ContainerErrorReason           = "ContainerError"
+	ProgressDeadlineExceededReason = "ProgressDeadlineExceeded"
+	DeploymentUnavailableReason    = "DeploymentUnavailable"
+	HealthUnavailableReason        = "HealthUnavailable"
+	ComponentUnhealthyReason       = "ComponentUnhealthy"
+)
+
+var (
+	imagePullErrors = map[string]bool{
+		"ErrImagePull":     true,
+		"ImagePullBackOff": true,
+		"InvalidImageName": true,
+	}
+
+	containerErrors = map[string]bool{
+		"CrashLoopBackOff":           true,
+		"CreateContainerConfigError": true,
+		"CreateContainerError":       true,
+		"RunContainerError":          true,
+	}
+
+	reasonPriorities = map[string]int{
+		CertificateNotReadyReason:      0,
+		ImagePullErrorReason:           1,
+		ContainerErrorReason:           2,
+		ProgressDeadlineExceededReason: 3,
+		DeploymentUnavailableReason:    4,
+		HealthUnavailableReason:        5,
+		ComponentUnhealthyReason:       6,
+	}
+)
+
+// ReadinessIssue is a reason why the Harbor is not ready.
+type ReadinessIssue struct {
+	Reason    string
+	Component string
+	// Resource is the kind and name of the failing resource, empty for Harbor API issues.
+	Resource string
+	Message  string
+}
+
+func (i ReadinessIssue) String() string {
+	if i.Resource == "" {
+		return fmt.Sprintf("%s: %s", i.Component, i.Message)
+	}
+
+	return fmt.Sprintf("%s: %s: %s", i.Component, i.Resource, i.Message)
+}
+
+// IsPodIssue returns true if the issue was found on pods rather than on watched resources.
+func (i ReadinessIssue) IsPodIssue() bool {
+	return i.Reason == ImagePullErrorReason || i.Reason == ContainerErrorReason
+}
+
+// IsHealthIssue returns true if the issue is reported by the health prober, which enqueues the Harbor when the health changes.
+func (i ReadinessIssue) IsHealthIssue() bool {
+	return i.Reason == HealthUnavailableReason || i.Reason == ComponentUnhealthyReason
+}
+
+// SortReadinessIssues sorts issues so the most likely root cause comes first.
+func SortReadinessIssues(issues []ReadinessIssue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		return reasonPriorities[issues[i].Reason] < reasonPriorities[issues[j].Reason]
+	})
+}
+
+// GetReadinessMessage lists the issues, root cause first.
+func GetReadinessMessage(issues []ReadinessIssue) string {
+	messages := make([]string, len(issues))
+
+	for i, issue := range issues {
+		messages[i] = issue.String()
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+func getComponentName(object metav1.Object) string {
+	if component, ok := object.GetLabels()[goharborv1alpha1.ComponentNameLabel]; ok {
+		return component
+	}
+
+	return object.GetName()
+}
+
+// getCertificateIssue returns the issue of a certificate which is not ready, nil otherwise.
+func getCertificateIssue(certificate *certv1.Certificate) *ReadinessIssue {
+	for _, condition := range certificate.Status.Conditions {
+		if condition.Type != certv1.CertificateConditionReady {
+			continue
+		}
+
+		if condition.Status == cmmeta.ConditionTrue {
+			return nil
+		}
+
+		return &ReadinessIssue{
+			Reason:    CertificateNotReadyReason,
+			Component: getComponentName(certificate),
+			Resource:  fmt.Sprintf("Certificate %s", certificate.GetName()),
+			Message:   fmt.Sprintf("%s: %s", condition.Reason, condition.Message),
+		}
+	}
+
+	return &ReadinessIssue{
+		Reason:    CertificateNotReadyReason,
+		Component: getComponentName(certificate),
+		Resource:  fmt.Sprintf("Certificate %s", certificate.GetName()),
+		Message:   "not issued yet",
+	}
+}
+
+// getDeploymentIssue returns the issue of a deployment which is not available, nil otherwise.
+// Pod statuses are used to find image pull and container errors.
+func getDeploymentIssue(deployment *appsv1.Deployment, pods []corev1.Pod) *ReadinessIssue {
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+
+	issue := &ReadinessIssue{
+		Component: getComponentName(deployment),
+		Resource:  fmt.Sprintf("Deployment %s", deployment.GetName()),
+	}
+
+	for _, pod := range pods {
+		for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+			if status.State.Waiting == nil {
+				continue
+			}
+
+			reason := ""
+
+			switch {
+			case imagePullErrors[status.State.Waiting.Reason]:
+				reason = ImagePullErrorReason
+			case containerErrors[status.State.Waiting.Reason]:
+				reason = ContainerErrorReason
+			default:
+				continue
+			}
+
+			issue.Reason = reason
+			issue.Message = fmt.Sprintf("pod %s, container %s: %s: %s", pod.GetName(), status.Name, status.State.Waiting.Reason, status.State.Waiting.Message)
+
+			if reason == ImagePullErrorReason {
+				return issue
+			}
+		}
+	}
+
+	if issue.Reason != "" {
+		return issue
+	}
+
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Status == corev1.ConditionFalse && condition.Reason == ProgressDeadlineExceededReason {
+			issue.Reason = ProgressDeadlineExceededReason
+			issue.Message = condition.Message
+
+			return issue
+		}
+	}
+
+	if deployment.Status.AvailableReplicas < replicas || deployment.Status.UpdatedReplicas < replicas || deployment.Status.ObservedGeneration < deployment.GetGeneration() {
+		issue.Reason = DeploymentUnavailableReason
+		issue.Message = fmt.Sprintf("%d/%d replicas available, %d updated", deployment.Status.AvailableReplicas, replicas, deployment.Status.UpdatedReplicas)
+
+		return issue
+	}
+
+	return nil
+}
+
+// getHealthIssues returns the issues reported by the Harbor API.
+func getHealthIssues(result *HealthResult) []ReadinessIssue {
+	if result.Err != nil {
+		return []ReadinessIssue{{
+			Reason:    HealthUnavailableReason,
+			Component: goharborv1alpha1.CoreName,
+			Message:   fmt.Sprintf("cannot get health: %v", result.Err),
+		}}
+	}
+
+	var issues []ReadinessIssue
+
+	for _, component := range result.Health.Components {
+		if component.Status == HealthyStatus {
+			continue
+		}
+
+		message := "unhealthy"
+		if component.Error != "" {
+			message = fmt.Sprintf("unhealthy: %s", component.Error)
+		}
+
+		issues = append(issues, ReadinessIssue{
+			Reason:    ComponentUnhealthyReason,
+			Component: component.Name,
+			Message:   message,
+		})
+	}
+
+	if len(issues) == 0 && !result.Health.IsHealthy() {
+		issues = append(issues, ReadinessIssue{
+			Reason:    ComponentUnhealthyReason,
+			Component: goharborv1alpha1.CoreName,
+			Message:   fmt.Sprintf("status %s", result.Health.Status),
+		})
+	}
+
+	return issues
+}
+
+// GetWorkloadIssues returns the issues of certificates and deployments controlled by the Harbor.
+func (r *Reconciler) GetWorkloadIssues(ctx context.Context, harbor *goharborv1alpha1.Harbor) ([]ReadinessIssue, error) {
+	var issues []ReadinessIssue
+
+	certificates := &certv1.CertificateList{}
+
+	err := r.Client.List(ctx, certificates, client.InNamespace(harbor.GetNamespace()))
+	if err != nil && !meta.IsNoMatchError(err) {
+		return nil, errors.Wrap(err, "cannot list certificates")
+	}
+
+	for i := range certificates.Items {
+		certificate := &certificates.Items[i]
+
+		if !metav1.IsControlledBy(certificate, harbor) {
+			continue
+		}
+
+		if issue := getCertificateIssue(certificate); issue != nil {
+			issues = append(issues, *issue)
+		}
+	}
+
+	deployments := &appsv1.DeploymentList{}
+
+	err = r.Client.List(ctx, deployments, client.InNamespace(harbor.GetNamespace()))
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot list deployments")
+	}
+
+	for i := range deployments.Items {
+		deployment := &deployments.Items[i]
+
+		if !metav1.IsControlledBy(deployment, harbor) {
+			continue
+		}
+
+		if getDeploymentIssue(deployment, nil) == nil {
+			continue
+		}
+
+		pods, err := r.getDeploymentPods(ctx, deployment)
+		if err != nil {
+			return nil, err
+		}
+
+		if issue := getDeploymentIssue(deployment, pods); issue != nil {
+			issues = append(issues, *issue)
+		}
+	}
+
+	return issues, nil
+}
+
+// +kubebuilder:rbac:groups="",resources="pods",verbs=list
+
+// getDeploymentPods lists pods of the deployment without caching pods of the whole cluster.
+func (r *Reconciler) getDeploymentPods(ctx context.Context, deployment *appsv1.Deployment) ([]corev1.Pod, error) {
+	reader := r.APIReader
+	if reader == nil {
+		reader = r.Client
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid selector of deployment %s", deployment.GetName())
+	}
+
+	pods := &corev1.PodList{}
+
+	err = reader.List(ctx, pods, client.InNamespace(deployment.GetNamespace()), client.MatchingLabelsSelector{Selector: selector})
+
+	return pods.Items, errors.Wrapf(err, "cannot list pods of deployment %s", deployment.GetName())
+}
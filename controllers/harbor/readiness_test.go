@@ -0,0 +1,189 @@
+package harbor
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	certv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha2"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+)
+
+var _ = Describe("Readiness", func() {
+	Context("Certificates", func() {
+		var certificate *certv1.Certificate
+
+		BeforeEach(func() {
+			certificate = &certv1.Certificate{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "my-harbor-public",
+					Labels: map[string]string{goharborv1alpha1.ComponentNameLabel: "core"},
+				},
+			}
+		})
+
+		It("Should report certificates not issued yet", func() {
+			issue := getCertificateIssue(certificate)
+			Expect(issue).ToNot(BeNil())
+			Expect(issue.Reason).To(Equal(CertificateNotReadyReason))
+			Expect(issue.String()).To(Equal("core: Certificate my-harbor-public: not issued yet"))
+		})
+
+		It("Should report the reason of failing certificates", func() {
+			certificate.Status.Conditions = []certv1.CertificateCondition{{
+				Type:    certv1.CertificateConditionReady,
+				Status:  cmmeta.ConditionFalse,
+				Reason:  "Failed",
+				Message: "issuer not found",
+			}}
+
+			Expect(getCertificateIssue(certificate).Message).To(Equal("Failed: issuer not found"))
+		})
+
+		It("Should ignore ready certificates", func() {
+			certificate.Status.Conditions = []certv1.CertificateCondition{{
+				Type:   certv1.CertificateConditionReady,
+				Status: cmmeta.ConditionTrue,
+			}}
+
+			Expect(getCertificateIssue(certificate)).To(BeNil())
+		})
+	})
+
+	Context("Deployments", func() {
+		var deployment *appsv1.Deployment
+
+		BeforeEach(func() {
+			replicas := int32(2)
+
+			deployment = &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "my-harbor-registry",
+					Generation: 2,
+					Labels:     map[string]string{goharborv1alpha1.ComponentNameLabel: "registry"},
+				},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+				},
+				Status: appsv1.DeploymentStatus{
+					ObservedGeneration: 2,
+					UpdatedReplicas:    2,
+					AvailableReplicas:  2,
+				},
+			}
+		})
+
+		It("Should ignore available deployments", func() {
+			Expect(getDeploymentIssue(deployment, nil)).To(BeNil())
+		})
+
+		It("Should report unavailable replicas", func() {
+			deployment.Status.AvailableReplicas = 1
+
+			issue := getDeploymentIssue(deployment, nil)
+			Expect(issue).ToNot(BeNil())
+			Expect(issue.Reason).To(Equal(DeploymentUnavailableReason))
+			Expect(issue.String()).To(Equal("registry: Deployment my-harbor-registry: 1/2 replicas available, 2 updated"))
+		})
+
+		It("Should report rollouts not observed yet", func() {
+			deployment.Generation = 3
+
+			Expect(getDeploymentIssue(deployment, nil).Reason).To(Equal(DeploymentUnavailableReason))
+		})
+
+		It("Should report exceeded progress deadlines", func() {
+			deployment.Status.AvailableReplicas = 1
+			deployment.Status.Conditions = []appsv1.DeploymentCondition{{
+				Type:    appsv1.DeploymentProgressing,
+				Status:  corev1.ConditionFalse,
+				Reason:  ProgressDeadlineExceededReason,
+				Message: "ReplicaSet has timed out progressing.",
+			}}
+
+			Expect(getDeploymentIssue(deployment, nil).Reason).To(Equal(ProgressDeadlineExceededReason))
+		})
+
+		It("Should report image pull errors before container errors", func() {
+			deployment.Status.AvailableReplicas = 0
+
+			pods := []corev1.Pod{{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-harbor-registry-1"},
+				Status: corev1.PodStatus{
+					ContainerStatuses: []corev1.ContainerStatus{{
+						Name:  "registryctl",
+						State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+					}, {
+						Name:  "registry",
+						State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "Back-off pulling image"}},
+					}},
+				},
+			}}
+
+			issue := getDeploymentIssue(deployment, pods)
+			Expect(issue.Reason).To(Equal(ImagePullErrorReason))
+			Expect(issue.IsPodIssue()).To(BeTrue())
+			Expect(issue.Message).To(Equal("pod my-harbor-registry-1, container registry: ImagePullBackOff: Back-off pulling image"))
+		})
+
+		It("Should report container errors", func() {
+			pods := []corev1.Pod{{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-harbor-registry-1"},
+				Status: corev1.PodStatus{
+					InitContainerStatuses: []corev1.ContainerStatus{{
+						Name:  "init",
+						State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CreateContainerConfigError"}},
+					}},
+				},
+			}}
+
+			Expect(getDeploymentIssue(deployment, pods).Reason).To(Equal(ContainerErrorReason))
+		})
+	})
+
+	Context("Health", func() {
+		It("Should report probe errors", func() {
+			issues := getHealthIssues(&HealthResult{Err: errors.New("connection refused")})
+			Expect(issues).To(HaveLen(1))
+			Expect(issues[0].Reason).To(Equal(HealthUnavailableReason))
+		})
+
+		It("Should report unhealthy components", func() {
+			issues := getHealthIssues(&HealthResult{Health: &APIHealth{
+				Status: UnhealthyStatus,
+				Components: []ComponentHealth{
+					{Name: "core", Status: HealthyStatus},
+					{Name: "registry", Status: UnhealthyStatus, Error: "dial tcp: i/o timeout"},
+				},
+			}})
+			Expect(issues).To(HaveLen(1))
+			Expect(issues[0].String()).To(Equal("registry: unhealthy: dial tcp: i/o timeout"))
+		})
+
+		It("Should not report healthy Harbors", func() {
+			Expect(getHealthIssues(&HealthResult{Health: &APIHealth{Status: HealthyStatus}})).To(BeEmpty())
+		})
+	})
+
+	Context("Message", func() {
+		It("Should put the root cause first", func() {
+			issues := []ReadinessIssue{
+				{Reason: ComponentUnhealthyReason, Component: "registry", Message: "unhealthy"},
+				{Reason: DeploymentUnavailableReason, Component: "registry", Resource: "Deployment my-harbor-registry", Message: "0/1 replicas available, 1 updated"},
+				{Reason: ImagePullErrorReason, Component: "registry", Resource: "Deployment my-harbor-registry", Message: "ErrImagePull"},
+			}
+
+			SortReadinessIssues(issues)
+
+			Expect(issues[0].Reason).To(Equal(ImagePullErrorReason))
+			Expect(GetReadinessMessage(issues)).To(Equal("registry: Deployment my-harbor-registry: ErrImagePull; " +
+				"registry: Deployment my-harbor-registry: 0/1 replicas available, 1 updated; " +
+				"registry: unhealthy"))
+		})
+	})
+})
@@ -2,27 +2,96 @@ package harbor
 
 import (
 	"context"
-	"fmt"
+	"time"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/log"
 	"github.com/pkg/errors"
-	"golang.org/x/sync/errgroup"
 	corev1 "k8s.io/api/core/v1"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/render"
 	"github.com/goharbor/harbor-operator/pkg/factories/application"
 	"github.com/goharbor/harbor-operator/pkg/factories/logger"
+	"github.com/goharbor/harbor-operator/pkg/metrics"
 )
 
+const (
+	// ConfigurationErrorReason is the reason of the Applied condition when configurations cannot be rendered.
+	ConfigurationErrorReason = "ConfigurationError"
+	// InvalidSpecReason is the reason of the Applied condition when the spec cannot be applied.
+	InvalidSpecReason = "InvalidSpec"
+)
+
+const (
+	// DefaultReconcileTimeout is the deadline of a reconciliation, before the status is written.
+	DefaultReconcileTimeout = 5 * time.Minute
+	// StatusPatchTimeout is the deadline of the status write, which runs even once the reconciliation timed out.
+	StatusPatchTimeout = 10 * time.Second
+)
+
+// Phase is a step of the reconciliation of a Harbor.
+type Phase string
+
+const (
+	ValidatePhase Phase = "validate"
+	RenderPhase   Phase = "render"
+	ApplyPhase    Phase = "apply"
+	PrunePhase    Phase = "prune"
+	ObservePhase  Phase = "observe"
+)
+
+// reconciliation is the state shared by the phases of a single reconciliation.
+// Phases run sequentially, so it is never accessed concurrently.
+type reconciliation struct {
+	harbor *goharborv1alpha1.Harbor
+	result *ctrl.Result
+
+	// skipApply is set when resources must not be changed, because the Harbor is paused or cannot be applied.
+	skipApply bool
+	// resync is set when every component must be applied, whatever its desired state hash.
+	resync bool
+
+	// components and the hashes of their desired state are set by the render phase.
+	components *components.Components
+	hashes     map[string]string
+
+	// applied is set once every component is applied, resources no longer desired can then be pruned.
+	applied bool
+	// drifts are the drifts reported while applying.
+	drifts []Drift
+}
+
+type phaseFunc func(ctx context.Context, rec *reconciliation) error
+
+type phase struct {
+	name Phase
+	run  phaseFunc
+}
+
+// getPhases returns the phases of a reconciliation, in their order.
+func (r *Reconciler) getPhases() []phase {
+	return []phase{
+		{ValidatePhase, r.validatePhase},
+		{RenderPhase, r.renderPhase},
+		{ApplyPhase, r.applyPhase},
+		{PrunePhase, r.prunePhase},
+		{ObservePhase, r.observePhase},
+	}
+}
+
 // +kubebuilder:rbac:groups=goharbor.io,resources=harbors,verbs=get;list;watch
 // +kubebuilder:rbac:groups=goharbor.io,resources=harbors/status,verbs=get;update;patch
 
 func (r *Reconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
-	ctx := context.TODO()
+	ctx, cancel := context.WithTimeout(context.Background(), r.Config.GetReconcileTimeout())
+	defer cancel()
+
 	application.SetName(&ctx, r.GetName())
 	application.SetVersion(&ctx, r.GetVersion())
 
@@ -50,6 +119,8 @@ func (r *Reconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 			// Request object not found, could have been deleted after reconcile request.
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			reqLogger.Info("Harbor does not exists")
+			metrics.Forget(req.NamespacedName)
+			r.requeue.Forget(req.NamespacedName)
 			return reconcile.Result{}, nil
 		}
 
@@ -61,124 +132,389 @@ func (r *Reconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 
 	if !harbor.ObjectMeta.DeletionTimestamp.IsZero() {
 		reqLogger.Info("harbor is being deleted")
+		r.requeue.Forget(req.NamespacedName)
 		return result, nil
 	}
 
-	var g errgroup.Group
+	// Owned resources of other Harbors may enqueue them
+	if !r.GetEventFilter().IsManaged(harbor) {
+		reqLogger.V(1).Info("harbor not managed by this operator")
+		r.requeue.Forget(req.NamespacedName)
+		return result, nil
+	}
 
-	g.Go(func() error {
-		err = r.UpdateReadyStatus(ctx, &result, harbor)
-		return errors.Wrapf(err, "type=%s", goharborv1alpha1.ReadyConditionType)
-	})
+	// Defaults of the class are only merged in memory, so updates of the class apply to the Harbor
+	merged, err := r.ApplyHarborClass(ctx, harbor)
+	if err != nil {
+		return r.Requeue(ctx, req.NamespacedName, harbor, result, errors.Wrap(err, "cannot apply harbor class"))
+	}
 
-	g.Go(func() error {
-		err = r.UpdateAppliedStatus(ctx, &result, harbor)
-		return errors.Wrapf(err, "type=%s", goharborv1alpha1.AppliedConditionType)
-	})
+	harbor = merged
+
+	original := harbor.DeepCopy()
+
+	rec := &reconciliation{
+		harbor: harbor,
+		result: &result,
+		resync: r.requeue.ShouldResync(req.NamespacedName, r.GetRequeueConfig(ctx, harbor).ResyncInterval, time.Now()),
+	}
+
+	err = r.RunPhases(ctx, rec)
+	if err == nil && rec.applied && rec.resync {
+		r.requeue.Resynced(req.NamespacedName, time.Now())
+	}
+
+	// Statuses computed so far are written, even when a phase failed or the deadline is exceeded
+	statusCtx, cancelStatus := context.WithTimeout(withoutCancel(ctx), StatusPatchTimeout)
+	defer cancelStatus()
+
+	statusErr := r.PatchStatus(statusCtx, &result, original, harbor)
 
-	err = g.Wait()
 	if err != nil {
-		return result, errors.Wrap(err, "cannot set status")
+		if statusErr != nil {
+			reqLogger.Error(statusErr, "cannot patch status")
+		}
+
+		err = errors.Wrap(err, "cannot reconcile")
+	} else {
+		err = statusErr
 	}
 
-	return result, r.UpdateStatus(ctx, &result, harbor)
+	return r.Requeue(ctx, req.NamespacedName, harbor, result, err)
+}
+
+// RunPhases runs every phase of the reconciliation, one after the other.
+// Phases stop at the first error or once the deadline of the reconciliation is exceeded.
+func (r *Reconciler) RunPhases(ctx context.Context, rec *reconciliation) error {
+	for _, phase := range r.getPhases() {
+		err := ctx.Err()
+		if err != nil {
+			rec.result.Requeue = true
+
+			return errors.Wrapf(err, "cannot start %s phase", phase.name)
+		}
+
+		err = r.runPhase(ctx, rec, phase)
+		if err != nil {
+			rec.result.Requeue = true
+
+			return errors.Wrapf(err, "%s phase", phase.name)
+		}
+	}
+
+	return nil
+}
+
+func (r *Reconciler) runPhase(ctx context.Context, rec *reconciliation, phase phase) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, string(phase.name))
+	defer span.Finish()
+
+	logger.Set(&ctx, logger.Get(ctx).WithValues("Phase", phase.name))
+
+	return phase.run(ctx, rec)
 }
 
-func (r *Reconciler) UpdateAppliedStatus(ctx context.Context, result *ctrl.Result, harbor *goharborv1alpha1.Harbor) error {
+// validatePhase reports paused resources and checks the spec can be applied.
+func (r *Reconciler) validatePhase(ctx context.Context, rec *reconciliation) error {
+	harbor := rec.harbor
+
+	err := r.UpdatePausedStatus(ctx, harbor)
+	if err != nil {
+		return errors.Wrapf(err, "type=%s", goharborv1alpha1.PausedConditionType)
+	}
+
+	if harbor.Spec.Paused {
+		// The new generation, if any, is applied once resumed
+		logger.Get(ctx).Info("harbor is paused")
+
+		rec.skipApply = true
+
+		return nil
+	}
+
 	if harbor.Status.ObservedGeneration != harbor.ObjectMeta.Generation {
 		harbor.Status.ObservedGeneration = harbor.ObjectMeta.Generation
 
 		err := r.UpdateCondition(ctx, harbor, goharborv1alpha1.AppliedConditionType, corev1.ConditionFalse, "new", "new generation detected")
 		if err != nil {
-			result.Requeue = true
-
 			return errors.Wrapf(err, "value=%s", corev1.ConditionFalse)
 		}
 	}
 
-	switch r.GetConditionStatus(ctx, harbor, goharborv1alpha1.AppliedConditionType) {
-	case corev1.ConditionTrue: // Already applied
-		// Anyway, reconciler is triggered, so at least one child resource has been deleted
-		// Try to recreate children
-		err := r.Create(ctx, harbor)
-		if err != nil {
-			result.Requeue = true
+	// Defaults are not set by the webhook when it is not deployed, or for Harbors created before it was enabled
+	harbor.SetDefaults()
+	r.ApplyLegacyCertificates(harbor)
+
+	err = r.Validate(ctx, harbor)
+	if err != nil {
+		// The Harbor must be updated, no need to retry
+		rec.skipApply = true
 
-			err := r.UpdateCondition(ctx, harbor, goharborv1alpha1.AppliedConditionType, corev1.ConditionFalse, err.Error())
-			if err != nil {
-				result.Requeue = true
+		if r.GetCondition(ctx, harbor, goharborv1alpha1.AppliedConditionType).Reason != InvalidSpecReason {
+			r.Recorder.Event(harbor, corev1.EventTypeWarning, InvalidSpecReason, err.Error())
+		}
 
-				return errors.Wrapf(err, "value=%s", corev1.ConditionFalse)
-			}
+		err := r.UpdateCondition(ctx, harbor, goharborv1alpha1.AppliedConditionType, corev1.ConditionFalse, InvalidSpecReason, err.Error())
 
-			return nil
+		return errors.Wrapf(err, "value=%s", corev1.ConditionFalse)
+	}
+
+	return nil
+}
+
+// Validate returns an error if the harbor cannot be applied.
+// Harbors created before the webhook was enabled are not validated by the API server.
+func (r *Reconciler) Validate(ctx context.Context, harbor *goharborv1alpha1.Harbor) error {
+	if harbor.Spec.Expose.GetType() == goharborv1alpha1.RouteExposeType && !r.routeAPIAvailable {
+		return errors.Errorf("cannot expose using routes: %s API not available", routeGVK.GroupVersion())
+	}
+
+	return harbor.Validate()
+}
+
+// renderPhase renders the configurations of every component.
+func (r *Reconciler) renderPhase(ctx context.Context, rec *reconciliation) error {
+	if rec.skipApply {
+		return nil
+	}
+
+	harbor := rec.harbor
+
+	harborResources, err := components.GetComponents(ctx, harbor)
+	if err != nil {
+		return errors.Wrap(err, "cannot get resources to manage")
+	}
+
+	err = harborResources.RenderConfigs(ctx, harbor, r.Client)
+	if err != nil {
+		rec.skipApply = true
+
+		if render.IsError(err) {
+			// Referenced secrets are not watched, retry until they are fixed
+			rec.result.Requeue = true
+
+			r.Recorder.Event(harbor, corev1.EventTypeWarning, ConfigurationErrorReason, err.Error())
 		}
-	default: // Not yet applied
+
+		err := r.UpdateCondition(ctx, harbor, goharborv1alpha1.AppliedConditionType, corev1.ConditionFalse, getAppliedFailureReasons(errors.Wrap(err, "cannot render configuration"))...)
+
+		return errors.Wrapf(err, "value=%s", corev1.ConditionFalse)
+	}
+
+	hashes, err := r.GetDesiredStateHashes(ctx, harbor, harborResources)
+	if err != nil {
+		return errors.Wrap(err, "cannot compute desired state")
+	}
+
+	rec.components = harborResources
+	rec.hashes = hashes
+
+	return nil
+}
+
+// applyPhase applies components whose desired state changed, and creates missing resources
+// and handles drifts of the other ones.
+func (r *Reconciler) applyPhase(ctx context.Context, rec *reconciliation) error {
+	if rec.skipApply {
+		return nil
+	}
+
+	harbor := rec.harbor
+
+	wasApplied := r.GetConditionStatus(ctx, harbor, goharborv1alpha1.AppliedConditionType) == corev1.ConditionTrue
+	if !wasApplied {
 		err := r.UpdateCondition(ctx, harbor, goharborv1alpha1.AppliedConditionType, corev1.ConditionFalse)
 		if err != nil {
-			result.Requeue = true
-
 			return errors.Wrapf(err, "value=%s", corev1.ConditionFalse)
 		}
+	}
 
-		err = r.Apply(ctx, harbor)
-		if err != nil {
-			err := r.UpdateCondition(ctx, harbor, goharborv1alpha1.AppliedConditionType, corev1.ConditionFalse, err.Error())
-			if err != nil {
-				result.Requeue = true
+	drifts, err := r.Apply(ctx, harbor, rec.components, rec.hashes, rec.resync)
+	if err != nil {
+		if wasApplied {
+			// Anyway, reconciler is triggered, so at least one child resource has been deleted or changed
+			rec.result.Requeue = true
+		}
 
-				return errors.Wrapf(err, "value=%s", corev1.ConditionFalse)
-			}
+		err := r.UpdateCondition(ctx, harbor, goharborv1alpha1.AppliedConditionType, corev1.ConditionFalse, getAppliedFailureReasons(err)...)
 
-			return nil
-		}
+		return errors.Wrapf(err, "value=%s", corev1.ConditionFalse)
+	}
 
-		err = r.UpdateCondition(ctx, harbor, goharborv1alpha1.AppliedConditionType, corev1.ConditionTrue)
+	rec.applied = true
+	rec.drifts = drifts
+
+	return nil
+}
+
+// prunePhase deletes resources no longer desired once every component is applied,
+// and records the applied desired states.
+func (r *Reconciler) prunePhase(ctx context.Context, rec *reconciliation) error {
+	if !rec.applied {
+		return nil
+	}
+
+	harbor := rec.harbor
+
+	if rec.resync || r.isDesiredStateChanged(ctx, rec) {
+		err := r.Prune(ctx, harbor, rec.components)
 		if err != nil {
-			result.Requeue = true
+			err := r.UpdateCondition(ctx, harbor, goharborv1alpha1.AppliedConditionType, corev1.ConditionFalse, errors.Wrap(err, "cannot prune resources").Error())
 
-			return errors.Wrapf(err, "value=%s", corev1.ConditionTrue)
+			return errors.Wrapf(err, "value=%s", corev1.ConditionFalse)
 		}
 	}
 
-	return nil
+	r.UpdateComponentsStatus(ctx, harbor, rec.hashes)
+
+	err := r.UpdateCondition(ctx, harbor, goharborv1alpha1.AppliedConditionType, corev1.ConditionTrue)
+	if err != nil {
+		return errors.Wrapf(err, "value=%s", corev1.ConditionTrue)
+	}
+
+	err = r.UpdateDriftedStatus(ctx, harbor, rec.drifts)
+
+	return errors.Wrapf(err, "type=%s", goharborv1alpha1.DriftedConditionType)
+}
+
+// isDesiredStateChanged returns true if a new generation is applied, or if the desired state of a component
+// was added, removed or changed since the last apply.
+func (r *Reconciler) isDesiredStateChanged(ctx context.Context, rec *reconciliation) bool {
+	harbor := rec.harbor
+
+	if r.GetCondition(ctx, harbor, goharborv1alpha1.AppliedConditionType).Status != corev1.ConditionTrue {
+		return true
+	}
+
+	for _, component := range harbor.Status.Components {
+		if _, ok := rec.hashes[component.Name]; !ok && !harbor.IsComponentPaused(component.Name) {
+			return true
+		}
+	}
+
+	for name, hash := range rec.hashes {
+		if !harbor.IsComponentPaused(name) && harbor.Status.GetDesiredStateHash(name) != hash {
+			return true
+		}
+	}
+
+	return false
+}
+
+// observePhase reports the readiness of the Harbor, from its resources and its health, and tracks its upgrades.
+func (r *Reconciler) observePhase(ctx context.Context, rec *reconciliation) error {
+	err := r.UpdateReadyStatus(ctx, rec.result, rec.harbor)
+	if err != nil {
+		return errors.Wrapf(err, "type=%s", goharborv1alpha1.ReadyConditionType)
+	}
+
+	// Versions are only known once rendered, paused Harbors are not upgraded
+	if rec.components == nil {
+		return nil
+	}
+
+	return r.ObserveUpgrade(ctx, rec.harbor, rec.components)
+}
+
+// getAppliedFailureReasons returns the reason and message of the Applied condition.
+// Configuration errors are reported with a dedicated reason.
+func getAppliedFailureReasons(err error) []string {
+	if render.IsError(err) {
+		return []string{ConfigurationErrorReason, err.Error()}
+	}
+
+	return []string{err.Error()}
 }
 
 func (r *Reconciler) UpdateReadyStatus(ctx context.Context, result *ctrl.Result, harbor *goharborv1alpha1.Harbor) error {
-	// TODO do it asynchronously but do not
-	// forget to wait for completion before return
-	health, err := r.GetHealth(ctx, harbor)
+	err := r.UpdateRoutesStatus(ctx, harbor)
 	if err != nil {
-		result.Requeue = true
+		return errors.Wrap(err, "cannot update routes status")
+	}
 
-		err = r.UpdateCondition(ctx, harbor, goharborv1alpha1.ReadyConditionType, corev1.ConditionFalse, errors.Cause(err).Error(), err.Error())
-		if err != nil {
-			result.Requeue = true
+	err = r.UpdateCertificatesStatus(ctx, harbor)
+	if err != nil {
+		return errors.Wrap(err, "cannot update certificates status")
+	}
 
-			return errors.Wrapf(err, "value=%s", corev1.ConditionFalse)
-		}
-	} else {
-		if health.IsHealthy() {
-			err = r.UpdateCondition(ctx, harbor, goharborv1alpha1.ReadyConditionType, corev1.ConditionTrue)
-			if err != nil {
-				result.Requeue = true
+	err = r.UpdatePublicCertificateStatus(ctx, harbor)
+	if err != nil {
+		return errors.Wrap(err, "cannot update public certificate status")
+	}
+
+	err = r.UpdateFunctionalStatus(ctx, harbor)
+	if err != nil {
+		return errors.Wrapf(err, "type=%s", goharborv1alpha1.FunctionalConditionType)
+	}
 
-				return errors.Wrapf(err, "value=%s", corev1.ConditionTrue)
-			}
-		} else {
-			// Hide error, just try again later
-			logger.Get(ctx).Info("not ready yet, trying again later")
+	wasReady := r.GetConditionStatus(ctx, harbor, goharborv1alpha1.ReadyConditionType)
 
-			result.RequeueAfter = DefaultRequeueWait
+	issues, err := r.GetWorkloadIssues(ctx, harbor)
+	if err != nil {
+		return errors.Wrap(err, "cannot get workload status")
+	}
 
-			err = r.UpdateCondition(ctx, harbor, goharborv1alpha1.ReadyConditionType, corev1.ConditionFalse, "harbor-component", fmt.Sprintf("at least an Harbor component failed: %+v", health.GetUnhealthyComponents()))
-			if err != nil {
-				result.Requeue = true
+	// Health is probed in the background, the Harbor is enqueued again when it changes
+	probe := r.healthProber.Get(types.NamespacedName{Namespace: harbor.GetNamespace(), Name: harbor.GetName()})
+	if probe != nil {
+		r.collector.SetHealth(types.NamespacedName{Namespace: harbor.GetNamespace(), Name: harbor.GetName()}, probe.Health)
+
+		issues = append(issues, getHealthIssues(probe)...)
+	}
+
+	if len(issues) == 0 {
+		if probe == nil {
+			logger.Get(ctx).V(1).Info("health not probed yet")
+
+			return nil
+		}
 
-				return errors.Wrapf(err, "value=%s", corev1.ConditionTrue)
-			}
+		if wasReady != corev1.ConditionTrue {
+			r.Recorder.Event(harbor, corev1.EventTypeNormal, HealthyReason, "All components are healthy")
 		}
+
+		err = r.UpdateCondition(ctx, harbor, goharborv1alpha1.ReadyConditionType, corev1.ConditionTrue)
+
+		return errors.Wrapf(err, "value=%s", corev1.ConditionTrue)
+	}
+
+	SortReadinessIssues(issues)
+
+	// Pods and status updates of workloads are not watched
+	if !issues[0].IsHealthIssue() {
+		result.Requeue = true
+	}
+
+	message := GetReadinessMessage(issues)
+
+	logger.Get(ctx).Info("not ready yet", "reason", issues[0].Reason, "message", message)
+
+	if wasReady != corev1.ConditionFalse {
+		r.Recorder.Event(harbor, corev1.EventTypeWarning, UnhealthyReason, message)
 	}
 
+	err = r.UpdateCondition(ctx, harbor, goharborv1alpha1.ReadyConditionType, corev1.ConditionFalse, issues[0].Reason, message)
+
+	return errors.Wrapf(err, "value=%s", corev1.ConditionFalse)
+}
+
+// valuesContext keeps the values of a context, such as the logger, without its deadline and cancellation.
+type valuesContext struct {
+	context.Context
+}
+
+func withoutCancel(ctx context.Context) context.Context {
+	return valuesContext{ctx}
+}
+
+func (valuesContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (valuesContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (valuesContext) Err() error {
 	return nil
 }
@@ -0,0 +1,181 @@
+package harbor
+
+import (
+	"context"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+)
+
+var _ = Describe("Reconcile", func() {
+	var r *Reconciler
+	var ctx context.Context
+	var harbor *goharborv1alpha1.Harbor
+
+	BeforeEach(func() {
+		// The fake client decodes patched objects with the client-go scheme
+		Expect(goharborv1alpha1.AddToScheme(kscheme.Scheme)).To(Succeed())
+
+		r, ctx = setupTest(context.TODO())
+		r.Log = zap.LoggerTo(GinkgoWriter, true)
+
+		harbor = &goharborv1alpha1.Harbor{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "my-harbor",
+				Namespace:  "registry",
+				Generation: 2,
+			},
+			Spec: goharborv1alpha1.HarborSpec{
+				Paused: true,
+			},
+		}
+	})
+
+	Context("RunPhases", func() {
+		It("Should stop once the deadline is exceeded", func() {
+			ctx, cancel := context.WithCancel(ctx)
+			cancel()
+
+			result := ctrl.Result{}
+
+			err := r.RunPhases(ctx, &reconciliation{harbor: harbor, result: &result})
+			Expect(err).To(MatchError(ContainSubstring("cannot start validate phase")))
+			Expect(result.Requeue).To(BeTrue())
+			Expect(harbor.Status.Conditions).To(BeEmpty())
+		})
+
+		It("Should run phases in order", func() {
+			Expect(r.getPhases()).To(HaveLen(5))
+
+			names := []Phase{}
+			for _, phase := range r.getPhases() {
+				names = append(names, phase.name)
+			}
+
+			Expect(names).To(Equal([]Phase{ValidatePhase, RenderPhase, ApplyPhase, PrunePhase, ObservePhase}))
+		})
+	})
+
+	Context("Validate phase", func() {
+		BeforeEach(func() {
+			harbor.Spec.Paused = false
+		})
+
+		It("Should not apply invalid harbors", func() {
+			harbor.Spec.PausedComponents = []goharborv1alpha1.PausableComponent{"unknown"}
+
+			rec := &reconciliation{harbor: harbor, result: &ctrl.Result{}}
+			Expect(r.validatePhase(ctx, rec)).To(Succeed())
+			Expect(r.validatePhase(ctx, rec)).To(Succeed())
+			Expect(rec.skipApply).To(BeTrue())
+			Expect(rec.result.Requeue).To(BeFalse())
+
+			condition := r.GetCondition(ctx, harbor, goharborv1alpha1.AppliedConditionType)
+			Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+			Expect(condition.Reason).To(Equal(InvalidSpecReason))
+			Expect(harbor.Status.ObservedGeneration).To(BeEquivalentTo(2))
+
+			events := r.Recorder.(*record.FakeRecorder).Events
+			Expect(<-events).To(HavePrefix("Normal Paused"))
+			Expect(<-events).To(HavePrefix("Warning InvalidSpec"))
+			Expect(events).To(BeEmpty())
+		})
+
+		It("Should default harbors not mutated by the webhook", func() {
+			harbor.Spec.Components.JobService = &goharborv1alpha1.JobServiceComponent{}
+
+			rec := &reconciliation{harbor: harbor, result: &ctrl.Result{}}
+			Expect(r.validatePhase(ctx, rec)).To(Succeed())
+
+			Expect(harbor.Spec.Expose.Type).To(Equal(goharborv1alpha1.IngressExposeType))
+			Expect(harbor.Spec.HarborVersion).ToNot(BeEmpty())
+			Expect(harbor.Spec.Components.JobService.WorkerCount).To(BeEquivalentTo(3))
+		})
+
+		It("Should require the route API to expose with routes", func() {
+			harbor.Spec.Expose.Type = goharborv1alpha1.RouteExposeType
+
+			Expect(r.Validate(ctx, harbor)).To(MatchError(ContainSubstring("route.openshift.io/v1 API not available")))
+		})
+	})
+
+	Context("PatchStatus", func() {
+		BeforeEach(func() {
+			r.Client = fake.NewFakeClientWithScheme(r.Scheme, harbor.DeepCopy())
+		})
+
+		It("Should not conflict with concurrent changes", func() {
+			Expect(r.Client.Get(ctx, types.NamespacedName{Namespace: "registry", Name: "my-harbor"}, harbor)).To(Succeed())
+			original := harbor.DeepCopy()
+
+			concurrent := harbor.DeepCopy()
+			concurrent.Spec.PublicURL = "https://registry.example.com"
+			Expect(r.Client.Update(ctx, concurrent)).To(Succeed())
+
+			Expect(r.UpdateCondition(ctx, harbor, goharborv1alpha1.ReadyConditionType, corev1.ConditionTrue)).To(Succeed())
+
+			result := ctrl.Result{}
+			Expect(r.PatchStatus(ctx, &result, original, harbor)).To(Succeed())
+			Expect(result.Requeue).To(BeFalse())
+
+			live := &goharborv1alpha1.Harbor{}
+			Expect(r.Client.Get(ctx, types.NamespacedName{Namespace: "registry", Name: "my-harbor"}, live)).To(Succeed())
+			Expect(live.Spec.PublicURL).To(Equal("https://registry.example.com"))
+			Expect(r.GetConditionStatus(ctx, live, goharborv1alpha1.ReadyConditionType)).To(Equal(corev1.ConditionTrue))
+		})
+
+		It("Should skip unchanged statuses", func() {
+			// The harbor is not known by the client, any patch would fail
+			r.Client = fake.NewFakeClientWithScheme(r.Scheme)
+
+			Expect(r.PatchStatus(ctx, &ctrl.Result{}, harbor.DeepCopy(), harbor)).To(Succeed())
+		})
+	})
+
+	It("Should reconcile harbors concurrently", func() {
+		other := harbor.DeepCopy()
+		other.SetName("other-harbor")
+
+		c := fake.NewFakeClientWithScheme(r.Scheme, harbor, other)
+		r.Client = c
+		r.APIReader = c
+
+		var wg sync.WaitGroup
+
+		for _, h := range []*goharborv1alpha1.Harbor{harbor, other} {
+			wg.Add(1)
+
+			go func(key types.NamespacedName) {
+				defer GinkgoRecover()
+				defer wg.Done()
+
+				_, err := r.Reconcile(ctrl.Request{NamespacedName: key})
+				Expect(err).ToNot(HaveOccurred())
+			}(types.NamespacedName{Namespace: h.GetNamespace(), Name: h.GetName()})
+		}
+
+		wg.Wait()
+
+		for _, name := range []string{"my-harbor", "other-harbor"} {
+			live := &goharborv1alpha1.Harbor{}
+			Expect(c.Get(ctx, client.ObjectKey{Namespace: "registry", Name: name}, live)).To(Succeed())
+
+			Expect(r.GetConditionStatus(ctx, live, goharborv1alpha1.PausedConditionType)).To(Equal(corev1.ConditionTrue))
+			// The new generation is applied once resumed
+			Expect(live.Status.ObservedGeneration).To(BeZero())
+		}
+	})
+})
@@ -0,0 +1,151 @@
+package harbor
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components"
+	"github.com/goharbor/harbor-operator/pkg/factories/application"
+)
+
+var componentNames = []string{
+	goharborv1alpha1.CoreName,
+	goharborv1alpha1.PortalName,
+	goharborv1alpha1.RegistryName,
+	goharborv1alpha1.JobServiceName,
+	goharborv1alpha1.ChartMuseumName,
+	goharborv1alpha1.ClairName,
+	goharborv1alpha1.NotaryName,
+	goharborv1alpha1.MonitoringName,
+}
+
+// Order of the rendered kinds, dependencies first.
+var renderKindOrder = map[string]int{
+	"ConfigMap":      0,
+	"Secret":         1,
+	"Certificate":    2,
+	"Service":        3,
+	"Ingress":        4,
+	"Route":          5,
+	"Deployment":     6,
+	"ServiceMonitor": 7,
+	"PrometheusRule": 8,
+}
+
+// Render returns the resources the reconciler would create for the harbor, without any change to the cluster.
+// Referenced secrets and configmaps are read from reader.
+// Only resources of the listed components are returned, all components if empty.
+func (r *Reconciler) Render(ctx context.Context, harbor *goharborv1alpha1.Harbor, reader client.Reader, names ...string) ([]components.Resource, error) {
+	filter := map[string]bool{}
+
+	for _, name := range names {
+		if !isComponentName(name) {
+			return nil, errors.Errorf("unknown component %q", name)
+		}
+
+		filter[name] = true
+	}
+
+	application.SetName(&ctx, r.GetName())
+	application.SetVersion(&ctx, r.GetVersion())
+
+	harborResources, err := components.GetComponents(ctx, harbor)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get components")
+	}
+
+	err = harborResources.RenderConfigs(ctx, harbor, reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot render configuration")
+	}
+
+	var lock sync.Mutex
+
+	result := []components.Resource{}
+
+	err = harborResources.ParallelRun(ctx, harbor, func(ctx context.Context, harbor *goharborv1alpha1.Harbor, component *components.ComponentRunner) error {
+		if len(filter) > 0 && !filter[components.ComponentName(ctx)] {
+			return nil
+		}
+
+		routes, err := component.GetRoutes(ctx)
+		if err != nil {
+			return err
+		}
+
+		resources := [][]components.Resource{
+			component.GetConfigMaps(ctx),
+			component.GetSecrets(ctx),
+			component.GetCertificates(ctx),
+			component.GetServices(ctx),
+			component.GetIngresses(ctx),
+			routes,
+			component.GetDeployments(ctx),
+			component.GetServiceMonitors(ctx),
+			component.GetPrometheusRules(ctx),
+		}
+
+		for _, list := range resources {
+			for _, resource := range list {
+				err := r.renderResource(ctx, harbor, resource)
+				if err != nil {
+					return errors.Wrapf(err, "cannot render %s", resource.GetName())
+				}
+
+				lock.Lock()
+				result = append(result, resource)
+				lock.Unlock()
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		kindI, kindJ := result[i].GetObjectKind().GroupVersionKind().Kind, result[j].GetObjectKind().GroupVersionKind().Kind
+		if kindI != kindJ {
+			return renderKindOrder[kindI] < renderKindOrder[kindJ]
+		}
+
+		return result[i].GetName() < result[j].GetName()
+	})
+
+	return result, nil
+}
+
+// renderResource sets type, labels, annotations and owner the same way resources are created.
+func (r *Reconciler) renderResource(ctx context.Context, harbor *goharborv1alpha1.Harbor, resource components.Resource) error {
+	if resource.GetObjectKind().GroupVersionKind().Kind == "" {
+		gvk, err := apiutil.GVKForObject(resource, r.Scheme)
+		if err != nil {
+			return errors.Wrap(err, "cannot get kind")
+		}
+
+		resource.GetObjectKind().SetGroupVersionKind(gvk)
+	}
+
+	r.MutateAnnotations(ctx, resource)
+	r.MutateLabels(ctx, resource)
+
+	return errors.Wrap(controllerutil.SetControllerReference(harbor, resource, r.Scheme), "cannot set controller reference")
+}
+
+func isComponentName(name string) bool {
+	for _, n := range componentNames {
+		if n == name {
+			return true
+		}
+	}
+
+	return false
+}
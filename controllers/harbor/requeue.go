@@ -0,0 +1,248 @@
+package harbor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/pkg/factories/logger"
+	"github.com/goharbor/harbor-operator/pkg/metrics"
+)
+
+const (
+	DefaultRequeueInitialDelay = 2 * time.Second
+	DefaultRequeueMaxDelay     = 5 * time.Minute
+	DefaultRequeueJitter       = 0.1
+	DefaultResyncInterval      = time.Hour
+)
+
+// RequeueConfig defines when Harbors are reconciled again.
+type RequeueConfig struct {
+	// InitialDelay is the delay before reconciling a failing or unready Harbor again.
+	// It is doubled on every attempt, up to MaxDelay.
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	// Jitter is the maximum factor of a delay added to it, so Harbors failing together are spread over time.
+	Jitter float64
+	// ResyncInterval is the interval between two applies of every component of a Harbor, disabled when zero.
+	ResyncInterval time.Duration
+}
+
+// GetDefaultRequeueConfig returns the default requeue configuration.
+func GetDefaultRequeueConfig() RequeueConfig {
+	return RequeueConfig{
+		InitialDelay:   DefaultRequeueInitialDelay,
+		MaxDelay:       DefaultRequeueMaxDelay,
+		Jitter:         DefaultRequeueJitter,
+		ResyncInterval: DefaultResyncInterval,
+	}
+}
+
+// Validate returns an error if delays are not consistent.
+func (c RequeueConfig) Validate() error {
+	if c.InitialDelay <= 0 {
+		return errors.Errorf("initial delay must be positive, got %s", c.InitialDelay)
+	}
+
+	if c.MaxDelay < c.InitialDelay {
+		return errors.Errorf("max delay %s must not be lower than initial delay %s", c.MaxDelay, c.InitialDelay)
+	}
+
+	if c.Jitter < 0 {
+		return errors.Errorf("jitter must not be negative, got %v", c.Jitter)
+	}
+
+	if c.ResyncInterval < 0 {
+		return errors.Errorf("resync interval must not be negative, got %s", c.ResyncInterval)
+	}
+
+	return nil
+}
+
+// GetRequeueConfig returns the requeue configuration of the Harbor: the configuration of the operator,
+// overridden by the annotations of the Harbor. Invalid annotations are ignored.
+func (r *Reconciler) GetRequeueConfig(ctx context.Context, harbor *goharborv1alpha1.Harbor) RequeueConfig {
+	r.configLock.RLock()
+	base := r.Config.GetRequeue()
+	r.configLock.RUnlock()
+
+	config := base
+
+	overrides := map[string]*time.Duration{
+		goharborv1alpha1.RequeueInitialDelayAnnotation: &config.InitialDelay,
+		goharborv1alpha1.RequeueMaxDelayAnnotation:     &config.MaxDelay,
+		goharborv1alpha1.ResyncIntervalAnnotation:      &config.ResyncInterval,
+	}
+
+	for annotation, value := range overrides {
+		raw, ok := harbor.GetAnnotations()[annotation]
+		if !ok {
+			continue
+		}
+
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			logger.Get(ctx).Error(err, "invalid annotation, ignored", "annotation", annotation)
+			continue
+		}
+
+		*value = duration
+	}
+
+	err := config.Validate()
+	if err != nil {
+		logger.Get(ctx).Error(err, "invalid requeue annotations, ignored")
+
+		return base
+	}
+
+	return config
+}
+
+// SetRequeue replaces the requeue configuration of the operator while Harbors are reconciled.
+func (r *Reconciler) SetRequeue(config RequeueConfig) {
+	r.configLock.Lock()
+	defer r.configLock.Unlock()
+
+	r.Config.Requeue = config
+}
+
+// jitter adds up to factor times the delay to the delay, wait.Jitter defaults to a factor of 1 when it is zero.
+func jitter(delay time.Duration, factor float64) time.Duration {
+	if factor <= 0 {
+		return delay
+	}
+
+	return wait.Jitter(delay, factor)
+}
+
+type requeueState struct {
+	generation int64
+	failures   int
+	lastResync time.Time
+}
+
+// RequeueTracker tracks failed reconciliations and resyncs of every Harbor. The zero value is ready to use.
+type RequeueTracker struct {
+	lock   sync.Mutex
+	states map[types.NamespacedName]*requeueState
+}
+
+func (t *RequeueTracker) get(key types.NamespacedName, now time.Time) *requeueState {
+	if t.states == nil {
+		t.states = map[types.NamespacedName]*requeueState{}
+	}
+
+	state, ok := t.states[key]
+	if !ok {
+		// Harbors are fully applied when they are created, or were applied by a previous instance of the operator
+		state = &requeueState{lastResync: now}
+		t.states[key] = state
+	}
+
+	return state
+}
+
+// NextDelay returns the delay before reconciling the Harbor again after a failure.
+// Failures of previous generations are not taken into account.
+func (t *RequeueTracker) NextDelay(key types.NamespacedName, generation int64, config RequeueConfig) time.Duration {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	state := t.get(key, time.Now())
+
+	if state.generation != generation {
+		state.generation = generation
+		state.failures = 0
+	}
+
+	delay := config.InitialDelay
+	for i := 0; i < state.failures && delay < config.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > config.MaxDelay {
+		delay = config.MaxDelay
+	}
+
+	state.failures++
+
+	return jitter(delay, config.Jitter)
+}
+
+// Reset forgets the failures of the Harbor.
+func (t *RequeueTracker) Reset(key types.NamespacedName) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.get(key, time.Now()).failures = 0
+}
+
+// ShouldResync returns true if every component of the Harbor should be applied again.
+func (t *RequeueTracker) ShouldResync(key types.NamespacedName, interval time.Duration, now time.Time) bool {
+	if interval <= 0 {
+		return false
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return now.Sub(t.get(key, now).lastResync) >= interval
+}
+
+// Resynced records that every component of the Harbor was applied.
+func (t *RequeueTracker) Resynced(key types.NamespacedName, now time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.get(key, now).lastResync = now
+}
+
+// Forget removes the state of a deleted Harbor.
+func (t *RequeueTracker) Forget(key types.NamespacedName) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	delete(t.states, key)
+}
+
+// Requeue sets when the Harbor is reconciled again.
+// Failing or unready Harbors are reconciled again with an exponential backoff. The error is then logged and counted
+// by the harbor_operator_harbor_reconcile_failures_total metric, but not returned: the controller would ignore
+// the result and requeue the Harbor with its own rate limiter instead.
+// Other Harbors are reconciled again after the resync interval.
+func (r *Reconciler) Requeue(ctx context.Context, key types.NamespacedName, harbor *goharborv1alpha1.Harbor, result ctrl.Result, err error) (ctrl.Result, error) {
+	config := r.GetRequeueConfig(ctx, harbor)
+
+	if err != nil || result.Requeue || result.RequeueAfter > 0 {
+		delay := r.requeue.NextDelay(key, harbor.GetGeneration(), config)
+
+		// The API server may suggest a longer delay
+		if result.RequeueAfter > delay {
+			delay = result.RequeueAfter
+		}
+
+		if err != nil {
+			metrics.ObserveReconcileFailure(key)
+			logger.Get(ctx).Error(err, "cannot reconcile", "requeueAfter", delay)
+		} else {
+			logger.Get(ctx).V(1).Info("reconciling again", "requeueAfter", delay)
+		}
+
+		return ctrl.Result{RequeueAfter: delay}, nil
+	}
+
+	r.requeue.Reset(key)
+
+	if config.ResyncInterval > 0 {
+		return ctrl.Result{RequeueAfter: jitter(config.ResyncInterval, config.Jitter)}, nil
+	}
+
+	return ctrl.Result{}, nil
+}
@@ -0,0 +1,160 @@
+package harbor
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pkg/errors"
+	dto "github.com/prometheus/client_model/go"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/pkg/metrics"
+)
+
+var _ = Describe("Requeue", func() {
+	var r *Reconciler
+	var ctx context.Context
+	var harbor *goharborv1alpha1.Harbor
+
+	key := types.NamespacedName{Namespace: "registry", Name: "my-harbor"}
+
+	BeforeEach(func() {
+		r, ctx = setupTest(context.TODO())
+		r.Config.Requeue = RequeueConfig{
+			InitialDelay:   time.Second,
+			MaxDelay:       5 * time.Second,
+			ResyncInterval: time.Hour,
+		}
+
+		harbor = &goharborv1alpha1.Harbor{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       key.Name,
+				Namespace:  key.Namespace,
+				Generation: 1,
+			},
+		}
+	})
+
+	Context("RequeueTracker", func() {
+		var tracker *RequeueTracker
+
+		BeforeEach(func() {
+			tracker = &RequeueTracker{}
+		})
+
+		It("Should back off exponentially", func() {
+			delays := []time.Duration{}
+			for i := 0; i < 5; i++ {
+				delays = append(delays, tracker.NextDelay(key, 1, r.Config.Requeue))
+			}
+
+			Expect(delays).To(Equal([]time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}))
+		})
+
+		It("Should add jitter", func() {
+			config := r.Config.Requeue
+			config.Jitter = 0.5
+
+			delay := tracker.NextDelay(key, 1, config)
+			Expect(delay).To(BeNumerically(">=", time.Second))
+			Expect(delay).To(BeNumerically("<=", 1500*time.Millisecond))
+		})
+
+		It("Should reset on new generations", func() {
+			tracker.NextDelay(key, 1, r.Config.Requeue)
+			tracker.NextDelay(key, 1, r.Config.Requeue)
+
+			Expect(tracker.NextDelay(key, 2, r.Config.Requeue)).To(Equal(time.Second))
+		})
+
+		It("Should reset once reconciled", func() {
+			tracker.NextDelay(key, 1, r.Config.Requeue)
+			tracker.Reset(key)
+
+			Expect(tracker.NextDelay(key, 1, r.Config.Requeue)).To(Equal(time.Second))
+		})
+
+		It("Should resync periodically", func() {
+			now := time.Now()
+
+			Expect(tracker.ShouldResync(key, time.Hour, now)).To(BeFalse())
+			Expect(tracker.ShouldResync(key, time.Hour, now.Add(time.Hour))).To(BeTrue())
+			Expect(tracker.ShouldResync(key, 0, now.Add(time.Hour))).To(BeFalse())
+
+			tracker.Resynced(key, now.Add(time.Hour))
+			Expect(tracker.ShouldResync(key, time.Hour, now.Add(time.Hour+time.Minute))).To(BeFalse())
+		})
+	})
+
+	Context("GetRequeueConfig", func() {
+		It("Should use the default configuration", func() {
+			r.Config.Requeue = RequeueConfig{}
+
+			Expect(r.GetRequeueConfig(ctx, harbor)).To(Equal(GetDefaultRequeueConfig()))
+		})
+
+		It("Should be overridden by annotations", func() {
+			harbor.SetAnnotations(map[string]string{
+				goharborv1alpha1.RequeueMaxDelayAnnotation: "1m",
+				goharborv1alpha1.ResyncIntervalAnnotation:  "0s",
+			})
+
+			config := r.GetRequeueConfig(ctx, harbor)
+			Expect(config.InitialDelay).To(Equal(time.Second))
+			Expect(config.MaxDelay).To(Equal(time.Minute))
+			Expect(config.ResyncInterval).To(BeZero())
+		})
+
+		It("Should ignore invalid annotations", func() {
+			harbor.SetAnnotations(map[string]string{
+				goharborv1alpha1.RequeueInitialDelayAnnotation: "10m",
+				goharborv1alpha1.ResyncIntervalAnnotation:      "daily",
+			})
+
+			Expect(r.GetRequeueConfig(ctx, harbor)).To(Equal(r.Config.Requeue))
+		})
+	})
+
+	Context("Requeue", func() {
+		It("Should back off on errors", func() {
+			failures := &dto.Metric{}
+			Expect(metrics.HarborReconcileFailures.WithLabelValues(key.Namespace, key.Name).Write(failures)).To(Succeed())
+
+			result, err := r.Requeue(ctx, key, harbor, ctrl.Result{}, errors.New("apply failed"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(ctrl.Result{RequeueAfter: time.Second}))
+
+			counted := &dto.Metric{}
+			Expect(metrics.HarborReconcileFailures.WithLabelValues(key.Namespace, key.Name).Write(counted)).To(Succeed())
+			Expect(counted.GetCounter().GetValue()).To(Equal(failures.GetCounter().GetValue() + 1))
+
+			result, err = r.Requeue(ctx, key, harbor, ctrl.Result{Requeue: true}, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(ctrl.Result{RequeueAfter: 2 * time.Second}))
+		})
+
+		It("Should keep longer delays suggested by the API server", func() {
+			result, err := r.Requeue(ctx, key, harbor, ctrl.Result{Requeue: true, RequeueAfter: time.Minute}, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(time.Minute))
+		})
+
+		It("Should resync ready Harbors", func() {
+			r.Requeue(ctx, key, harbor, ctrl.Result{Requeue: true}, nil) // nolint:errcheck
+
+			result, err := r.Requeue(ctx, key, harbor, ctrl.Result{}, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(ctrl.Result{RequeueAfter: time.Hour}))
+
+			// Failures are forgotten
+			result, _ = r.Requeue(ctx, key, harbor, ctrl.Result{Requeue: true}, nil)
+			Expect(result.RequeueAfter).To(Equal(time.Second))
+		})
+	})
+})
@@ -0,0 +1,287 @@
+package harbor
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	routev1 "github.com/openshift/api/route/v1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/internaltls"
+)
+
+const (
+	// RouteCACertificateKey is the key of the TLS secret holding the CA bundle used by routes.
+	RouteCACertificateKey = "ca.crt"
+	// RouteTLSChecksumAnnotation is set on routes so renewed certificates change the desired state of their component.
+	RouteTLSChecksumAnnotation = "route-tls/checksum"
+)
+
+// IsRouteAPIAvailable returns true when the route.openshift.io API serves routes.
+func IsRouteAPIAvailable(config *rest.Config) (bool, error) {
+	return IsAPIAvailable(config, routev1.SchemeGroupVersion.WithKind("Route"))
+}
+
+// IsAPIAvailable returns true when the API server serves the kind, its CRD being installed for instance.
+func IsAPIAvailable(config *rest.Config, gvk schema.GroupVersionKind) (bool, error) {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return false, errors.Wrap(err, "cannot create discovery client")
+	}
+
+	resources, err := discoveryClient.ServerResourcesForGroupVersion(gvk.GroupVersion().String())
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+
+		return false, errors.Wrapf(err, "cannot discover %s", gvk.GroupVersion())
+	}
+
+	for _, resource := range resources.APIResources {
+		if resource.Kind == gvk.Kind {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// InjectRoutesTLS copies the certificate stored in the TLS secret of the Harbor into routes.
+func (r *Reconciler) InjectRoutesTLS(ctx context.Context, harbor *goharborv1alpha1.Harbor, resources []components.Resource) error {
+	if harbor.Spec.TLSSecretName == "" && !harbor.IsPublicCertificateIssued() {
+		return nil
+	}
+
+	var tls *routev1.TLSConfig
+
+	for _, resource := range resources {
+		route, ok := resource.(*routev1.Route)
+		if !ok {
+			return errors.Errorf("unexpected resource %+v", resource)
+		}
+
+		if route.Spec.TLS == nil {
+			continue
+		}
+
+		if tls == nil {
+			var err error
+
+			// The public certificate may not be issued yet, the apply fails and is retried with backoff
+			tls, err = r.GetRoutesTLS(ctx, harbor, route.Spec.TLS.Termination)
+			if err != nil {
+				return err
+			}
+		}
+
+		route.Spec.TLS.Certificate = tls.Certificate
+		route.Spec.TLS.Key = tls.Key
+		route.Spec.TLS.CACertificate = tls.CACertificate
+		route.Spec.TLS.DestinationCACertificate = tls.DestinationCACertificate
+
+		setRouteTLSChecksum(route, tls)
+	}
+
+	return nil
+}
+
+// GetRoutesTLS returns the certificates of routes with the termination, read from the TLS secret of the Harbor.
+func (r *Reconciler) GetRoutesTLS(ctx context.Context, harbor *goharborv1alpha1.Harbor, termination routev1.TLSTerminationType) (*routev1.TLSConfig, error) {
+	secretName := harbor.GetTLSSecretName()
+	secret := &corev1.Secret{}
+
+	err := r.Client.Get(ctx, client.ObjectKey{
+		Namespace: harbor.GetNamespace(),
+		Name:      secretName,
+	}, secret)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get tls secret %s", secretName)
+	}
+
+	tls := &routev1.TLSConfig{
+		Termination:   termination,
+		Certificate:   string(secret.Data[corev1.TLSCertKey]),
+		Key:           string(secret.Data[corev1.TLSPrivateKeyKey]),
+		CACertificate: string(secret.Data[RouteCACertificateKey]),
+	}
+
+	// Validation requires internal TLS to re-encrypt
+	if termination == routev1.TLSTerminationReencrypt {
+		tls.DestinationCACertificate, err = r.GetInternalCACertificate(ctx, harbor)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot get internal ca")
+		}
+	}
+
+	return tls, nil
+}
+
+// AnnotateRoutesTLSChecksum annotates routes with the checksum of their certificates.
+// It is called on the resources used to compute the desired state hash, so renewed certificates are applied
+// as any other change of the component. Missing secrets are ignored, the apply fails until they are created.
+func (r *Reconciler) AnnotateRoutesTLSChecksum(ctx context.Context, harbor *goharborv1alpha1.Harbor, resources []components.Resource) error {
+	if harbor.Spec.TLSSecretName == "" && !harbor.IsPublicCertificateIssued() {
+		return nil
+	}
+
+	var tls *routev1.TLSConfig
+
+	for _, resource := range resources {
+		route, ok := resource.(*routev1.Route)
+		if !ok || route.Spec.TLS == nil {
+			continue
+		}
+
+		if tls == nil {
+			var err error
+
+			tls, err = r.GetRoutesTLS(ctx, harbor, route.Spec.TLS.Termination)
+			if err != nil {
+				if apierrors.IsNotFound(errors.Cause(err)) {
+					return nil
+				}
+
+				return err
+			}
+		}
+
+		setRouteTLSChecksum(route, tls)
+	}
+
+	return nil
+}
+
+func setRouteTLSChecksum(route *routev1.Route, tls *routev1.TLSConfig) {
+	data := strings.Join([]string{tls.Certificate, tls.Key, tls.CACertificate, tls.DestinationCACertificate}, "\n")
+
+	if route.Annotations == nil {
+		route.Annotations = map[string]string{}
+	}
+
+	route.Annotations[RouteTLSChecksumAnnotation] = fmt.Sprintf("%x", sha256.Sum256([]byte(data)))
+}
+
+// GetInternalCACertificate returns the CA of the internal certificates.
+// All internal certificates are issued by the same issuer, so the one of the core is used.
+func (r *Reconciler) GetInternalCACertificate(ctx context.Context, harbor *goharborv1alpha1.Harbor) (string, error) {
+	secretName := internaltls.GetSecretName(harbor, goharborv1alpha1.CoreName)
+	secret := &corev1.Secret{}
+
+	err := r.Client.Get(ctx, client.ObjectKey{
+		Namespace: harbor.GetNamespace(),
+		Name:      secretName,
+	}, secret)
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot get secret %s", secretName)
+	}
+
+	return string(secret.Data[RouteCACertificateKey]), nil
+}
+
+// WithRoutesTLS returns a ComponentRun injecting TLS certificates in routes before calling run.
+func (r *Reconciler) WithRoutesTLS(run components.ComponentRun) components.ComponentRun {
+	return func(ctx context.Context, harbor *goharborv1alpha1.Harbor, resources []components.Resource) error {
+		err := r.InjectRoutesTLS(ctx, harbor, resources)
+		if err != nil {
+			return errors.Wrap(err, "cannot configure tls")
+		}
+
+		return run(ctx, harbor, resources)
+	}
+}
+
+// UpdateRoutesStatus reports the admission status of the routes in the Harbor status.
+func (r *Reconciler) UpdateRoutesStatus(ctx context.Context, harbor *goharborv1alpha1.Harbor) error {
+	if !r.routeAPIAvailable || harbor.Spec.Expose.GetType() != goharborv1alpha1.RouteExposeType {
+		harbor.Status.Routes = nil
+
+		return nil
+	}
+
+	harborResource, err := components.GetComponents(ctx, harbor)
+	if err != nil {
+		return errors.Wrap(err, "cannot get resources to manage")
+	}
+
+	var statuses []goharborv1alpha1.RouteAdmissionStatus
+
+	for _, component := range []*components.ComponentRunner{harborResource.Core, harborResource.Registry, harborResource.Portal, harborResource.ChartMuseum, harborResource.Notary} {
+		if component == nil {
+			continue
+		}
+
+		routes, err := component.GetRoutes(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, resource := range routes {
+			route := &routev1.Route{}
+
+			err := r.Client.Get(ctx, client.ObjectKey{
+				Namespace: resource.GetNamespace(),
+				Name:      resource.GetName(),
+			}, route)
+			if err != nil {
+				if client.IgnoreNotFound(err) != nil {
+					return errors.Wrapf(err, "cannot get route %s", resource.GetName())
+				}
+
+				statuses = append(statuses, goharborv1alpha1.RouteAdmissionStatus{
+					Name:     resource.GetName(),
+					Admitted: corev1.ConditionUnknown,
+					Reason:   "NotFound",
+				})
+
+				continue
+			}
+
+			statuses = append(statuses, getRouteAdmissionStatus(route))
+		}
+	}
+
+	harbor.Status.Routes = statuses
+
+	return nil
+}
+
+func getRouteAdmissionStatus(route *routev1.Route) goharborv1alpha1.RouteAdmissionStatus {
+	status := goharborv1alpha1.RouteAdmissionStatus{
+		Name:     route.GetName(),
+		Host:     route.Spec.Host,
+		Admitted: corev1.ConditionUnknown,
+	}
+
+	for _, ingress := range route.Status.Ingress {
+		for _, condition := range ingress.Conditions {
+			if condition.Type != routev1.RouteAdmitted {
+				continue
+			}
+
+			if ingress.Host != "" {
+				status.Host = ingress.Host
+			}
+
+			status.Reason = condition.Reason
+			status.Message = condition.Message
+			status.Admitted = condition.Status
+
+			if condition.Status == corev1.ConditionTrue {
+				return status
+			}
+		}
+	}
+
+	return status
+}
@@ -0,0 +1,196 @@
+package harbor
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components"
+)
+
+var _ = Describe("routes", func() {
+	Describe("A route", func() {
+		var route *routev1.Route
+
+		BeforeEach(func() {
+			route = &routev1.Route{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "harbor-core-api",
+				},
+				Spec: routev1.RouteSpec{
+					Host: "harbor.example.com",
+				},
+			}
+		})
+
+		Context("Not yet seen by any router", func() {
+			It("Should be reported as unknown", func() {
+				status := getRouteAdmissionStatus(route)
+				Expect(status.Name).To(Equal("harbor-core-api"))
+				Expect(status.Host).To(Equal("harbor.example.com"))
+				Expect(status.Admitted).To(Equal(corev1.ConditionUnknown))
+			})
+		})
+
+		Context("Rejected by a router and admitted by another one", func() {
+			BeforeEach(func() {
+				route.Status.Ingress = []routev1.RouteIngress{
+					{
+						Host:       "harbor.example.com",
+						RouterName: "sharded",
+						Conditions: []routev1.RouteIngressCondition{{
+							Type:    routev1.RouteAdmitted,
+							Status:  corev1.ConditionFalse,
+							Reason:  "HostAlreadyClaimed",
+							Message: "route already exists",
+						}},
+					}, {
+						Host:       "harbor.example.com",
+						RouterName: "default",
+						Conditions: []routev1.RouteIngressCondition{{
+							Type:   routev1.RouteAdmitted,
+							Status: corev1.ConditionTrue,
+						}},
+					},
+				}
+			})
+
+			It("Should be admitted", func() {
+				status := getRouteAdmissionStatus(route)
+				Expect(status.Admitted).To(Equal(corev1.ConditionTrue))
+				Expect(status.Reason).To(BeEmpty())
+			})
+		})
+
+		Context("Rejected by the router", func() {
+			BeforeEach(func() {
+				route.Status.Ingress = []routev1.RouteIngress{{
+					Host: "harbor.example.com",
+					Conditions: []routev1.RouteIngressCondition{{
+						Type:    routev1.RouteAdmitted,
+						Status:  corev1.ConditionFalse,
+						Reason:  "HostAlreadyClaimed",
+						Message: "route already exists",
+					}},
+				}}
+			})
+
+			It("Should report the reason", func() {
+				status := getRouteAdmissionStatus(route)
+				Expect(status.Admitted).To(Equal(corev1.ConditionFalse))
+				Expect(status.Reason).To(Equal("HostAlreadyClaimed"))
+				Expect(status.Message).To(Equal("route already exists"))
+			})
+		})
+	})
+
+	Describe("An Harbor exposed with ingresses", func() {
+		var r *Reconciler
+		var ctx context.Context
+		var h *goharborv1alpha1.Harbor
+
+		BeforeEach(func() {
+			r, ctx = setupTest(context.TODO())
+			h = &goharborv1alpha1.Harbor{
+				Status: goharborv1alpha1.HarborStatus{
+					Routes: []goharborv1alpha1.RouteAdmissionStatus{{
+						Name:     "harbor-core-api",
+						Admitted: corev1.ConditionTrue,
+					}},
+				},
+			}
+		})
+
+		It("Should not report routes", func() {
+			err := r.UpdateRoutesStatus(ctx, h)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(h.Status.Routes).To(BeEmpty())
+		})
+	})
+
+	Describe("An Harbor exposed with routes", func() {
+		var r *Reconciler
+		var ctx context.Context
+		var h *goharborv1alpha1.Harbor
+		var secret *corev1.Secret
+
+		newRoute := func() *routev1.Route {
+			return &routev1.Route{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "harbor-core-api",
+					Namespace: "registry",
+				},
+				Spec: routev1.RouteSpec{
+					TLS: &routev1.TLSConfig{Termination: routev1.TLSTerminationEdge},
+				},
+			}
+		}
+
+		checksum := func() string {
+			route := newRoute()
+
+			err := r.AnnotateRoutesTLSChecksum(ctx, h, []components.Resource{route})
+			Expect(err).ToNot(HaveOccurred())
+
+			return route.Annotations[RouteTLSChecksumAnnotation]
+		}
+
+		BeforeEach(func() {
+			r, ctx = setupTest(context.TODO())
+
+			h = &goharborv1alpha1.Harbor{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-harbor",
+					Namespace: "registry",
+				},
+				Spec: goharborv1alpha1.HarborSpec{
+					TLSSecretName: "public-tls",
+				},
+			}
+
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "public-tls",
+					Namespace: "registry",
+				},
+				Data: map[string][]byte{
+					corev1.TLSCertKey:       []byte("first"),
+					corev1.TLSPrivateKeyKey: []byte("key"),
+				},
+			}
+
+			r.Client = fake.NewFakeClientWithScheme(r.Scheme, secret)
+		})
+
+		It("Should annotate routes with the checksum of the injected certificate", func() {
+			route := newRoute()
+
+			err := r.InjectRoutesTLS(ctx, h, []components.Resource{route})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(route.Spec.TLS.Certificate).To(Equal("first"))
+			Expect(route.Annotations).To(HaveKeyWithValue(RouteTLSChecksumAnnotation, checksum()))
+		})
+
+		It("Should change the checksum when the certificate is renewed", func() {
+			previous := checksum()
+
+			secret.Data[corev1.TLSCertKey] = []byte("second")
+			r.Client = fake.NewFakeClientWithScheme(r.Scheme, secret)
+
+			Expect(checksum()).ToNot(Equal(previous))
+		})
+
+		It("Should ignore missing secret", func() {
+			r.Client = fake.NewFakeClientWithScheme(r.Scheme)
+
+			Expect(checksum()).To(BeEmpty())
+		})
+	})
+})
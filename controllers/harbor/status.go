@@ -6,9 +6,11 @@ import (
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
 	"github.com/goharbor/harbor-operator/pkg/factories/logger"
@@ -79,11 +81,31 @@ func (r *Reconciler) UpdateCondition(ctx context.Context, harbor *goharborv1alph
 	return nil
 }
 
-// UpdateStatus applies current in-memory statuses to the remote resource
+// RemoveCondition removes the condition, when it no longer applies.
+func (r *Reconciler) RemoveCondition(ctx context.Context, harbor *goharborv1alpha1.Harbor, conditionType goharborv1alpha1.HarborConditionType) {
+	for i, condition := range harbor.Status.Conditions {
+		if condition.Type == conditionType {
+			harbor.Status.Conditions = append(harbor.Status.Conditions[:i], harbor.Status.Conditions[i+1:]...)
+			return
+		}
+	}
+}
+
+// PatchStatus writes the in-memory status with a merge patch computed from the original harbor.
+// Unlike an update, the patch does not conflict with changes made to the Harbor during the reconciliation.
 // https://kubernetes.io/docs/tasks/access-kubernetes-api/custom-resources/custom-resource-definitions/#status-subresource
-func (r *Reconciler) UpdateStatus(ctx context.Context, result *ctrl.Result, harbor *goharborv1alpha1.Harbor) error {
-	err := r.Status().Update(ctx, harbor)
+func (r *Reconciler) PatchStatus(ctx context.Context, result *ctrl.Result, original, harbor *goharborv1alpha1.Harbor) error {
+	if equality.Semantic.DeepEqual(original.Status, harbor.Status) {
+		return nil
+	}
+
+	err := r.Status().Patch(ctx, harbor, client.MergeFrom(original))
 	if err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Get(ctx).Info("harbor deleted, status not patched")
+			return nil
+		}
+
 		result.Requeue = true
 
 		seconds, needWait := apierrors.SuggestsClientDelay(err)
@@ -91,13 +113,7 @@ func (r *Reconciler) UpdateStatus(ctx context.Context, result *ctrl.Result, harb
 			result.RequeueAfter = time.Second * time.Duration(seconds)
 		}
 
-		if apierrors.IsConflict(err) {
-			// the object has been modified; please apply your changes to the latest version and try again
-			logger.Get(ctx).Error(err, "cannot update status field")
-			return nil
-		}
-
-		return errors.Wrap(err, "cannot update status field")
+		return errors.Wrap(err, "cannot patch status field")
 	}
 
 	return nil
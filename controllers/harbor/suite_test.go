@@ -7,6 +7,7 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
@@ -32,6 +33,7 @@ func setupTest(ctx context.Context) (*Reconciler, context.Context) {
 	Expect(err).ToNot(HaveOccurred(), "failed to initialize scheme")
 
 	return &Reconciler{
-		Scheme: s,
+		Scheme:   s,
+		Recorder: record.NewFakeRecorder(100),
 	}, ctx
 }
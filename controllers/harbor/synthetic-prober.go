@@ -0,0 +1,198 @@
+package harbor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/pkg/metrics"
+)
+
+const (
+	DefaultSyntheticProbeTimeout = time.Minute
+
+	// syntheticProbeTick is the time between two checks of the Harbors to probe,
+	// each Harbor is probed according to its own interval.
+	syntheticProbeTick = 30 * time.Second
+)
+
+// SyntheticResult is the result of the last synthetic probe of a Harbor.
+type SyntheticResult struct {
+	Err       error
+	Duration  time.Duration
+	ProbeTime time.Time
+}
+
+// SyntheticProber periodically pushes and pulls an image on Harbors with a synthetic probe, in the background.
+// Harbors are enqueued through Source after every probe.
+type SyntheticProber struct {
+	client client.Reader
+	probe  SyntheticProbe
+	filter *EventFilter
+	log    logr.Logger
+
+	timeout time.Duration
+
+	lock    sync.RWMutex
+	results map[types.NamespacedName]*SyntheticResult
+	running map[types.NamespacedName]bool
+
+	events chan event.GenericEvent
+}
+
+var _ manager.Runnable = &SyntheticProber{}
+
+func NewSyntheticProber(reader client.Reader, probe SyntheticProbe, filter *EventFilter, log logr.Logger, timeout time.Duration) *SyntheticProber {
+	if timeout <= 0 {
+		timeout = DefaultSyntheticProbeTimeout
+	}
+
+	return &SyntheticProber{
+		client:  reader,
+		probe:   probe,
+		filter:  filter,
+		log:     log,
+		timeout: timeout,
+		results: map[types.NamespacedName]*SyntheticResult{},
+		running: map[types.NamespacedName]bool{},
+		events:  make(chan event.GenericEvent, healthEventsBufferSize),
+	}
+}
+
+// Source enqueues Harbors after every probe.
+func (p *SyntheticProber) Source() source.Source {
+	return &source.Channel{Source: p.events}
+}
+
+// Get returns the result of the last probe of the Harbor, nil if not probed yet.
+func (p *SyntheticProber) Get(harbor types.NamespacedName) *SyntheticResult {
+	if p == nil {
+		return nil
+	}
+
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	return p.results[harbor]
+}
+
+// Start probes Harbors whose interval elapsed until stop is closed.
+func (p *SyntheticProber) Start(stop <-chan struct{}) error {
+	ticker := time.NewTicker(syntheticProbeTick)
+	defer ticker.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		<-stop
+		cancel()
+	}()
+
+	for {
+		err := p.ProbeAll(ctx, time.Now())
+		if err != nil {
+			p.log.Error(err, "cannot probe harbors")
+		}
+
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProbeAll starts probes of Harbors whose interval elapsed at now, and forgets Harbors no longer probed.
+// Probes run in the background, a Harbor is not probed again until its previous probe finished.
+func (p *SyntheticProber) ProbeAll(ctx context.Context, now time.Time) error {
+	var harbors goharborv1alpha1.HarborList
+
+	err := p.client.List(ctx, &harbors)
+	if err != nil {
+		return errors.Wrap(err, "cannot list harbors")
+	}
+
+	existing := make(map[types.NamespacedName]bool, len(harbors.Items))
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for i := range harbors.Items {
+		harbor := &harbors.Items[i]
+
+		if harbor.Spec.SyntheticProbe == nil || harbor.Spec.Paused || !harbor.ObjectMeta.DeletionTimestamp.IsZero() || !p.filter.IsManaged(harbor) {
+			continue
+		}
+
+		key := types.NamespacedName{Namespace: harbor.GetNamespace(), Name: harbor.GetName()}
+		existing[key] = true
+
+		if p.running[key] {
+			continue
+		}
+
+		if result, ok := p.results[key]; ok && now.Sub(result.ProbeTime) < harbor.Spec.SyntheticProbe.GetInterval() {
+			continue
+		}
+
+		p.running[key] = true
+
+		go p.Probe(ctx, harbor)
+	}
+
+	for key := range p.results {
+		if !existing[key] {
+			delete(p.results, key)
+			metrics.ForgetSyntheticProbe(key)
+		}
+	}
+
+	return nil
+}
+
+// Probe probes the Harbor and enqueues it.
+func (p *SyntheticProber) Probe(ctx context.Context, harbor *goharborv1alpha1.Harbor) {
+	key := types.NamespacedName{Namespace: harbor.GetNamespace(), Name: harbor.GetName()}
+
+	probeCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	start := time.Now()
+
+	err := p.probe(probeCtx, harbor)
+
+	result := &SyntheticResult{
+		Err:       err,
+		Duration:  time.Since(start),
+		ProbeTime: start,
+	}
+
+	if err != nil {
+		p.log.V(1).Info("synthetic probe failed", "Harbor", key, "error", err.Error())
+	}
+
+	metrics.ObserveSyntheticProbe(key, result.Duration, err)
+
+	p.lock.Lock()
+	p.results[key] = result
+	delete(p.running, key)
+	p.lock.Unlock()
+
+	select {
+	case p.events <- event.GenericEvent{
+		Meta:   harbor,
+		Object: harbor,
+	}:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,120 @@
+package harbor
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+)
+
+var _ = Describe("SyntheticProber", func() {
+	var lister *harborLister
+	var prober *SyntheticProber
+	var probes int32
+	var probeErr atomic.Value
+	var ctx context.Context
+	var now time.Time
+
+	key := types.NamespacedName{Namespace: "registry", Name: "my"}
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		now = time.Now()
+
+		lister = &harborLister{
+			harbors: []goharborv1alpha1.Harbor{{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: key.Namespace,
+					Name:      key.Name,
+				},
+				Spec: goharborv1alpha1.HarborSpec{
+					SyntheticProbe: &goharborv1alpha1.HarborSyntheticProbe{},
+				},
+			}},
+		}
+
+		atomic.StoreInt32(&probes, 0)
+		probeErr.Store(errors.New(""))
+
+		probe := func(ctx context.Context, harbor *goharborv1alpha1.Harbor) error {
+			atomic.AddInt32(&probes, 1)
+
+			if err := probeErr.Load().(error); err.Error() != "" {
+				return err
+			}
+
+			return nil
+		}
+
+		prober = NewSyntheticProber(lister, probe, &EventFilter{}, zap.LoggerTo(GinkgoWriter, true), 0)
+	})
+
+	// probeAll probes Harbors at the given time and waits for probes to finish.
+	probeAll := func(at time.Time) {
+		Expect(prober.ProbeAll(ctx, at)).To(Succeed())
+
+		Eventually(func() int {
+			prober.lock.RLock()
+			defer prober.lock.RUnlock()
+
+			return len(prober.running)
+		}).Should(BeZero())
+	}
+
+	It("Should cache results and enqueue the harbor", func() {
+		Expect(prober.Get(key)).To(BeNil())
+
+		probeAll(now)
+
+		result := prober.Get(key)
+		Expect(result).ToNot(BeNil())
+		Expect(result.Err).ToNot(HaveOccurred())
+		Expect(prober.events).To(HaveLen(1))
+	})
+
+	It("Should report failures", func() {
+		probeErr.Store(errors.New("cannot push"))
+
+		probeAll(now)
+
+		Expect(prober.Get(key).Err).To(MatchError("cannot push"))
+	})
+
+	It("Should probe again only after the interval", func() {
+		probeAll(now)
+		Expect(atomic.LoadInt32(&probes)).To(BeEquivalentTo(1))
+
+		probeAll(now.Add(time.Minute))
+		Expect(atomic.LoadInt32(&probes)).To(BeEquivalentTo(1))
+
+		probeAll(time.Now().Add(goharborv1alpha1.DefaultSyntheticProbeInterval))
+		Expect(atomic.LoadInt32(&probes)).To(BeEquivalentTo(2))
+	})
+
+	It("Should skip harbors without synthetic probe", func() {
+		lister.harbors[0].Spec.SyntheticProbe = nil
+
+		probeAll(now)
+		Expect(atomic.LoadInt32(&probes)).To(BeZero())
+		Expect(prober.Get(key)).To(BeNil())
+	})
+
+	It("Should forget harbors no longer probed", func() {
+		probeAll(now)
+		Expect(prober.Get(key)).ToNot(BeNil())
+
+		lister.harbors[0].Spec.SyntheticProbe = nil
+
+		probeAll(now)
+		Expect(prober.Get(key)).To(BeNil())
+	})
+})
@@ -0,0 +1,293 @@
+package harbor
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/render"
+	"github.com/goharbor/harbor-operator/pkg/factories/logger"
+	"github.com/goharbor/harbor-operator/pkg/registry"
+)
+
+const (
+	SyntheticProbeRepository = "synthetic-probe"
+	SyntheticProbeTag        = "latest"
+
+	syntheticProbeSecretSuffix = "synthetic-probe"
+
+	// Keys of the robot account secret, in addition to the basic auth keys
+	RobotIDKey   = "robotID"
+	ProjectIDKey = "projectID"
+
+	robotNameSuffixLength = 4
+)
+
+// SyntheticProbe pushes an image, pulls it back and deletes it.
+type SyntheticProbe func(ctx context.Context, harbor *goharborv1alpha1.Harbor) error
+
+// GetSyntheticProbeSecretName returns the name of the secret containing the robot account of the synthetic probe.
+func GetSyntheticProbeSecretName(harbor *goharborv1alpha1.Harbor) string {
+	return harbor.NormalizeComponentName(syntheticProbeSecretSuffix)
+}
+
+// NewSyntheticProbe probes through the endpoint of the Harbor spec, with a robot account created as administrator.
+// The robot account is stored in a secret owned by the Harbor, and created again when its credentials are rejected.
+func NewSyntheticProbe(c client.Client, scheme *runtime.Scheme, userAgent string) SyntheticProbe {
+	return func(ctx context.Context, harbor *goharborv1alpha1.Harbor) error {
+		span, ctx := opentracing.StartSpanFromContext(ctx, "syntheticProbe")
+		defer span.Finish()
+
+		baseURL, transport, err := getSyntheticProbeEndpoint(ctx, c, harbor)
+		if err != nil {
+			return errors.Wrap(err, "cannot get endpoint")
+		}
+		defer transport.CloseIdleConnections()
+
+		httpClient := &http.Client{Transport: transport}
+
+		adminSecret, err := render.GetSecret(ctx, c, harbor.GetNamespace(), harbor.Spec.AdminPasswordSecret)
+		if err != nil {
+			return err
+		}
+
+		api := &harborAPI{
+			url:       baseURL,
+			client:    httpClient,
+			password:  render.GetSecretValue(adminSecret, goharborv1alpha1.HarborAdminPasswordKey, ""),
+			userAgent: userAgent,
+		}
+
+		project := harbor.Spec.SyntheticProbe.GetProject()
+
+		projectID, err := api.EnsureProject(ctx, project)
+		if err != nil {
+			return err
+		}
+
+		robot, err := getSyntheticProbeRobot(ctx, c, scheme, api, harbor, projectID)
+		if err != nil {
+			return errors.Wrap(err, "cannot get robot account")
+		}
+
+		registryClient := &registry.Client{
+			URL:        baseURL,
+			HTTPClient: httpClient,
+			Username:   robot.Name,
+			Password:   robot.Token,
+			UserAgent:  userAgent,
+		}
+
+		now := time.Now()
+
+		image, err := registry.NewImage([]byte(now.Format(time.RFC3339Nano)), now)
+		if err != nil {
+			return errors.Wrap(err, "cannot generate image")
+		}
+
+		repository := fmt.Sprintf("%s/%s", project, SyntheticProbeRepository)
+
+		err = registryClient.Push(ctx, repository, SyntheticProbeTag, image)
+		if err != nil {
+			if errors.Cause(err) == registry.ErrUnauthorized {
+				forgetSyntheticProbeRobot(ctx, c, api, harbor, projectID, robot.ID)
+			}
+
+			return errors.Wrapf(err, "cannot push %s:%s", repository, SyntheticProbeTag)
+		}
+
+		err = registryClient.Pull(ctx, repository, SyntheticProbeTag, image)
+		if err != nil {
+			return errors.Wrapf(err, "cannot pull %s:%s", repository, SyntheticProbeTag)
+		}
+
+		return api.DeleteTag(ctx, repository, SyntheticProbeTag)
+	}
+}
+
+// getSyntheticProbeEndpoint returns the url and the transport to reach the internal core service or the public url.
+func getSyntheticProbeEndpoint(ctx context.Context, reader client.Reader, harbor *goharborv1alpha1.Harbor) (*url.URL, *http.Transport, error) {
+	if harbor.Spec.SyntheticProbe.GetEndpoint() == goharborv1alpha1.PublicSyntheticProbeEndpoint {
+		publicURL, err := url.Parse(harbor.Spec.PublicURL)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "invalid public url")
+		}
+
+		transport, err := newPublicTransport(ctx, reader, harbor)
+
+		return publicURL, transport, err
+	}
+
+	transport, err := newCoreTransport(ctx, reader, harbor)
+
+	return getCoreURL(harbor), transport, err
+}
+
+// newPublicTransport returns a transport trusting the system CAs and the trusted CA of the Harbor, if any.
+func newPublicTransport(ctx context.Context, reader client.Reader, harbor *goharborv1alpha1.Harbor) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if harbor.Spec.TrustedCA == nil {
+		return transport, nil
+	}
+
+	bundle, err := getTrustedCABundle(ctx, reader, harbor)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get trusted ca")
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(bundle) {
+		return nil, errors.New("no CA certificate in trusted ca")
+	}
+
+	transport.TLSClientConfig = &tls.Config{
+		RootCAs:    pool,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	return transport, nil
+}
+
+// getSyntheticProbeRobot returns the robot account from its secret,
+// or creates it when the secret is missing or refers to another project.
+func getSyntheticProbeRobot(ctx context.Context, c client.Client, scheme *runtime.Scheme, api *harborAPI, harbor *goharborv1alpha1.Harbor, projectID int64) (*harborRobot, error) {
+	secret := &corev1.Secret{}
+
+	err := c.Get(ctx, client.ObjectKey{
+		Namespace: harbor.GetNamespace(),
+		Name:      GetSyntheticProbeSecretName(harbor),
+	}, secret)
+	if client.IgnoreNotFound(err) != nil {
+		return nil, errors.Wrap(err, "cannot get secret")
+	}
+
+	if err == nil {
+		robotID, _ := strconv.ParseInt(render.GetSecretValue(secret, RobotIDKey, ""), 10, 64)
+		robotProjectID, _ := strconv.ParseInt(render.GetSecretValue(secret, ProjectIDKey, ""), 10, 64)
+
+		if robotProjectID == projectID {
+			return &harborRobot{
+				ID:    robotID,
+				Name:  render.GetSecretValue(secret, corev1.BasicAuthUsernameKey, ""),
+				Token: render.GetSecretValue(secret, corev1.BasicAuthPasswordKey, ""),
+			}, nil
+		}
+
+		// The project changed
+		forgetSyntheticProbeRobot(ctx, c, api, harbor, robotProjectID, robotID)
+	}
+
+	suffix := make([]byte, robotNameSuffixLength)
+
+	_, err = rand.Read(suffix)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot generate robot account name")
+	}
+
+	robot, err := api.CreateRobot(ctx, projectID, fmt.Sprintf("%s-%s", harbor.NormalizeComponentName(syntheticProbeSecretSuffix), hex.EncodeToString(suffix)),
+		fmt.Sprintf("Synthetic probe of %s/%s by the operator", harbor.GetNamespace(), harbor.GetName()))
+	if err != nil {
+		return nil, err
+	}
+
+	secret = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GetSyntheticProbeSecretName(harbor),
+			Namespace: harbor.GetNamespace(),
+			Labels: map[string]string{
+				"app":    syntheticProbeSecretSuffix,
+				"harbor": harbor.GetName(),
+			},
+		},
+		Type: corev1.SecretTypeBasicAuth,
+		StringData: map[string]string{
+			corev1.BasicAuthUsernameKey: robot.Name,
+			corev1.BasicAuthPasswordKey: robot.Token,
+			RobotIDKey:                  strconv.FormatInt(robot.ID, 10),
+			ProjectIDKey:                strconv.FormatInt(projectID, 10),
+		},
+	}
+
+	err = controllerutil.SetControllerReference(harbor, secret, scheme)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot set controller reference")
+	}
+
+	err = c.Create(ctx, secret)
+	if err != nil {
+		_ = api.DeleteRobot(ctx, projectID, robot.ID)
+
+		return nil, errors.Wrap(err, "cannot create secret")
+	}
+
+	return robot, nil
+}
+
+// forgetSyntheticProbeRobot deletes the robot account and its secret, so a new one is created by the next probe.
+// Errors are ignored, the robot account may already have been deleted.
+func forgetSyntheticProbeRobot(ctx context.Context, c client.Client, api *harborAPI, harbor *goharborv1alpha1.Harbor, projectID, robotID int64) {
+	if robotID != 0 {
+		_ = api.DeleteRobot(ctx, projectID, robotID)
+	}
+
+	_ = c.Delete(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GetSyntheticProbeSecretName(harbor),
+			Namespace: harbor.GetNamespace(),
+		},
+	})
+}
+
+// UpdateFunctionalStatus sets the Functional condition from the result of the last synthetic probe.
+func (r *Reconciler) UpdateFunctionalStatus(ctx context.Context, harbor *goharborv1alpha1.Harbor) error {
+	if harbor.Spec.SyntheticProbe == nil {
+		r.RemoveCondition(ctx, harbor, goharborv1alpha1.FunctionalConditionType)
+		return nil
+	}
+
+	result := r.syntheticProber.Get(types.NamespacedName{Namespace: harbor.GetNamespace(), Name: harbor.GetName()})
+	if result == nil {
+		logger.Get(ctx).V(1).Info("synthetic probe not run yet")
+		return nil
+	}
+
+	wasFunctional := r.GetConditionStatus(ctx, harbor, goharborv1alpha1.FunctionalConditionType)
+
+	if result.Err != nil {
+		if wasFunctional != corev1.ConditionFalse {
+			r.Recorder.Event(harbor, corev1.EventTypeWarning, PushPullFailedReason, result.Err.Error())
+		}
+
+		return r.UpdateCondition(ctx, harbor, goharborv1alpha1.FunctionalConditionType, corev1.ConditionFalse, PushPullFailedReason, result.Err.Error())
+	}
+
+	message := fmt.Sprintf("Pushed and pulled %s/%s in %s", harbor.Spec.SyntheticProbe.GetProject(), SyntheticProbeRepository, result.Duration.Round(time.Millisecond))
+
+	if wasFunctional != corev1.ConditionTrue {
+		r.Recorder.Event(harbor, corev1.EventTypeNormal, PushPullSucceededReason, message)
+	}
+
+	return r.UpdateCondition(ctx, harbor, goharborv1alpha1.FunctionalConditionType, corev1.ConditionTrue, PushPullSucceededReason, message)
+}
@@ -0,0 +1,102 @@
+package harbor
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+)
+
+var _ = Describe("Synthetic probe endpoint", func() {
+	var r *Reconciler
+	var ctx context.Context
+	var server *httptest.Server
+	var harbor *goharborv1alpha1.Harbor
+
+	BeforeEach(func() {
+		r, ctx = setupTest(context.TODO())
+
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		harbor = &goharborv1alpha1.Harbor{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "my-harbor",
+				Namespace: "registry",
+			},
+			Spec: goharborv1alpha1.HarborSpec{
+				PublicURL: server.URL,
+				SyntheticProbe: &goharborv1alpha1.HarborSyntheticProbe{
+					Endpoint: goharborv1alpha1.PublicSyntheticProbeEndpoint,
+				},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	get := func(transport *http.Transport) error {
+		defer transport.CloseIdleConnections()
+
+		response, err := (&http.Client{Transport: transport}).Get(server.URL)
+		if err == nil {
+			response.Body.Close()
+		}
+
+		return err
+	}
+
+	It("Should trust the trusted CA of the harbor", func() {
+		harbor.Spec.TrustedCA = &goharborv1alpha1.TrustedCA{
+			ConfigMap: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "trusted-ca"},
+				Key:                  "ca.crt",
+			},
+		}
+
+		reader := fake.NewFakeClientWithScheme(r.Scheme, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "trusted-ca",
+				Namespace: "registry",
+			},
+			Data: map[string]string{
+				"ca.crt": string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})),
+			},
+		})
+
+		url, transport, err := getSyntheticProbeEndpoint(ctx, reader, harbor)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(url.String()).To(Equal(server.URL))
+		Expect(get(transport)).To(Succeed())
+	})
+
+	It("Should not trust other CAs", func() {
+		_, transport, err := getSyntheticProbeEndpoint(ctx, fake.NewFakeClientWithScheme(r.Scheme), harbor)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(get(transport)).ToNot(Succeed())
+	})
+
+	It("Should report missing trusted CA", func() {
+		harbor.Spec.TrustedCA = &goharborv1alpha1.TrustedCA{
+			Secret: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "trusted-ca"},
+				Key:                  "ca.crt",
+			},
+		}
+
+		_, _, err := getSyntheticProbeEndpoint(ctx, fake.NewFakeClientWithScheme(r.Scheme), harbor)
+		Expect(err).To(MatchError(ContainSubstring("cannot get trusted ca")))
+	})
+})
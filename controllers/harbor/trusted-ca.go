@@ -0,0 +1,121 @@
+package harbor
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/render"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/trustedca"
+)
+
+const (
+	// TrustedCAChecksumAnnotation is set on pod templates so changes of the trusted CA trigger a rolling restart.
+	TrustedCAChecksumAnnotation = "trusted-ca/checksum"
+)
+
+// getTrustedCABundle returns the PEM encoded certificates of the trusted CA secret or configmap.
+func getTrustedCABundle(ctx context.Context, reader client.Reader, harbor *goharborv1alpha1.Harbor) ([]byte, error) {
+	ca := harbor.Spec.TrustedCA
+
+	if ca.Secret != nil {
+		secret, err := render.GetSecret(ctx, reader, harbor.GetNamespace(), ca.Secret.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		return []byte(render.GetSecretValue(secret, ca.Secret.Key, "")), nil
+	}
+
+	configMap := &corev1.ConfigMap{}
+
+	err := reader.Get(ctx, client.ObjectKey{
+		Namespace: harbor.GetNamespace(),
+		Name:      ca.ConfigMap.Name,
+	}, configMap)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get configmap %s", ca.ConfigMap.Name)
+	}
+
+	return []byte(configMap.Data[ca.ConfigMap.Key]), nil
+}
+
+// GetTrustedCACheckSum returns the checksum of the trusted CA certificates.
+// A missing secret or configmap is ignored, pods cannot start until it is created.
+func (r *Reconciler) GetTrustedCACheckSum(ctx context.Context, harbor *goharborv1alpha1.Harbor) (string, error) {
+	if harbor.Spec.TrustedCA == nil {
+		return "", nil
+	}
+
+	bundle, err := getTrustedCABundle(ctx, r.Client, harbor)
+	if err != nil {
+		if apierrors.IsNotFound(errors.Cause(err)) {
+			return "", nil
+		}
+
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", sha256.Sum256(bundle)), nil
+}
+
+// WithTrustedCAChecksum returns a ComponentRun annotating deployments mounting the trusted CA with its checksum before calling run.
+func (r *Reconciler) WithTrustedCAChecksum(run components.ComponentRun) components.ComponentRun {
+	return func(ctx context.Context, harbor *goharborv1alpha1.Harbor, resources []components.Resource) error {
+		checksum, err := r.GetTrustedCACheckSum(ctx, harbor)
+		if err != nil {
+			return errors.Wrap(err, "cannot compute trusted ca checksum")
+		}
+
+		if checksum == "" {
+			return run(ctx, harbor, resources)
+		}
+
+		for _, resource := range resources {
+			deployment, ok := resource.(*appsv1.Deployment)
+			if !ok {
+				return errors.Errorf("unexpected resource %+v", resource)
+			}
+
+			if !mountsTrustedCA(deployment) {
+				continue
+			}
+
+			if deployment.Spec.Template.Annotations == nil {
+				deployment.Spec.Template.Annotations = map[string]string{}
+			}
+
+			deployment.Spec.Template.Annotations[TrustedCAChecksumAnnotation] = checksum
+		}
+
+		return run(ctx, harbor, resources)
+	}
+}
+
+// RolloutChangedTrustedCA patches existing deployments whose trusted CA changed.
+// Resources must have been annotated by WithTrustedCAChecksum.
+func (r *Reconciler) RolloutChangedTrustedCA(ctx context.Context, harbor *goharborv1alpha1.Harbor, resources []components.Resource) error {
+	if harbor.Spec.TrustedCA == nil {
+		return nil
+	}
+
+	return r.rolloutChecksum(ctx, harbor, resources, TrustedCAChecksumAnnotation, TrustedCAChangedReason, "its trusted CA changed")
+}
+
+func mountsTrustedCA(deployment *appsv1.Deployment) bool {
+	for _, volume := range deployment.Spec.Template.Spec.Volumes {
+		if volume.Name == trustedca.VolumeName {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,127 @@
+package harbor
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components"
+	"github.com/goharbor/harbor-operator/controllers/harbor/components/trustedca"
+)
+
+var _ = Describe("Trusted CA", func() {
+	var r *Reconciler
+	var ctx context.Context
+	var harbor *goharborv1alpha1.Harbor
+	var configMap *corev1.ConfigMap
+
+	newDeployment := func(name string, volumes ...corev1.Volume) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "registry",
+			},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Volumes: volumes,
+					},
+				},
+			},
+		}
+	}
+
+	annotate := func(resources ...components.Resource) {
+		err := r.WithTrustedCAChecksum(func(context.Context, *goharborv1alpha1.Harbor, []components.Resource) error {
+			return nil
+		})(ctx, harbor, resources)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	BeforeEach(func() {
+		r, ctx = setupTest(context.TODO())
+
+		harbor = &goharborv1alpha1.Harbor{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "my-harbor",
+				Namespace: "registry",
+			},
+			Spec: goharborv1alpha1.HarborSpec{
+				TrustedCA: &goharborv1alpha1.TrustedCA{
+					ConfigMap: &corev1.ConfigMapKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "trusted-ca"},
+						Key:                  "ca.crt",
+					},
+				},
+			},
+		}
+
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "trusted-ca",
+				Namespace: "registry",
+			},
+			Data: map[string]string{"ca.crt": "first"},
+		}
+
+		r.Client = fake.NewFakeClientWithScheme(r.Scheme, configMap)
+	})
+
+	It("Should annotate deployments mounting the trusted CA", func() {
+		withCA := newDeployment("core", trustedca.GetVolumes(harbor)...)
+		withoutCA := newDeployment("portal")
+
+		annotate(withCA, withoutCA)
+
+		Expect(withCA.Spec.Template.Annotations).To(HaveKey(TrustedCAChecksumAnnotation))
+		Expect(withoutCA.Spec.Template.Annotations).ToNot(HaveKey(TrustedCAChecksumAnnotation))
+	})
+
+	It("Should ignore missing trusted CA", func() {
+		r.Client = fake.NewFakeClientWithScheme(r.Scheme)
+
+		deployment := newDeployment("core", trustedca.GetVolumes(harbor)...)
+
+		annotate(deployment)
+
+		Expect(deployment.Spec.Template.Annotations).ToNot(HaveKey(TrustedCAChecksumAnnotation))
+	})
+
+	It("Should roll out deployments when the trusted CA changes", func() {
+		existing := newDeployment("core", trustedca.GetVolumes(harbor)...)
+		annotate(existing)
+
+		configMap.Data["ca.crt"] = "second"
+		r.Client = fake.NewFakeClientWithScheme(r.Scheme, configMap, existing.DeepCopy())
+
+		desired := newDeployment("core", trustedca.GetVolumes(harbor)...)
+		annotate(desired)
+
+		Expect(desired.Spec.Template.Annotations[TrustedCAChecksumAnnotation]).
+			ToNot(Equal(existing.Spec.Template.Annotations[TrustedCAChecksumAnnotation]))
+
+		// Events are emitted on behalf of a component
+		harbor.Spec.Components.Portal = &goharborv1alpha1.PortalComponent{}
+		harbor.Default()
+
+		harborComponents, err := components.GetComponents(ctx, harbor)
+		Expect(err).ToNot(HaveOccurred())
+
+		err = harborComponents.ParallelRun(ctx, harbor, func(ctx context.Context, harbor *goharborv1alpha1.Harbor, _ *components.ComponentRunner) error {
+			return r.RolloutChangedTrustedCA(ctx, harbor, []components.Resource{desired})
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		result := &appsv1.Deployment{}
+		Expect(r.Client.Get(ctx, client.ObjectKey{Namespace: "registry", Name: "core"}, result)).To(Succeed())
+		Expect(result.Spec.Template.Annotations).To(HaveKeyWithValue(TrustedCAChecksumAnnotation, desired.Spec.Template.Annotations[TrustedCAChecksumAnnotation]))
+	})
+})
@@ -3,8 +3,8 @@ package main
 import (
 	"os"
 
-	"github.com/go-logr/logr"
 	"github.com/ovh/configstore"
+	uberzap "go.uber.org/zap"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
@@ -12,6 +12,8 @@ import (
 	// +kubebuilder:scaffold:imports
 
 	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/pkg/cmd/render"
+	"github.com/goharbor/harbor-operator/pkg/config"
 	"github.com/goharbor/harbor-operator/pkg/controllers/harbor"
 	"github.com/goharbor/harbor-operator/pkg/factories/logger"
 	"github.com/goharbor/harbor-operator/pkg/manager"
@@ -28,16 +30,14 @@ const (
 	exitCodeFailure = 1
 )
 
-func getLogger() logr.Logger {
-	development, err := configstore.Filter().GetItemValueBool("dev-mode")
-	if err != nil {
-		development = true
+// getConfig loads the configuration file, or the configstore keys when no file is set.
+func getConfig() (*config.OperatorConfiguration, string, error) {
+	path := os.Getenv(config.FileEnv)
+	if path != "" {
+		configuration, err := config.Load(path)
+		return configuration, path, err
 	}
 
-	return zap.Logger(development)
-}
-
-func main() {
 	// uses env var CONFIGURATION_FROM=... to initialize config
 	// examples of possible values:
 	// CONFIGURATION_FROM=file:/etc/cfg1.conf,file:/etc/cfg2.conf
@@ -45,12 +45,37 @@ func main() {
 	// ...
 	configstore.InitFromEnvironment()
 
+	configuration, err := config.FromConfigstore()
+
+	return configuration, "", err
+}
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == render.CommandName {
+		// Offline rendering does not need any configuration nor cluster
+		ctx := logger.Context(zap.Logger(false))
+
+		os.Exit(render.Main(ctx, OperatorName, OperatorVersion, os.Args[2:]))
+	}
+
+	configuration, path, err := getConfig()
+	if err != nil {
+		zap.Logger(true).WithName("setup").Error(err, "invalid configuration")
+		os.Exit(exitCodeFailure)
+	}
+
+	level := uberzap.NewAtomicLevelAt(configuration.GetLogLevel())
+	ctrl.SetLogger(zap.New(zap.UseDevMode(configuration.Log.Development), zap.Level(&level)))
+
 	setupLog := ctrl.Log.WithName("setup")
 
 	ctx := logger.Context(setupLog)
 
-	logger := getLogger()
-	ctrl.SetLogger(logger)
+	store, err := config.NewStore(configuration, level)
+	if err != nil {
+		setupLog.Error(err, "unable to apply configuration")
+		os.Exit(exitCodeFailure)
+	}
 
 	scheme, err := scheme.New(ctx)
 	if err != nil {
@@ -58,20 +83,31 @@ func main() {
 		os.Exit(exitCodeFailure)
 	}
 
-	mgr, err := manager.New(ctx, scheme)
+	harborConfig, err := configuration.GetHarborConfig()
+	if err != nil {
+		setupLog.Error(err, "unable to get configuration", "controller", "Harbor")
+		os.Exit(exitCodeFailure)
+	}
+
+	if harborConfig.LegacyCertificates != nil {
+		setupLog.Info("deprecated configstore slice, set spec.certificates.registryToken and spec.certificates.notary of Harbors instead",
+			"slice", harbor.CertificateEncryptionSlice)
+	}
+
+	mgr, err := manager.New(ctx, scheme, configuration.Manager, harborConfig.Namespaces)
 	if err != nil {
 		setupLog.Error(err, "unable to create manager")
 		os.Exit(exitCodeFailure)
 	}
 
-	traCon, err := tracing.New(ctx, OperatorName, OperatorVersion)
+	traCon, err := tracing.New(ctx, OperatorName, OperatorVersion, configuration.Tracing)
 	if err != nil {
 		setupLog.Error(err, "unable to create tracer")
 		os.Exit(exitCodeFailure)
 	}
 	defer traCon.Close()
 
-	reconciler, err := harbor.New(ctx, OperatorName, OperatorVersion)
+	reconciler, err := harbor.New(ctx, OperatorName, OperatorVersion, harborConfig)
 	if err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Harbor")
 		os.Exit(exitCodeFailure)
@@ -82,6 +118,25 @@ func main() {
 		os.Exit(exitCodeFailure)
 	}
 
+	store.AddRequeueSetter(reconciler)
+	goharborv1alpha1.SetHarborClassReader(reconciler.GetHarborClassReader())
+
+	if path != "" {
+		err = mgr.Add(&config.Reloader{Path: path, Store: store, Log: ctrl.Log.WithName("config")})
+		if err != nil {
+			setupLog.Error(err, "unable to add configuration reloader")
+			os.Exit(exitCodeFailure)
+		}
+	}
+
+	if *configuration.DebugAddress != "" {
+		err = mgr.Add(&config.Server{Address: *configuration.DebugAddress, Store: store, Log: ctrl.Log.WithName("config")})
+		if err != nil {
+			setupLog.Error(err, "unable to add configuration server")
+			os.Exit(exitCodeFailure)
+		}
+	}
+
 	if err := (&goharborv1alpha1.Harbor{}).SetupWebhookWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create webhook", "webhook", "Harbor")
 		os.Exit(exitCodeFailure)
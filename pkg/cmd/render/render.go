@@ -0,0 +1,347 @@
+package render
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor"
+	"github.com/goharbor/harbor-operator/pkg/scheme"
+)
+
+const (
+	CommandName = "render"
+
+	DefaultNamespace = "default"
+
+	// MaskedValue replaces values of rendered secrets.
+	MaskedValue = "*****"
+
+	stdinFileName = "-"
+)
+
+// Options of the render command.
+type Options struct {
+	// File containing the Harbor, and optionally the secrets and configmaps it references.
+	File string
+	// Namespace of the Harbor, when not set in the manifest.
+	Namespace string
+	// Components to render, all if empty.
+	Components []string
+	// ShowSecrets disables masking of secret values.
+	ShowSecrets bool
+}
+
+// ParseOptions parses the command line arguments, without the command name.
+func ParseOptions(args []string, output io.Writer) (*Options, error) {
+	options := &Options{}
+
+	flags := flag.NewFlagSet(CommandName, flag.ContinueOnError)
+	flags.SetOutput(output)
+	flags.Usage = func() {
+		fmt.Fprintf(output, "Usage: harbor-operator %s -f harbor.yaml [flags]\n\n", CommandName)
+		fmt.Fprintln(output, "Print the resources the operator creates for a Harbor, without connecting to a cluster.")
+		fmt.Fprintln(output, "Secrets and configmaps referenced by the Harbor can be added to the file, missing ones are replaced by empty ones.")
+		fmt.Fprintln(output)
+		flags.PrintDefaults()
+	}
+
+	var componentList string
+
+	flags.StringVar(&options.File, "f", "", "file containing the Harbor manifest, - for the standard input")
+	flags.StringVar(&options.Namespace, "namespace", DefaultNamespace, "namespace of the Harbor if not set in the manifest")
+	flags.StringVar(&componentList, "component", "", "comma separated list of components to render, all by default")
+	flags.BoolVar(&options.ShowSecrets, "show-secrets", false, "print secret values instead of masking them")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+
+	if flags.NArg() > 0 {
+		return nil, errors.Errorf("unexpected arguments %v", flags.Args())
+	}
+
+	if options.File == "" {
+		return nil, errors.New("flag -f is required")
+	}
+
+	if componentList != "" {
+		for _, name := range strings.Split(componentList, ",") {
+			options.Components = append(options.Components, strings.TrimSpace(name))
+		}
+	}
+
+	return options, nil
+}
+
+// Main runs the render command and returns the process exit code.
+func Main(ctx context.Context, name, version string, args []string) int {
+	options, err := ParseOptions(args, os.Stderr)
+	if err != nil {
+		if err != flag.ErrHelp {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", CommandName, err)
+		}
+
+		return 2 // nolint:gomnd
+	}
+
+	input := io.Reader(os.Stdin)
+
+	if options.File != stdinFileName {
+		file, err := os.Open(options.File)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", CommandName, err)
+
+			return 1
+		}
+		defer file.Close()
+
+		input = file
+	}
+
+	err = Run(ctx, name, version, options, input, os.Stdout, os.Stderr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", CommandName, err)
+
+		return 1
+	}
+
+	return 0
+}
+
+// Run renders the resources of the Harbor read from input and writes them to output as YAML documents.
+// Warnings, such as references to secrets not found in input, are written to warnings.
+func Run(ctx context.Context, name, version string, options *Options, input io.Reader, output, warnings io.Writer) error {
+	s, err := scheme.New(ctx)
+	if err != nil {
+		return errors.Wrap(err, "cannot create scheme")
+	}
+
+	h, reader, err := decode(s, input, options.Namespace, warnings)
+	if err != nil {
+		return err
+	}
+
+	h.Default()
+
+	err = h.Validate()
+	if err != nil {
+		return errors.Wrap(err, "invalid harbor")
+	}
+
+	r := &harbor.Reconciler{
+		Name:    name,
+		Version: version,
+		Scheme:  s,
+	}
+
+	resources, err := r.Render(ctx, h, reader, options.Components...)
+	if err != nil {
+		return errors.Wrap(err, "cannot render harbor")
+	}
+
+	for _, missing := range reader.GetMissing() {
+		fmt.Fprintf(warnings, "warning: %s not found in input, rendered as empty\n", missing)
+	}
+
+	for _, resource := range resources {
+		if secret, ok := resource.(*corev1.Secret); ok && !options.ShowSecrets {
+			resource = maskSecret(secret)
+		}
+
+		data, err := yaml.Marshal(resource)
+		if err != nil {
+			return errors.Wrapf(err, "cannot marshal %s", resource.GetName())
+		}
+
+		_, err = fmt.Fprintf(output, "---\n%s", data)
+		if err != nil {
+			return errors.Wrap(err, "cannot write output")
+		}
+	}
+
+	return nil
+}
+
+// decode reads the Harbor and the secrets and configmaps from a stream of YAML or JSON documents.
+// Other documents are skipped.
+func decode(s *runtime.Scheme, input io.Reader, namespace string, warnings io.Writer) (*goharborv1alpha1.Harbor, *objectReader, error) {
+	deserializer := serializer.NewCodecFactory(s).UniversalDeserializer()
+	documents := utilyaml.NewYAMLReader(bufio.NewReader(input))
+
+	var h *goharborv1alpha1.Harbor
+
+	reader := &objectReader{
+		secrets:    map[client.ObjectKey]*corev1.Secret{},
+		configMaps: map[client.ObjectKey]*corev1.ConfigMap{},
+	}
+
+	for i := 0; ; i++ {
+		document, err := documents.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "cannot read document %d", i)
+		}
+
+		if strings.TrimSpace(string(document)) == "" {
+			continue
+		}
+
+		obj, gvk, err := deserializer.Decode(document, nil, nil)
+		if runtime.IsNotRegisteredError(err) {
+			fmt.Fprintf(warnings, "warning: document %d: kind %s ignored\n", i, gvk.Kind)
+			continue
+		}
+
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "cannot decode document %d", i)
+		}
+
+		switch obj := obj.(type) {
+		case *goharborv1alpha1.Harbor:
+			if h != nil {
+				return nil, nil, errors.Errorf("document %d: only one harbor is supported", i)
+			}
+
+			h = obj
+		case *corev1.Secret:
+			reader.secrets[client.ObjectKey{Namespace: obj.GetNamespace(), Name: obj.GetName()}] = obj
+		case *corev1.ConfigMap:
+			reader.configMaps[client.ObjectKey{Namespace: obj.GetNamespace(), Name: obj.GetName()}] = obj
+		default:
+			fmt.Fprintf(warnings, "warning: document %d: kind %s ignored\n", i, obj.GetObjectKind().GroupVersionKind().Kind)
+		}
+	}
+
+	if h == nil {
+		return nil, nil, errors.New("no harbor found")
+	}
+
+	if h.GetNamespace() == "" {
+		h.SetNamespace(namespace)
+	}
+
+	reader.namespace = h.GetNamespace()
+
+	return h, reader, nil
+}
+
+// maskSecret returns a copy of the secret with every value masked.
+func maskSecret(secret *corev1.Secret) *corev1.Secret {
+	result := secret.DeepCopy()
+	result.StringData = map[string]string{}
+
+	for key := range secret.Data {
+		result.StringData[key] = MaskedValue
+	}
+
+	for key := range secret.StringData {
+		result.StringData[key] = MaskedValue
+	}
+
+	result.Data = nil
+
+	return result
+}
+
+// objectReader serves secrets and configmaps read from the input.
+// Missing objects are returned empty, so that the harbor can be rendered anyway, and recorded.
+type objectReader struct {
+	namespace  string
+	secrets    map[client.ObjectKey]*corev1.Secret
+	configMaps map[client.ObjectKey]*corev1.ConfigMap
+
+	lock    sync.Mutex
+	missing map[string]bool
+}
+
+func (o *objectReader) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	// Objects of the input without namespace belong to the namespace of the harbor
+	unqualified := client.ObjectKey{Name: key.Name}
+
+	switch obj := obj.(type) {
+	case *corev1.Secret:
+		if secret, ok := o.secrets[key]; ok {
+			secret.DeepCopyInto(obj)
+			return nil
+		}
+
+		if secret, ok := o.secrets[unqualified]; ok && key.Namespace == o.namespace {
+			secret.DeepCopyInto(obj)
+			return nil
+		}
+
+		o.setMissing("secret", key)
+	case *corev1.ConfigMap:
+		if configMap, ok := o.configMaps[key]; ok {
+			configMap.DeepCopyInto(obj)
+			return nil
+		}
+
+		if configMap, ok := o.configMaps[unqualified]; ok && key.Namespace == o.namespace {
+			configMap.DeepCopyInto(obj)
+			return nil
+		}
+
+		o.setMissing("configmap", key)
+	default:
+		return errors.Errorf("unsupported object %T", obj)
+	}
+
+	if accessor, ok := obj.(metav1.Object); ok {
+		accessor.SetNamespace(key.Namespace)
+		accessor.SetName(key.Name)
+	}
+
+	return nil
+}
+
+func (o *objectReader) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+	return errors.New("list is not supported while rendering")
+}
+
+func (o *objectReader) setMissing(kind string, key client.ObjectKey) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if o.missing == nil {
+		o.missing = map[string]bool{}
+	}
+
+	o.missing[fmt.Sprintf("%s %s", kind, key)] = true
+}
+
+// GetMissing returns the sorted list of requested objects not found in the input.
+func (o *objectReader) GetMissing() []string {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	result := make([]string, 0, len(o.missing))
+	for missing := range o.missing {
+		result = append(result, missing)
+	}
+
+	sort.Strings(result)
+
+	return result
+}
@@ -0,0 +1,140 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/goharbor/harbor-operator/pkg/factories/logger"
+)
+
+func TestRender(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecsWithDefaultAndCustomReporters(t, "Render Command Suite", []Reporter{envtest.NewlineReporter{}})
+}
+
+const harborManifest = `apiVersion: goharbor.io/v1alpha1
+kind: Harbor
+metadata:
+  name: sample
+spec:
+  version: 1.10.0
+  publicURL: https://harbor.example.com
+  adminPasswordSecret: admin
+  components:
+    core:
+      databaseSecret: core-database
+    registry:
+      cacheSecret: registry-cache
+    portal: {}
+    jobService:
+      redisSecret: jobservice-redis
+`
+
+const secretManifest = `---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: registry-cache
+stringData:
+  url: redis://redis:6379/1
+`
+
+var _ = Describe("Render command", func() {
+	var ctx context.Context
+	var output, warnings *bytes.Buffer
+
+	BeforeEach(func() {
+		ctx = logger.Context(zap.LoggerTo(GinkgoWriter, true))
+		output, warnings = &bytes.Buffer{}, &bytes.Buffer{}
+	})
+
+	Context("ParseOptions", func() {
+		It("Should require a file", func() {
+			_, err := ParseOptions([]string{"--component", "core"}, ioutil.Discard)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should split components", func() {
+			options, err := ParseOptions([]string{"-f", "-", "--component", "core, registry"}, ioutil.Discard)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(options.File).To(Equal("-"))
+			Expect(options.Namespace).To(Equal(DefaultNamespace))
+			Expect(options.Components).To(Equal([]string{"core", "registry"}))
+		})
+	})
+
+	Context("Run", func() {
+		It("Should render every component", func() {
+			err := Run(ctx, "harbor-operator", "test", &Options{Namespace: "registry"}, strings.NewReader(harborManifest+secretManifest), output, warnings)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(output.String()).To(HavePrefix("---\n"))
+			Expect(output.String()).To(ContainSubstring("kind: ConfigMap"))
+			Expect(output.String()).To(ContainSubstring("kind: Deployment"))
+			Expect(output.String()).To(ContainSubstring("name: sample-portal"))
+			Expect(output.String()).To(ContainSubstring("namespace: registry"))
+			Expect(output.String()).To(ContainSubstring("goharbor.io/version: test"))
+		})
+
+		It("Should mask secret values", func() {
+			err := Run(ctx, "harbor-operator", "test", &Options{Namespace: "registry"}, strings.NewReader(harborManifest+secretManifest), output, warnings)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(output.String()).To(ContainSubstring(MaskedValue))
+			Expect(output.String()).ToNot(ContainSubstring("redis://redis:6379/1"))
+		})
+
+		It("Should show secret values when asked", func() {
+			err := Run(ctx, "harbor-operator", "test", &Options{Namespace: "registry", ShowSecrets: true}, strings.NewReader(harborManifest+secretManifest), output, warnings)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(output.String()).ToNot(ContainSubstring(MaskedValue))
+		})
+
+		It("Should warn about missing secrets", func() {
+			err := Run(ctx, "harbor-operator", "test", &Options{Namespace: "registry"}, strings.NewReader(harborManifest), output, warnings)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(warnings.String()).To(ContainSubstring("secret registry/registry-cache not found"))
+		})
+
+		It("Should filter components", func() {
+			err := Run(ctx, "harbor-operator", "test", &Options{Namespace: "registry", Components: []string{"portal"}}, strings.NewReader(harborManifest+secretManifest), output, warnings)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(output.String()).To(ContainSubstring("goharbor.io/component: portal"))
+			Expect(output.String()).ToNot(ContainSubstring("goharbor.io/component: core"))
+		})
+
+		It("Should reject unknown components", func() {
+			err := Run(ctx, "harbor-operator", "test", &Options{Namespace: "registry", Components: []string{"unknown"}}, strings.NewReader(harborManifest), output, warnings)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should reject invalid harbors", func() {
+			err := Run(ctx, "harbor-operator", "test", &Options{Namespace: "registry"}, strings.NewReader(harborManifest+"  pausedComponents: [unknown]\n"), output, warnings)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should skip other kinds", func() {
+			err := Run(ctx, "harbor-operator", "test", &Options{Namespace: "registry"}, strings.NewReader(harborManifest+"---\napiVersion: v1\nkind: Namespace\nmetadata:\n  name: registry\n"), output, warnings)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(warnings.String()).To(ContainSubstring("kind Namespace ignored"))
+		})
+
+		It("Should require a harbor", func() {
+			err := Run(ctx, "harbor-operator", "test", &Options{Namespace: "registry"}, strings.NewReader(secretManifest), output, warnings)
+			Expect(err).To(MatchError("no harbor found"))
+		})
+	})
+})
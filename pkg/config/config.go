@@ -0,0 +1,371 @@
+package config
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	jaeger_cnf "github.com/uber/jaeger-client-go/config"
+	"go.uber.org/zap/zapcore"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor"
+)
+
+const (
+	APIVersion = "config.goharbor.io/v1alpha1"
+	Kind       = "OperatorConfiguration"
+)
+
+const (
+	DefaultLogLevel       = "info"
+	DefaultMetricsAddress = ":8080"
+	DefaultWebhookPort    = 9443
+	DefaultDebugAddress   = "127.0.0.1:8082"
+
+	DefaultConcurrentReconciles = 1
+	DefaultWatchChildren        = true
+	DefaultHarborClasses        = true
+)
+
+// OperatorConfiguration is the configuration of the operator.
+type OperatorConfiguration struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Log configures the logs of the operator. The level is reloaded.
+	Log LogConfiguration `json:"log,omitempty"`
+
+	// Manager configures the manager of the controllers.
+	Manager ManagerConfiguration `json:"manager,omitempty"`
+
+	// Tracing is the Jaeger configuration, read from the JAEGER_* environment variables when not set.
+	Tracing *jaeger_cnf.Configuration `json:"tracing,omitempty"`
+
+	// Controller configures the Harbor controller. Requeue delays are reloaded.
+	Controller ControllerConfiguration `json:"controller,omitempty"`
+
+	// DefaultImages overrides the images of components whose image is not set, by component. They are reloaded.
+	DefaultImages map[string]string `json:"defaultImages,omitempty"`
+
+	// DebugAddress is the address serving the /config endpoint, disabled when empty.
+	DebugAddress *string `json:"debugAddress,omitempty"`
+}
+
+type LogConfiguration struct {
+	// Development enables human readable logs.
+	Development bool `json:"development,omitempty"`
+
+	// Level is the minimum level of logs: debug, info, warn or error.
+	Level string `json:"level,omitempty"`
+}
+
+type ManagerConfiguration struct {
+	MetricsAddress          string `json:"metricsAddress,omitempty"`
+	WebhookPort             int    `json:"webhookPort,omitempty"`
+	LeaderElection          bool   `json:"leaderElection,omitempty"`
+	LeaderElectionNamespace string `json:"leaderElectionNamespace,omitempty"`
+	LeaderElectionID        string `json:"leaderElectionID,omitempty"`
+}
+
+type ControllerConfiguration struct {
+	ConcurrentReconciles int   `json:"concurrentReconciles,omitempty"`
+	WatchChildren        *bool `json:"watchChildren,omitempty"`
+
+	// Class is the value of the goharbor.io/harbor-class annotation of the reconciled Harbors.
+	Class string `json:"class,omitempty"`
+
+	// Namespaces are the namespaces of the reconciled Harbors, every namespace when empty.
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// Selector is the label selector of the reconciled Harbors.
+	Selector string `json:"selector,omitempty"`
+
+	// HarborClasses enables the defaults of HarborClasses, disable it when the operator cannot watch cluster-scoped resources.
+	HarborClasses *bool `json:"harborClasses,omitempty"`
+
+	HealthProbe HealthProbeConfiguration `json:"healthProbe,omitempty"`
+
+	SyntheticProbeTimeout metav1.Duration `json:"syntheticProbeTimeout,omitempty"`
+
+	DriftPolicy harbor.DriftPolicy `json:"driftPolicy,omitempty"`
+
+	ReconcileTimeout metav1.Duration `json:"reconcileTimeout,omitempty"`
+
+	Requeue RequeueConfiguration `json:"requeue,omitempty"`
+
+	// LegacyCertificates are read from the deprecated certificate-encryption configstore slice only.
+	LegacyCertificates *goharborv1alpha1.CertificateSettings `json:"-"`
+}
+
+type HealthProbeConfiguration struct {
+	Mode     harbor.HealthProbeMode `json:"mode,omitempty"`
+	Interval metav1.Duration        `json:"interval,omitempty"`
+	Timeout  metav1.Duration        `json:"timeout,omitempty"`
+}
+
+type RequeueConfiguration struct {
+	InitialDelay metav1.Duration `json:"initialDelay,omitempty"`
+	MaxDelay     metav1.Duration `json:"maxDelay,omitempty"`
+	Jitter       *float64        `json:"jitter,omitempty"`
+
+	// ResyncInterval is the interval between two full applies of a Harbor, disabled when zero.
+	ResyncInterval *metav1.Duration `json:"resyncInterval,omitempty"`
+}
+
+// New returns the default configuration.
+func New() *OperatorConfiguration {
+	config := &OperatorConfiguration{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: APIVersion,
+			Kind:       Kind,
+		},
+	}
+	config.SetDefaults()
+
+	return config
+}
+
+// SetDefaults sets the default value of every field which is not set.
+func (c *OperatorConfiguration) SetDefaults() {
+	if c.Log.Level == "" {
+		c.Log.Level = DefaultLogLevel
+	}
+
+	if c.Manager.MetricsAddress == "" {
+		c.Manager.MetricsAddress = DefaultMetricsAddress
+	}
+
+	if c.Manager.WebhookPort == 0 {
+		c.Manager.WebhookPort = DefaultWebhookPort
+	}
+
+	if c.DebugAddress == nil {
+		address := DefaultDebugAddress
+		c.DebugAddress = &address
+	}
+
+	c.Controller.SetDefaults()
+}
+
+// SetDefaults sets the default value of every field which is not set.
+func (c *ControllerConfiguration) SetDefaults() {
+	if c.ConcurrentReconciles == 0 {
+		c.ConcurrentReconciles = DefaultConcurrentReconciles
+	}
+
+	if c.WatchChildren == nil {
+		watchChildren := DefaultWatchChildren
+		c.WatchChildren = &watchChildren
+	}
+
+	if c.HarborClasses == nil {
+		harborClasses := DefaultHarborClasses
+		c.HarborClasses = &harborClasses
+	}
+
+	if c.HealthProbe.Mode == "" {
+		c.HealthProbe.Mode = harbor.ProxyHealthProbeMode
+	}
+
+	setDefaultDuration(&c.HealthProbe.Interval, harbor.DefaultHealthProbeInterval)
+	setDefaultDuration(&c.HealthProbe.Timeout, harbor.DefaultHealthProbeTimeout)
+	setDefaultDuration(&c.SyntheticProbeTimeout, harbor.DefaultSyntheticProbeTimeout)
+	setDefaultDuration(&c.ReconcileTimeout, harbor.DefaultReconcileTimeout)
+
+	if c.DriftPolicy == "" {
+		c.DriftPolicy = harbor.DefaultDriftPolicy
+	}
+
+	setDefaultDuration(&c.Requeue.InitialDelay, harbor.DefaultRequeueInitialDelay)
+	setDefaultDuration(&c.Requeue.MaxDelay, harbor.DefaultRequeueMaxDelay)
+
+	if c.Requeue.Jitter == nil {
+		jitter := harbor.DefaultRequeueJitter
+		c.Requeue.Jitter = &jitter
+	}
+
+	if c.Requeue.ResyncInterval == nil {
+		c.Requeue.ResyncInterval = &metav1.Duration{Duration: harbor.DefaultResyncInterval}
+	}
+}
+
+func setDefaultDuration(duration *metav1.Duration, value time.Duration) {
+	if duration.Duration == 0 {
+		duration.Duration = value
+	}
+}
+
+// Validate returns every invalid field of the configuration, defaults must have been set.
+func (c *OperatorConfiguration) Validate() error {
+	var errs field.ErrorList
+
+	if c.APIVersion != APIVersion {
+		errs = append(errs, field.NotSupported(field.NewPath("apiVersion"), c.APIVersion, []string{APIVersion}))
+	}
+
+	if c.Kind != Kind {
+		errs = append(errs, field.NotSupported(field.NewPath("kind"), c.Kind, []string{Kind}))
+	}
+
+	var level zapcore.Level
+	if err := level.UnmarshalText([]byte(c.Log.Level)); err != nil {
+		errs = append(errs, field.NotSupported(field.NewPath("log", "level"), c.Log.Level, []string{"debug", "info", "warn", "error"}))
+	}
+
+	if c.Manager.WebhookPort < 0 || c.Manager.WebhookPort > 65535 {
+		errs = append(errs, field.Invalid(field.NewPath("manager", "webhookPort"), c.Manager.WebhookPort, "must be a valid port"))
+	}
+
+	errs = append(errs, c.Controller.validate(field.NewPath("controller"))...)
+
+	keys := goharborv1alpha1.GetDefaultImageKeys()
+
+	for key, image := range c.DefaultImages {
+		if image == "" {
+			errs = append(errs, field.Required(field.NewPath("defaultImages").Key(key), "image must not be empty"))
+		}
+
+		found := false
+
+		for _, k := range keys {
+			if k == key {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			errs = append(errs, field.NotSupported(field.NewPath("defaultImages").Key(key), key, keys))
+		}
+	}
+
+	return errs.ToAggregate()
+}
+
+func (c *ControllerConfiguration) validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if c.ConcurrentReconciles < 1 {
+		errs = append(errs, field.Invalid(path.Child("concurrentReconciles"), c.ConcurrentReconciles, "must be positive"))
+	}
+
+	for i, namespace := range c.Namespaces {
+		for _, msg := range validation.IsDNS1123Label(namespace) {
+			errs = append(errs, field.Invalid(path.Child("namespaces").Index(i), namespace, msg))
+		}
+	}
+
+	if _, err := labels.Parse(c.Selector); err != nil {
+		errs = append(errs, field.Invalid(path.Child("selector"), c.Selector, err.Error()))
+	}
+
+	switch c.HealthProbe.Mode {
+	case harbor.ProxyHealthProbeMode, harbor.DirectHealthProbeMode:
+	default:
+		errs = append(errs, field.NotSupported(path.Child("healthProbe", "mode"), c.HealthProbe.Mode, []string{string(harbor.ProxyHealthProbeMode), string(harbor.DirectHealthProbeMode)}))
+	}
+
+	errs = append(errs, validatePositive(path.Child("healthProbe", "interval"), c.HealthProbe.Interval)...)
+	errs = append(errs, validatePositive(path.Child("healthProbe", "timeout"), c.HealthProbe.Timeout)...)
+	errs = append(errs, validatePositive(path.Child("syntheticProbeTimeout"), c.SyntheticProbeTimeout)...)
+	errs = append(errs, validatePositive(path.Child("reconcileTimeout"), c.ReconcileTimeout)...)
+
+	switch c.DriftPolicy {
+	case harbor.CorrectDriftPolicy, harbor.ReportDriftPolicy:
+	default:
+		errs = append(errs, field.NotSupported(path.Child("driftPolicy"), c.DriftPolicy, []string{string(harbor.CorrectDriftPolicy), string(harbor.ReportDriftPolicy)}))
+	}
+
+	if err := c.GetRequeueConfig().Validate(); err != nil {
+		errs = append(errs, field.Invalid(path.Child("requeue"), c.Requeue, err.Error()))
+	}
+
+	return errs
+}
+
+func validatePositive(path *field.Path, duration metav1.Duration) field.ErrorList {
+	if duration.Duration <= 0 {
+		return field.ErrorList{field.Invalid(path, duration.Duration.String(), "must be positive")}
+	}
+
+	return nil
+}
+
+// GetRequeueConfig returns the requeue configuration of the Harbor controller, defaults must have been set.
+func (c *ControllerConfiguration) GetRequeueConfig() harbor.RequeueConfig {
+	return harbor.RequeueConfig{
+		InitialDelay:   c.Requeue.InitialDelay.Duration,
+		MaxDelay:       c.Requeue.MaxDelay.Duration,
+		Jitter:         *c.Requeue.Jitter,
+		ResyncInterval: c.Requeue.ResyncInterval.Duration,
+	}
+}
+
+// GetHarborConfig returns the configuration of the Harbor controller, the configuration must be valid.
+func (c *OperatorConfiguration) GetHarborConfig() (*harbor.Config, error) {
+	var selector labels.Selector
+
+	if strings.TrimSpace(c.Controller.Selector) != "" {
+		var err error
+
+		selector, err = labels.Parse(c.Controller.Selector)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid selector")
+		}
+	}
+
+	return &harbor.Config{
+		ClassName:            c.Controller.Class,
+		ConcurrentReconciles: c.Controller.ConcurrentReconciles,
+		WatchChildren:        *c.Controller.WatchChildren,
+		Namespaces:           c.Controller.Namespaces,
+		Selector:             selector,
+		HarborClasses:        *c.Controller.HarborClasses,
+		HealthProbe: harbor.HealthProbeConfig{
+			Mode:     c.Controller.HealthProbe.Mode,
+			Interval: c.Controller.HealthProbe.Interval.Duration,
+			Timeout:  c.Controller.HealthProbe.Timeout.Duration,
+		},
+		SyntheticProbeTimeout: c.Controller.SyntheticProbeTimeout.Duration,
+		DriftPolicy:           c.Controller.DriftPolicy,
+		ReconcileTimeout:      c.Controller.ReconcileTimeout.Duration,
+		Requeue:               c.Controller.GetRequeueConfig(),
+		LegacyCertificates:    c.Controller.LegacyCertificates,
+	}, nil
+}
+
+// GetLogLevel returns the minimum level of logs, the configuration must be valid.
+func (c *OperatorConfiguration) GetLogLevel() zapcore.Level {
+	var level zapcore.Level
+
+	_ = level.UnmarshalText([]byte(c.Log.Level))
+
+	return level
+}
+
+// Redacted returns a copy of the configuration without credentials.
+func (c *OperatorConfiguration) Redacted() *OperatorConfiguration {
+	redacted := *c
+
+	if c.Tracing != nil {
+		tracing := *c.Tracing
+
+		if tracing.Reporter != nil {
+			reporter := *tracing.Reporter
+
+			if reporter.Password != "" {
+				reporter.Password = "REDACTED"
+			}
+
+			tracing.Reporter = &reporter
+		}
+
+		redacted.Tracing = &tracing
+	}
+
+	return &redacted
+}
@@ -0,0 +1,281 @@
+package config
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	certv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/ovh/configstore"
+	jaeger_cnf "github.com/uber/jaeger-client-go/config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor"
+	pkgharbor "github.com/goharbor/harbor-operator/pkg/controllers/harbor"
+)
+
+func TestConfig(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecsWithDefaultAndCustomReporters(t, "Config Suite", []Reporter{envtest.NewlineReporter{}})
+}
+
+type requeueRecorder struct {
+	config harbor.RequeueConfig
+}
+
+func (r *requeueRecorder) SetRequeue(config harbor.RequeueConfig) {
+	r.config = config
+}
+
+var _ = Describe("OperatorConfiguration", func() {
+	Context("Parse", func() {
+		It("Should set defaults", func() {
+			config, err := Parse([]byte(`
+apiVersion: config.goharbor.io/v1alpha1
+kind: OperatorConfiguration
+`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(config).To(Equal(New()))
+
+			harborConfig, err := config.GetHarborConfig()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(harborConfig.Requeue).To(Equal(harbor.GetDefaultRequeueConfig()))
+			Expect(harborConfig.WatchChildren).To(BeTrue())
+			Expect(harborConfig.Selector).To(BeNil())
+			Expect(harborConfig.HarborClasses).To(BeTrue())
+		})
+
+		It("Should decode settings", func() {
+			config, err := Parse([]byte(`
+apiVersion: config.goharbor.io/v1alpha1
+kind: OperatorConfiguration
+log:
+  level: debug
+controller:
+  watchChildren: false
+  namespaces: [registry]
+  selector: team=registry
+  harborClasses: false
+  requeue:
+    maxDelay: 1m
+    resyncInterval: 0s
+defaultImages:
+  core: registry.example.com/harbor-core:v1.10.0
+`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(config.GetLogLevel()).To(Equal(zapcore.DebugLevel))
+
+			harborConfig, err := config.GetHarborConfig()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(harborConfig.WatchChildren).To(BeFalse())
+			Expect(harborConfig.Namespaces).To(Equal([]string{"registry"}))
+			Expect(harborConfig.Selector.String()).To(Equal("team=registry"))
+			Expect(harborConfig.HarborClasses).To(BeFalse())
+			Expect(harborConfig.Requeue.MaxDelay).To(Equal(time.Minute))
+			Expect(harborConfig.Requeue.ResyncInterval).To(BeZero())
+		})
+
+		It("Should fail on unknown fields", func() {
+			_, err := Parse([]byte(`
+apiVersion: config.goharbor.io/v1alpha1
+kind: OperatorConfiguration
+controller:
+  watchChildern: false
+`))
+			Expect(err).To(MatchError(ContainSubstring("watchChildern")))
+		})
+
+		It("Should report every invalid field", func() {
+			_, err := Parse([]byte(`
+apiVersion: config.goharbor.io/v1alpha1
+kind: OperatorConfiguration
+log:
+  level: verbose
+controller:
+  driftPolicy: ignore
+  namespaces: [Registry]
+  requeue:
+    initialDelay: 1h
+defaultImages:
+  portal2: portal
+`))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("log.level"))
+			Expect(err.Error()).To(ContainSubstring("controller.driftPolicy"))
+			Expect(err.Error()).To(ContainSubstring("controller.namespaces[0]"))
+			Expect(err.Error()).To(ContainSubstring("controller.requeue"))
+			Expect(err.Error()).To(ContainSubstring("defaultImages[portal2]"))
+		})
+
+		It("Should require the kind", func() {
+			_, err := Parse([]byte(`
+apiVersion: config.goharbor.io/v1alpha1
+`))
+			Expect(err).To(MatchError(ContainSubstring("kind")))
+		})
+	})
+
+	Context("FromConfigstore", func() {
+		var provider *configstore.InMemoryProvider
+
+		BeforeEach(func() {
+			configstore.AllowProviderOverride()
+			provider = configstore.InMemory("config-test")
+		})
+
+		It("Should read legacy keys", func() {
+			provider.Add(
+				configstore.NewItem(DevModeKey, "false", 0),
+				configstore.NewItem(pkgharbor.WatchChildrenKey, "false", 0),
+				configstore.NewItem(pkgharbor.RequeueMaxDelayKey, "1m", 0),
+			)
+
+			config, err := FromConfigstore()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(config.Log.Development).To(BeFalse())
+			Expect(*config.Controller.WatchChildren).To(BeFalse())
+			Expect(config.Controller.Requeue.MaxDelay.Duration).To(Equal(time.Minute))
+			Expect(config.Manager.WebhookPort).To(Equal(DefaultWebhookPort))
+		})
+
+		It("Should fail on unknown keys", func() {
+			provider.Add(configstore.NewItem(pkgharbor.ConfigPrefix+"-watch-childern", "false", 0))
+
+			_, err := FromConfigstore()
+			Expect(err).To(MatchError(ContainSubstring("harbor-controller-watch-childern")))
+		})
+
+		It("Should fail on unsupported manager options", func() {
+			provider.Add(configstore.NewItem(OperatorSlice, "port: 9443\nnamespace: registry\ncertDir: /tmp/certs", 0))
+
+			_, err := FromConfigstore()
+			Expect(err).To(MatchError(ContainSubstring("unsupported fields Namespace, CertDir")))
+		})
+
+		It("Should read supported manager options", func() {
+			provider.Add(configstore.NewItem(OperatorSlice, "port: 9443\nleaderelection: true", 0))
+
+			config, err := FromConfigstore()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(config.Manager.WebhookPort).To(Equal(9443))
+			Expect(config.Manager.LeaderElection).To(BeTrue())
+		})
+
+		It("Should read the deprecated certificate encryption", func() {
+			provider.Add(configstore.NewItem(pkgharbor.CertificateEncryptionSlice, "keysize: 2048\nkeyalgorithm: rsa", 0))
+
+			config, err := FromConfigstore()
+			Expect(err).ToNot(HaveOccurred())
+
+			harborConfig, err := config.GetHarborConfig()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(harborConfig.LegacyCertificates.KeySize).To(Equal(2048))
+			Expect(harborConfig.LegacyCertificates.KeyAlgorithm).To(Equal(certv1.RSAKeyAlgorithm))
+		})
+	})
+
+	Context("Reloader", func() {
+		var reloader *Reloader
+		var level zap.AtomicLevel
+		var requeue *requeueRecorder
+
+		write := func(content string) {
+			Expect(ioutil.WriteFile(reloader.Path, []byte(content), 0600)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			dir, err := ioutil.TempDir("", "config")
+			Expect(err).ToNot(HaveOccurred())
+
+			level = zap.NewAtomicLevel()
+			requeue = &requeueRecorder{}
+
+			store, err := NewStore(New(), level)
+			Expect(err).ToNot(HaveOccurred())
+			store.AddRequeueSetter(requeue)
+
+			reloader = &Reloader{
+				Path:  filepath.Join(dir, "config.yaml"),
+				Store: store,
+				Log:   logf.NullLogger{},
+			}
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(filepath.Dir(reloader.Path))
+			Expect(goharborv1alpha1.SetDefaultImages(nil)).To(Succeed())
+		})
+
+		It("Should apply reloadable settings", func() {
+			write(`
+apiVersion: config.goharbor.io/v1alpha1
+kind: OperatorConfiguration
+log:
+  level: debug
+manager:
+  webhookPort: 9444
+controller:
+  requeue:
+    maxDelay: 1m
+defaultImages:
+  core: registry.example.com/harbor-core:v1.10.0
+`)
+			reloader.Reload()
+
+			Expect(level.Level()).To(Equal(zapcore.DebugLevel))
+			Expect(requeue.config.MaxDelay).To(Equal(time.Minute))
+			Expect(goharborv1alpha1.GetDefaultImage(goharborv1alpha1.CoreImageKey)).To(Equal("registry.example.com/harbor-core:v1.10.0"))
+
+			// The manager is configured on startup only
+			Expect(reloader.Store.Get().Manager.WebhookPort).To(Equal(DefaultWebhookPort))
+		})
+
+		It("Should keep the running configuration when invalid", func() {
+			write(`
+apiVersion: config.goharbor.io/v1alpha1
+kind: OperatorConfiguration
+log:
+  level: verbose
+`)
+			reloader.Reload()
+
+			Expect(level.Level()).To(Equal(zapcore.InfoLevel))
+			Expect(reloader.Store.Get()).To(Equal(New()))
+		})
+	})
+
+	Context("Server", func() {
+		It("Should serve the configuration without credentials", func() {
+			config := New()
+
+			config.Tracing = &jaeger_cnf.Configuration{
+				Reporter: &jaeger_cnf.ReporterConfig{
+					User:     "harbor",
+					Password: "secret",
+				},
+			}
+
+			store, err := NewStore(config, zap.NewAtomicLevel())
+			Expect(err).ToNot(HaveOccurred())
+
+			recorder := httptest.NewRecorder()
+			(&Server{Store: store, Log: logf.NullLogger{}}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, ConfigPath, nil))
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(ContainSubstring(`"kind": "OperatorConfiguration"`))
+			Expect(recorder.Body.String()).ToNot(ContainSubstring("secret"))
+			Expect(config.Tracing.Reporter.Password).To(Equal("secret"))
+		})
+	})
+})
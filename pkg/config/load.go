@@ -0,0 +1,225 @@
+package config
+
+import (
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ovh/configstore"
+	"github.com/pkg/errors"
+	jaeger_cnf "github.com/uber/jaeger-client-go/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/yaml"
+
+	"github.com/goharbor/harbor-operator/controllers/harbor"
+	pkgharbor "github.com/goharbor/harbor-operator/pkg/controllers/harbor"
+)
+
+const (
+	// FileEnv is the environment variable containing the path of the configuration file.
+	FileEnv = "OPERATOR_CONFIGURATION_FILE"
+
+	DevModeKey     = "dev-mode"
+	OperatorSlice  = "operator"
+	JaegerSlice    = "jaeger"
+	DefaultDevMode = true
+)
+
+// Load reads the configuration file, sets defaults and validates it.
+func Load(path string) (*OperatorConfiguration, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read %s", path)
+	}
+
+	config, err := Parse(data)
+
+	return config, errors.Wrapf(err, "invalid configuration %s", path)
+}
+
+// Parse decodes the configuration, sets defaults and validates it.
+// Unknown fields are errors, so typos are not silently ignored.
+func Parse(data []byte) (*OperatorConfiguration, error) {
+	config := &OperatorConfiguration{}
+
+	err := yaml.UnmarshalStrict(data, config)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot decode")
+	}
+
+	config.SetDefaults()
+
+	err = config.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// FromConfigstore returns the configuration from the configstore keys used before configuration files.
+// Unknown keys of the Harbor controller are errors.
+func FromConfigstore() (*OperatorConfiguration, error) {
+	config := New()
+
+	err := checkConfigstoreKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	config.Log.Development, err = configstore.Filter().GetItemValueBool(DevModeKey)
+	if err != nil {
+		_, ok := err.(configstore.ErrItemNotFound)
+		if !ok {
+			return nil, errors.Wrapf(err, "key %s", DevModeKey)
+		}
+
+		config.Log.Development = DefaultDevMode
+	}
+
+	item, err := configstore.Filter().
+		Slice(OperatorSlice).
+		Unmarshal(func() interface{} { return &manager.Options{} }).
+		GetFirstItem()
+	if err == nil {
+		options, err := item.Unmarshaled()
+		if err != nil {
+			return nil, errors.Wrapf(err, "slice %s", OperatorSlice)
+		}
+
+		config.Manager, err = getManagerConfiguration(options.(*manager.Options))
+		if err != nil {
+			return nil, errors.Wrapf(err, "slice %s", OperatorSlice)
+		}
+	}
+
+	item, err = configstore.Filter().
+		Slice(JaegerSlice).
+		Unmarshal(func() interface{} { return &jaeger_cnf.Configuration{} }).
+		GetFirstItem()
+	if err == nil {
+		tracing, err := item.Unmarshaled()
+		if err != nil {
+			return nil, errors.Wrapf(err, "slice %s", JaegerSlice)
+		}
+
+		config.Tracing = tracing.(*jaeger_cnf.Configuration)
+	}
+
+	harborConfig, err := pkgharbor.GetConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "harbor controller")
+	}
+
+	config.Controller = getControllerConfiguration(harborConfig)
+
+	config.SetDefaults()
+
+	err = config.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func checkConfigstoreKeys() error {
+	items, err := configstore.GetItemList()
+	if err != nil {
+		return errors.Wrap(err, "cannot list keys")
+	}
+
+	known := make(map[string]bool, len(pkgharbor.Keys))
+	for _, key := range pkgharbor.Keys {
+		known[key] = true
+	}
+
+	var unknown []string
+
+	for _, key := range items.Keys() {
+		if strings.HasPrefix(key, pkgharbor.ConfigPrefix+"-") && !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+
+		return errors.Errorf("unknown keys %s", strings.Join(unknown, ", "))
+	}
+
+	return nil
+}
+
+// supportedManagerOptions are the fields of manager.Options with a manager setting.
+var supportedManagerOptions = map[string]bool{
+	"MetricsBindAddress":      true,
+	"Port":                    true,
+	"LeaderElection":          true,
+	"LeaderElectionNamespace": true,
+	"LeaderElectionID":        true,
+}
+
+// getManagerConfiguration returns the manager settings of options.
+// Other fields of options are errors, so they are not silently ignored.
+func getManagerConfiguration(options *manager.Options) (ManagerConfiguration, error) {
+	var unsupported []string
+
+	value := reflect.ValueOf(options).Elem()
+
+	for i := 0; i < value.NumField(); i++ {
+		name := value.Type().Field(i).Name
+		if !supportedManagerOptions[name] && !value.Field(i).IsZero() {
+			unsupported = append(unsupported, name)
+		}
+	}
+
+	if len(unsupported) > 0 {
+		return ManagerConfiguration{}, errors.Errorf("unsupported fields %s", strings.Join(unsupported, ", "))
+	}
+
+	return ManagerConfiguration{
+		MetricsAddress:          options.MetricsBindAddress,
+		WebhookPort:             options.Port,
+		LeaderElection:          options.LeaderElection,
+		LeaderElectionNamespace: options.LeaderElectionNamespace,
+		LeaderElectionID:        options.LeaderElectionID,
+	}, nil
+}
+
+func getControllerConfiguration(harborConfig *harbor.Config) ControllerConfiguration {
+	jitter := harborConfig.Requeue.Jitter
+	watchChildren := harborConfig.WatchChildren
+	harborClasses := harborConfig.HarborClasses
+
+	config := ControllerConfiguration{
+		ConcurrentReconciles: harborConfig.ConcurrentReconciles,
+		WatchChildren:        &watchChildren,
+		Class:                harborConfig.ClassName,
+		Namespaces:           harborConfig.Namespaces,
+		HarborClasses:        &harborClasses,
+		HealthProbe: HealthProbeConfiguration{
+			Mode:     harborConfig.HealthProbe.Mode,
+			Interval: metav1.Duration{Duration: harborConfig.HealthProbe.Interval},
+			Timeout:  metav1.Duration{Duration: harborConfig.HealthProbe.Timeout},
+		},
+		SyntheticProbeTimeout: metav1.Duration{Duration: harborConfig.SyntheticProbeTimeout},
+		DriftPolicy:           harborConfig.DriftPolicy,
+		ReconcileTimeout:      metav1.Duration{Duration: harborConfig.ReconcileTimeout},
+		Requeue: RequeueConfiguration{
+			InitialDelay:   metav1.Duration{Duration: harborConfig.Requeue.InitialDelay},
+			MaxDelay:       metav1.Duration{Duration: harborConfig.Requeue.MaxDelay},
+			Jitter:         &jitter,
+			ResyncInterval: &metav1.Duration{Duration: harborConfig.Requeue.ResyncInterval},
+		},
+		LegacyCertificates: harborConfig.LegacyCertificates,
+	}
+
+	if harborConfig.Selector != nil {
+		config.Selector = harborConfig.Selector.String()
+	}
+
+	return config
+}
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
+	"github.com/goharbor/harbor-operator/controllers/harbor"
+)
+
+const DefaultReloadInterval = 10 * time.Second
+
+// RequeueSetter replaces the requeue configuration of a running controller.
+type RequeueSetter interface {
+	SetRequeue(config harbor.RequeueConfig)
+}
+
+// Store holds the running configuration and applies the settings which can be reloaded.
+type Store struct {
+	lock   sync.RWMutex
+	config *OperatorConfiguration
+
+	level    zap.AtomicLevel
+	requeues []RequeueSetter
+}
+
+// NewStore returns a store of the running configuration, the level of logs is set by the store.
+func NewStore(config *OperatorConfiguration, level zap.AtomicLevel) (*Store, error) {
+	s := &Store{
+		level: level,
+	}
+
+	return s, s.Set(config)
+}
+
+// AddRequeueSetter registers a controller whose requeue configuration is reloaded.
+func (s *Store) AddRequeueSetter(setter RequeueSetter) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.requeues = append(s.requeues, setter)
+}
+
+// Get returns the running configuration, it must not be modified.
+func (s *Store) Get() *OperatorConfiguration {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.config
+}
+
+// Set applies the log level, the requeue configuration and the default images of the configuration.
+func (s *Store) Set(config *OperatorConfiguration) error {
+	err := goharborv1alpha1.SetDefaultImages(config.DefaultImages)
+	if err != nil {
+		return errors.Wrap(err, "cannot set default images")
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.level.SetLevel(config.GetLogLevel())
+
+	for _, setter := range s.requeues {
+		setter.SetRequeue(config.Controller.GetRequeueConfig())
+	}
+
+	s.config = config
+
+	return nil
+}
+
+// WithReloadable returns a copy of the configuration with the settings of next which can be reloaded.
+func (c *OperatorConfiguration) WithReloadable(next *OperatorConfiguration) *OperatorConfiguration {
+	config := *c
+
+	config.Log.Level = next.Log.Level
+	config.Controller.Requeue = next.Controller.Requeue
+	config.DefaultImages = next.DefaultImages
+
+	return &config
+}
+
+// GetRestartRequired returns the settings of the new configuration which are only applied on restart.
+func GetRestartRequired(current, next *OperatorConfiguration) []string {
+	var settings []string
+
+	if current.Log.Development != next.Log.Development {
+		settings = append(settings, "log.development")
+	}
+
+	if !reflect.DeepEqual(current.Manager, next.Manager) {
+		settings = append(settings, "manager")
+	}
+
+	if !reflect.DeepEqual(current.Tracing, next.Tracing) {
+		settings = append(settings, "tracing")
+	}
+
+	currentController, nextController := current.Controller, next.Controller
+	currentController.Requeue, nextController.Requeue = RequeueConfiguration{}, RequeueConfiguration{}
+
+	if !reflect.DeepEqual(currentController, nextController) {
+		settings = append(settings, "controller")
+	}
+
+	if !reflect.DeepEqual(current.DebugAddress, next.DebugAddress) {
+		settings = append(settings, "debugAddress")
+	}
+
+	return settings
+}
+
+// Reloader loads the configuration file again when its content changes.
+// Files of mounted ConfigMaps are replaced by the kubelet, so the content is polled.
+type Reloader struct {
+	Path     string
+	Interval time.Duration
+	Store    *Store
+	Log      logr.Logger
+
+	content []byte
+}
+
+// NeedLeaderElection returns false, every instance of the operator reloads its configuration.
+func (r *Reloader) NeedLeaderElection() bool {
+	return false
+}
+
+// Start polls the configuration file until stop is closed.
+func (r *Reloader) Start(stop <-chan struct{}) error {
+	interval := r.Interval
+	if interval <= 0 {
+		interval = DefaultReloadInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+			r.Reload()
+		}
+	}
+}
+
+// Reload applies the configuration file if its content changed.
+// An invalid configuration is logged and the running configuration is kept.
+func (r *Reloader) Reload() {
+	content, err := ioutil.ReadFile(r.Path)
+	if err != nil {
+		r.Log.Error(err, "cannot read configuration, running configuration kept", "path", r.Path)
+		return
+	}
+
+	if r.content != nil && bytes.Equal(content, r.content) {
+		return
+	}
+
+	r.content = content
+
+	config, err := Parse(content)
+	if err != nil {
+		r.Log.Error(err, "invalid configuration, running configuration kept", "path", r.Path)
+		return
+	}
+
+	current := r.Store.Get()
+	restart := GetRestartRequired(current, config)
+	next := current.WithReloadable(config)
+
+	if len(restart) == 0 && reflect.DeepEqual(current, next) {
+		return
+	}
+
+	err = r.Store.Set(next)
+	if err != nil {
+		r.Log.Error(err, "cannot apply configuration, running configuration kept", "path", r.Path)
+		return
+	}
+
+	r.Log.Info("configuration reloaded", "path", r.Path, "restartRequired", restart)
+}
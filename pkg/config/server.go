@@ -0,0 +1,77 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+)
+
+const (
+	ConfigPath = "/config"
+
+	serverShutdownTimeout = 5 * time.Second
+)
+
+// Server serves the running configuration, without credentials, on ConfigPath.
+type Server struct {
+	Address string
+	Store   *Store
+	Log     logr.Logger
+}
+
+// NeedLeaderElection returns false, every instance of the operator serves its configuration.
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// ServeHTTP writes the running configuration as JSON.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	err := encoder.Encode(s.Store.Get().Redacted())
+	if err != nil {
+		s.Log.Error(err, "cannot write configuration")
+	}
+}
+
+// Start serves the configuration until stop is closed.
+func (s *Server) Start(stop <-chan struct{}) error {
+	mux := http.NewServeMux()
+	mux.Handle(ConfigPath, s)
+
+	server := &http.Server{
+		Addr:    s.Address,
+		Handler: mux,
+	}
+
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+
+	s.Log.Info("serving configuration", "address", s.Address, "path", ConfigPath)
+
+	select {
+	case err := <-errs:
+		return errors.Wrap(err, "cannot serve configuration")
+	case <-stop:
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), serverShutdownTimeout)
+	defer cancel()
+
+	return errors.Wrap(server.Shutdown(ctx), "cannot stop configuration server")
+}
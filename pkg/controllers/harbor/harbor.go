@@ -2,10 +2,16 @@ package harbor
 
 import (
 	"context"
+	"strings"
+	"time"
 
+	certv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha2"
 	"github.com/ovh/configstore"
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
 
+	goharborv1alpha1 "github.com/goharbor/harbor-operator/api/v1alpha1"
 	"github.com/goharbor/harbor-operator/controllers/harbor"
 )
 
@@ -14,12 +20,44 @@ const (
 	ReconciliationKey = ConfigPrefix + "-max-reconcile"
 	WatchChildrenKey  = ConfigPrefix + "-watch-children"
 	HarborClassKey    = ConfigPrefix + "-class"
+	NamespacesKey     = ConfigPrefix + "-namespaces"
+	SelectorKey       = ConfigPrefix + "-selector"
+	HarborClassesKey  = ConfigPrefix + "-harbor-classes"
+
+	HealthProbeModeKey     = ConfigPrefix + "-health-probe-mode"
+	HealthProbeIntervalKey = ConfigPrefix + "-health-probe-interval"
+	HealthProbeTimeoutKey  = ConfigPrefix + "-health-probe-timeout"
+
+	SyntheticProbeTimeoutKey = ConfigPrefix + "-synthetic-probe-timeout"
+
+	DriftPolicyKey = ConfigPrefix + "-drift-policy"
+
+	ReconcileTimeoutKey = ConfigPrefix + "-reconcile-timeout"
+
+	RequeueInitialDelayKey = ConfigPrefix + "-requeue-initial-delay"
+	RequeueMaxDelayKey     = ConfigPrefix + "-requeue-max-delay"
+	RequeueJitterKey       = ConfigPrefix + "-requeue-jitter"
+	ResyncIntervalKey      = ConfigPrefix + "-resync-interval"
+
+	// CertificateEncryptionSlice is the deprecated slice of the key settings of registry token and notary certificates,
+	// replaced by spec.certificates of Harbors.
+	CertificateEncryptionSlice = "certificate-encryption"
 )
 
+// Keys are the configuration keys of the Harbor controller.
+var Keys = []string{
+	ReconciliationKey, WatchChildrenKey, HarborClassKey, NamespacesKey, SelectorKey, HarborClassesKey,
+	HealthProbeModeKey, HealthProbeIntervalKey, HealthProbeTimeoutKey,
+	SyntheticProbeTimeoutKey, DriftPolicyKey, ReconcileTimeoutKey,
+	RequeueInitialDelayKey, RequeueMaxDelayKey, RequeueJitterKey, ResyncIntervalKey,
+}
+
 const (
 	DefaultConcurrentReconcile = 1
 	DefaultWatchChildren       = true
+	DefaultHarborClasses       = true
 	DefaultHarborClass         = ""
+	DefaultHealthProbeMode     = harbor.ProxyHealthProbeMode
 )
 
 func getWatchChildrenConfiguration() (bool, error) {
@@ -36,6 +74,20 @@ func getWatchChildrenConfiguration() (bool, error) {
 	return watchChildren, nil
 }
 
+func getHarborClassesConfiguration() (bool, error) {
+	harborClasses, err := configstore.Filter().GetItemValueBool(HarborClassesKey)
+	if err != nil {
+		_, ok := err.(configstore.ErrItemNotFound)
+		if !ok {
+			return false, errors.Wrapf(err, "key %s", HarborClassesKey)
+		}
+
+		harborClasses = DefaultHarborClasses
+	}
+
+	return harborClasses, nil
+}
+
 func getConcurrentConfiguration() (int, error) {
 	concurrentReconciles, err := configstore.Filter().GetItemValueInt(ReconciliationKey)
 	if err != nil {
@@ -64,6 +116,221 @@ func getHarborClassConfiguration() (string, error) {
 	return harborClass, nil
 }
 
+func getNamespacesConfiguration() ([]string, error) {
+	value, err := configstore.Filter().GetItemValue(NamespacesKey)
+	if err != nil {
+		_, ok := err.(configstore.ErrItemNotFound)
+		if !ok {
+			return nil, errors.Wrapf(err, "key %s", NamespacesKey)
+		}
+
+		return nil, nil
+	}
+
+	var namespaces []string
+
+	for _, namespace := range strings.Split(value, ",") {
+		namespace = strings.TrimSpace(namespace)
+		if namespace == "" {
+			continue
+		}
+
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return nil, errors.Errorf("key %s: invalid namespace %q: %s", NamespacesKey, namespace, strings.Join(errs, ", "))
+		}
+
+		namespaces = append(namespaces, namespace)
+	}
+
+	return namespaces, nil
+}
+
+func getSelectorConfiguration() (labels.Selector, error) {
+	value, err := configstore.Filter().GetItemValue(SelectorKey)
+	if err != nil {
+		_, ok := err.(configstore.ErrItemNotFound)
+		if !ok {
+			return nil, errors.Wrapf(err, "key %s", SelectorKey)
+		}
+
+		return nil, nil
+	}
+
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	selector, err := labels.Parse(value)
+
+	return selector, errors.Wrapf(err, "key %s", SelectorKey)
+}
+
+func getHealthProbeConfiguration() (harbor.HealthProbeConfig, error) {
+	config := harbor.HealthProbeConfig{
+		Mode:     DefaultHealthProbeMode,
+		Interval: harbor.DefaultHealthProbeInterval,
+		Timeout:  harbor.DefaultHealthProbeTimeout,
+	}
+
+	mode, err := configstore.Filter().GetItemValue(HealthProbeModeKey)
+	if err != nil {
+		_, ok := err.(configstore.ErrItemNotFound)
+		if !ok {
+			return config, errors.Wrapf(err, "key %s", HealthProbeModeKey)
+		}
+	} else {
+		config.Mode = harbor.HealthProbeMode(mode)
+	}
+
+	switch config.Mode {
+	case harbor.ProxyHealthProbeMode, harbor.DirectHealthProbeMode:
+	default:
+		return config, errors.Errorf("key %s: unsupported mode %q", HealthProbeModeKey, config.Mode)
+	}
+
+	interval, err := configstore.Filter().GetItemValueDuration(HealthProbeIntervalKey)
+	if err != nil {
+		_, ok := err.(configstore.ErrItemNotFound)
+		if !ok {
+			return config, errors.Wrapf(err, "key %s", HealthProbeIntervalKey)
+		}
+	} else {
+		config.Interval = interval
+	}
+
+	timeout, err := configstore.Filter().GetItemValueDuration(HealthProbeTimeoutKey)
+	if err != nil {
+		_, ok := err.(configstore.ErrItemNotFound)
+		if !ok {
+			return config, errors.Wrapf(err, "key %s", HealthProbeTimeoutKey)
+		}
+	} else {
+		config.Timeout = timeout
+	}
+
+	return config, nil
+}
+
+func getSyntheticProbeTimeoutConfiguration() (time.Duration, error) {
+	timeout, err := configstore.Filter().GetItemValueDuration(SyntheticProbeTimeoutKey)
+	if err != nil {
+		_, ok := err.(configstore.ErrItemNotFound)
+		if !ok {
+			return 0, errors.Wrapf(err, "key %s", SyntheticProbeTimeoutKey)
+		}
+
+		timeout = harbor.DefaultSyntheticProbeTimeout
+	}
+
+	return timeout, nil
+}
+
+func getDriftPolicyConfiguration() (harbor.DriftPolicy, error) {
+	policy, err := configstore.Filter().GetItemValue(DriftPolicyKey)
+	if err != nil {
+		_, ok := err.(configstore.ErrItemNotFound)
+		if !ok {
+			return "", errors.Wrapf(err, "key %s", DriftPolicyKey)
+		}
+
+		return harbor.DefaultDriftPolicy, nil
+	}
+
+	switch harbor.DriftPolicy(policy) {
+	case harbor.CorrectDriftPolicy, harbor.ReportDriftPolicy:
+		return harbor.DriftPolicy(policy), nil
+	default:
+		return "", errors.Errorf("key %s: unsupported policy %q", DriftPolicyKey, policy)
+	}
+}
+
+func getReconcileTimeoutConfiguration() (time.Duration, error) {
+	timeout, err := configstore.Filter().GetItemValueDuration(ReconcileTimeoutKey)
+	if err != nil {
+		_, ok := err.(configstore.ErrItemNotFound)
+		if !ok {
+			return 0, errors.Wrapf(err, "key %s", ReconcileTimeoutKey)
+		}
+
+		return harbor.DefaultReconcileTimeout, nil
+	}
+
+	if timeout <= 0 {
+		return 0, errors.Errorf("key %s: timeout must be positive, got %s", ReconcileTimeoutKey, timeout)
+	}
+
+	return timeout, nil
+}
+
+func getRequeueConfiguration() (harbor.RequeueConfig, error) {
+	config := harbor.GetDefaultRequeueConfig()
+
+	durations := []struct {
+		key   string
+		value *time.Duration
+	}{
+		{RequeueInitialDelayKey, &config.InitialDelay},
+		{RequeueMaxDelayKey, &config.MaxDelay},
+		{ResyncIntervalKey, &config.ResyncInterval},
+	}
+
+	for _, duration := range durations {
+		value, err := configstore.Filter().GetItemValueDuration(duration.key)
+		if err != nil {
+			_, ok := err.(configstore.ErrItemNotFound)
+			if !ok {
+				return config, errors.Wrapf(err, "key %s", duration.key)
+			}
+		} else {
+			*duration.value = value
+		}
+	}
+
+	jitter, err := configstore.Filter().GetItemValueFloat(RequeueJitterKey)
+	if err != nil {
+		_, ok := err.(configstore.ErrItemNotFound)
+		if !ok {
+			return config, errors.Wrapf(err, "key %s", RequeueJitterKey)
+		}
+	} else {
+		config.Jitter = jitter
+	}
+
+	return config, config.Validate()
+}
+
+type certificateEncryption struct {
+	KeySize      int
+	KeyAlgorithm certv1.KeyAlgorithm
+}
+
+func getCertificateEncryptionConfiguration() (*goharborv1alpha1.CertificateSettings, error) {
+	item, err := configstore.Filter().
+		Slice(CertificateEncryptionSlice).
+		Unmarshal(func() interface{} { return &certificateEncryption{} }).
+		GetFirstItem()
+	if err != nil {
+		_, ok := err.(configstore.ErrItemNotFound)
+		if !ok {
+			return nil, errors.Wrapf(err, "slice %s", CertificateEncryptionSlice)
+		}
+
+		return nil, nil
+	}
+
+	value, err := item.Unmarshaled()
+	if err != nil {
+		return nil, errors.Wrapf(err, "slice %s", CertificateEncryptionSlice)
+	}
+
+	encryption := value.(*certificateEncryption)
+
+	return &goharborv1alpha1.CertificateSettings{
+		KeySize:      encryption.KeySize,
+		KeyAlgorithm: encryption.KeyAlgorithm,
+	}, nil
+}
+
 func GetConfig() (*harbor.Config, error) {
 	watchChildren, err := getWatchChildrenConfiguration()
 	if err != nil {
@@ -80,18 +347,67 @@ func GetConfig() (*harbor.Config, error) {
 		return nil, errors.Wrap(err, "fail to get harbor class configuration")
 	}
 
-	return &harbor.Config{
-		ConcurrentReconciles: concurrentReconciles,
-		WatchChildren:        watchChildren,
-		ClassName:            className,
-	}, nil
-}
+	namespaces, err := getNamespacesConfiguration()
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to get namespaces configuration")
+	}
+
+	selector, err := getSelectorConfiguration()
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to get selector configuration")
+	}
+
+	harborClasses, err := getHarborClassesConfiguration()
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to get harbor classes configuration")
+	}
 
-func New(ctx context.Context, name, version string) (*harbor.Reconciler, error) {
-	config, err := GetConfig()
+	healthProbe, err := getHealthProbeConfiguration()
 	if err != nil {
-		return nil, errors.Wrap(err, "cannot get configuration")
+		return nil, errors.Wrap(err, "fail to get health probe configuration")
 	}
 
+	syntheticProbeTimeout, err := getSyntheticProbeTimeoutConfiguration()
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to get synthetic probe configuration")
+	}
+
+	driftPolicy, err := getDriftPolicyConfiguration()
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to get drift policy configuration")
+	}
+
+	reconcileTimeout, err := getReconcileTimeoutConfiguration()
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to get reconcile timeout configuration")
+	}
+
+	requeue, err := getRequeueConfiguration()
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to get requeue configuration")
+	}
+
+	legacyCertificates, err := getCertificateEncryptionConfiguration()
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to get certificate encryption configuration")
+	}
+
+	return &harbor.Config{
+		ConcurrentReconciles:  concurrentReconciles,
+		WatchChildren:         watchChildren,
+		ClassName:             className,
+		Namespaces:            namespaces,
+		Selector:              selector,
+		HarborClasses:         harborClasses,
+		HealthProbe:           healthProbe,
+		SyntheticProbeTimeout: syntheticProbeTimeout,
+		DriftPolicy:           driftPolicy,
+		ReconcileTimeout:      reconcileTimeout,
+		Requeue:               requeue,
+		LegacyCertificates:    legacyCertificates,
+	}, nil
+}
+
+func New(ctx context.Context, name, version string, config *harbor.Config) (*harbor.Reconciler, error) {
 	return harbor.New(ctx, name, version, config)
 }
@@ -0,0 +1,142 @@
+package event
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	// DefaultInterval is the period during which an identical event is not emitted again.
+	DefaultInterval = 5 * time.Minute
+	// DefaultBurst is the maximum number of events emitted on an object during the interval.
+	DefaultBurst = 25
+)
+
+type eventKey struct {
+	uid       types.UID
+	eventType string
+	reason    string
+	message   string
+}
+
+type objectWindow struct {
+	start time.Time
+	count int
+}
+
+// RateLimitedRecorder drops events identical to one emitted on the same object less than interval ago,
+// and emits at most burst events per object and per interval, so reconciliation loops do not spam events.
+type RateLimitedRecorder struct {
+	recorder record.EventRecorder
+
+	interval time.Duration
+	burst    int
+
+	now func() time.Time
+
+	lock    sync.Mutex
+	sent    map[eventKey]time.Time
+	objects map[types.UID]*objectWindow
+}
+
+var _ record.EventRecorder = &RateLimitedRecorder{}
+
+func NewRateLimitedRecorder(recorder record.EventRecorder, interval time.Duration, burst int) *RateLimitedRecorder {
+	return &RateLimitedRecorder{
+		recorder: recorder,
+		interval: interval,
+		burst:    burst,
+		now:      time.Now,
+		sent:     map[eventKey]time.Time{},
+		objects:  map[types.UID]*objectWindow{},
+	}
+}
+
+// allow returns true if the event must be emitted, and records it.
+func (r *RateLimitedRecorder) allow(object runtime.Object, eventType, reason, message string) bool {
+	accessor, err := meta.Accessor(object)
+	if err != nil {
+		// Let the underlying recorder report the invalid object
+		return true
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := r.now()
+
+	r.expire(now)
+
+	key := eventKey{
+		uid:       accessor.GetUID(),
+		eventType: eventType,
+		reason:    reason,
+		message:   message,
+	}
+
+	if _, ok := r.sent[key]; ok {
+		return false
+	}
+
+	window, ok := r.objects[key.uid]
+	if !ok {
+		window = &objectWindow{start: now}
+		r.objects[key.uid] = window
+	}
+
+	if window.count >= r.burst {
+		return false
+	}
+
+	window.count++
+	r.sent[key] = now
+
+	return true
+}
+
+func (r *RateLimitedRecorder) expire(now time.Time) {
+	for key, sent := range r.sent {
+		if now.Sub(sent) >= r.interval {
+			delete(r.sent, key)
+		}
+	}
+
+	for uid, window := range r.objects {
+		if now.Sub(window.start) >= r.interval {
+			delete(r.objects, uid)
+		}
+	}
+}
+
+func (r *RateLimitedRecorder) Event(object runtime.Object, eventType, reason, message string) {
+	if r.allow(object, eventType, reason, message) {
+		r.recorder.Event(object, eventType, reason, message)
+	}
+}
+
+func (r *RateLimitedRecorder) Eventf(object runtime.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	r.Event(object, eventType, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r *RateLimitedRecorder) PastEventf(object runtime.Object, timestamp metav1.Time, eventType, reason, messageFmt string, args ...interface{}) {
+	message := fmt.Sprintf(messageFmt, args...)
+
+	if r.allow(object, eventType, reason, message) {
+		r.recorder.PastEventf(object, timestamp, eventType, reason, "%s", message)
+	}
+}
+
+func (r *RateLimitedRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventType, reason, messageFmt string, args ...interface{}) {
+	message := fmt.Sprintf(messageFmt, args...)
+
+	if r.allow(object, eventType, reason, message) {
+		r.recorder.AnnotatedEventf(object, annotations, eventType, reason, "%s", message)
+	}
+}
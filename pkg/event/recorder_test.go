@@ -0,0 +1,59 @@
+package event
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+)
+
+func TestEvent(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecsWithDefaultAndCustomReporters(t, "Event Suite", []Reporter{envtest.NewlineReporter{}})
+}
+
+var _ = Describe("RateLimitedRecorder", func() {
+	var fake *record.FakeRecorder
+	var recorder *RateLimitedRecorder
+	var now time.Time
+	var object *corev1.ConfigMap
+
+	BeforeEach(func() {
+		fake = record.NewFakeRecorder(10)
+		recorder = NewRateLimitedRecorder(fake, time.Minute, 3)
+
+		now = time.Now()
+		recorder.now = func() time.Time { return now }
+
+		object = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{UID: "uid"}}
+	})
+
+	It("Should drop identical events until the interval elapsed", func() {
+		recorder.Eventf(object, corev1.EventTypeNormal, "Created", "%s created", "core")
+		recorder.Eventf(object, corev1.EventTypeNormal, "Created", "%s created", "core")
+		Expect(fake.Events).To(HaveLen(1))
+		Expect(<-fake.Events).To(Equal("Normal Created core created"))
+
+		now = now.Add(time.Minute)
+
+		recorder.Eventf(object, corev1.EventTypeNormal, "Created", "%s created", "core")
+		Expect(fake.Events).To(HaveLen(1))
+	})
+
+	It("Should limit the number of events per object", func() {
+		for _, reason := range []string{"A", "B", "C", "D"} {
+			recorder.Event(object, corev1.EventTypeWarning, reason, "message")
+		}
+
+		Expect(fake.Events).To(HaveLen(3))
+
+		recorder.Event(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{UID: "other"}}, corev1.EventTypeWarning, "D", "message")
+		Expect(fake.Events).To(HaveLen(4))
+	})
+})
@@ -0,0 +1,202 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	Namespace = "harbor_operator"
+
+	NamespaceLabel = "namespace"
+	HarborLabel    = "harbor"
+	ComponentLabel = "component"
+	KindLabel      = "kind"
+)
+
+var (
+	// ReconcileDuration is the time spent applying the resources of a kind for a component.
+	ReconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "reconcile_duration_seconds",
+		Help:      "Time spent reconciling the resources of a kind for a Harbor component.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 12), // nolint:gomnd
+	}, []string{NamespaceLabel, HarborLabel, ComponentLabel, KindLabel})
+
+	// ReconcileErrors counts failed reconciliations of the resources of a kind for a component.
+	ReconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "reconcile_errors_total",
+		Help:      "Number of failed reconciliations of the resources of a kind for a Harbor component.",
+	}, []string{NamespaceLabel, HarborLabel, ComponentLabel, KindLabel})
+
+	// HarborReconcileFailures counts reconciliations of a Harbor ending with an error.
+	// Failed reconciliations are requeued without error, so they are not counted by controller-runtime.
+	HarborReconcileFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "harbor_reconcile_failures_total",
+		Help:      "Number of reconciliations of a Harbor ending with an error.",
+	}, []string{NamespaceLabel, HarborLabel})
+
+	// SyntheticProbeDuration is the time spent pushing, pulling and deleting the synthetic probe image.
+	SyntheticProbeDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "synthetic_probe_duration_seconds",
+		Help:      "Time spent pushing, pulling and deleting the synthetic probe image of a Harbor.",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 2, 10), // nolint:gomnd
+	}, []string{NamespaceLabel, HarborLabel})
+
+	// SyntheticProbeSuccess is 1 when the last synthetic probe succeeded.
+	SyntheticProbeSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "synthetic_probe_success",
+		Help:      "Result of the last synthetic probe of a Harbor, 1 when the image was pushed and pulled.",
+	}, []string{NamespaceLabel, HarborLabel})
+
+	// UpgradeDuration is the time from a change of the version or images of a Harbor until it is ready again.
+	UpgradeDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "upgrade_duration_seconds",
+		Help:      "Time from a change of the version or images of a Harbor until its workloads are ready again.",
+		Buckets:   prometheus.ExponentialBuckets(5, 2, 10), // nolint:gomnd
+	}, []string{NamespaceLabel, HarborLabel})
+
+	// UpgradeInProgress is 1 while a Harbor is upgraded.
+	UpgradeInProgress = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "upgrade_in_progress",
+		Help:      "1 while the new version or images of a Harbor are rolled out, until its workloads are ready.",
+	}, []string{NamespaceLabel, HarborLabel})
+)
+
+func init() { // nolint:gochecknoinits
+	metrics.Registry.MustRegister(ReconcileDuration, ReconcileErrors, HarborReconcileFailures, SyntheticProbeDuration, SyntheticProbeSuccess,
+		UpgradeDuration, UpgradeInProgress)
+}
+
+var observed = struct {
+	sync.Mutex
+	runs map[types.NamespacedName]map[[2]string]bool
+}{
+	runs: map[types.NamespacedName]map[[2]string]bool{},
+}
+
+// ObserveComponentRun records the duration and the result of the reconciliation of the resources of a kind.
+func ObserveComponentRun(harbor types.NamespacedName, component, kind string, duration time.Duration, err error) {
+	observed.Lock()
+	defer observed.Unlock()
+
+	if observed.runs[harbor] == nil {
+		observed.runs[harbor] = map[[2]string]bool{}
+	}
+
+	observed.runs[harbor][[2]string{component, kind}] = true
+
+	ReconcileDuration.WithLabelValues(harbor.Namespace, harbor.Name, component, kind).Observe(duration.Seconds())
+
+	counter := ReconcileErrors.WithLabelValues(harbor.Namespace, harbor.Name, component, kind)
+	if err != nil {
+		counter.Inc()
+	}
+}
+
+// ObserveReconcileFailure counts a reconciliation of the Harbor ending with an error.
+func ObserveReconcileFailure(harbor types.NamespacedName) {
+	HarborReconcileFailures.WithLabelValues(harbor.Namespace, harbor.Name).Inc()
+}
+
+// ObserveSyntheticProbe records the duration and the result of a synthetic probe.
+func ObserveSyntheticProbe(harbor types.NamespacedName, duration time.Duration, err error) {
+	SyntheticProbeDuration.WithLabelValues(harbor.Namespace, harbor.Name).Observe(duration.Seconds())
+
+	success := 1.
+	if err != nil {
+		success = 0
+	}
+
+	SyntheticProbeSuccess.WithLabelValues(harbor.Namespace, harbor.Name).Set(success)
+}
+
+// ForgetSyntheticProbe deletes the synthetic probe series of a Harbor no longer probed.
+func ForgetSyntheticProbe(harbor types.NamespacedName) {
+	SyntheticProbeDuration.DeleteLabelValues(harbor.Namespace, harbor.Name)
+	SyntheticProbeSuccess.DeleteLabelValues(harbor.Namespace, harbor.Name)
+}
+
+type upgrade struct {
+	version string
+	// start is the time the version changed, zero when the Harbor is not upgraded.
+	start time.Time
+}
+
+var upgrades = struct {
+	sync.Mutex
+	harbors map[types.NamespacedName]*upgrade
+}{
+	harbors: map[types.NamespacedName]*upgrade{},
+}
+
+// ObserveVersion records the version of a Harbor, made of its version and images, and whether it is ready.
+// An upgrade starts when the version changes and ends once the Harbor is ready with it.
+// The first version observed, at the creation of the Harbor or the start of the operator, is not an upgrade.
+func ObserveVersion(harbor types.NamespacedName, version string, ready bool, now time.Time) {
+	upgrades.Lock()
+	defer upgrades.Unlock()
+
+	current, ok := upgrades.harbors[harbor]
+	if !ok {
+		upgrades.harbors[harbor] = &upgrade{version: version}
+		UpgradeInProgress.WithLabelValues(harbor.Namespace, harbor.Name).Set(0)
+
+		return
+	}
+
+	if current.version != version {
+		current.version = version
+
+		// An upgrade to another version while upgrading lasts until the last one is ready
+		if current.start.IsZero() {
+			current.start = now
+		}
+
+		UpgradeInProgress.WithLabelValues(harbor.Namespace, harbor.Name).Set(1)
+
+		// The readiness may be computed from workloads not updated yet
+		return
+	}
+
+	if !ready || current.start.IsZero() {
+		return
+	}
+
+	UpgradeDuration.WithLabelValues(harbor.Namespace, harbor.Name).Observe(now.Sub(current.start).Seconds())
+	UpgradeInProgress.WithLabelValues(harbor.Namespace, harbor.Name).Set(0)
+
+	current.start = time.Time{}
+}
+
+// Forget deletes the series of a deleted Harbor.
+func Forget(harbor types.NamespacedName) {
+	ForgetSyntheticProbe(harbor)
+	HarborReconcileFailures.DeleteLabelValues(harbor.Namespace, harbor.Name)
+	UpgradeDuration.DeleteLabelValues(harbor.Namespace, harbor.Name)
+	UpgradeInProgress.DeleteLabelValues(harbor.Namespace, harbor.Name)
+
+	upgrades.Lock()
+	delete(upgrades.harbors, harbor)
+	upgrades.Unlock()
+
+	observed.Lock()
+	defer observed.Unlock()
+
+	for run := range observed.runs[harbor] {
+		ReconcileDuration.DeleteLabelValues(harbor.Namespace, harbor.Name, run[0], run[1])
+		ReconcileErrors.DeleteLabelValues(harbor.Namespace, harbor.Name, run[0], run[1])
+	}
+
+	delete(observed.runs, harbor)
+}
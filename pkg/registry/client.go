@@ -0,0 +1,292 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	TokenPath = "/service/token"
+
+	// DefaultService is the service the Harbor token service issues registry tokens for.
+	DefaultService = "harbor-registry"
+)
+
+// ErrUnauthorized is the cause of errors due to rejected credentials.
+var ErrUnauthorized = errors.New("unauthorized")
+
+// Client pushes and pulls images through the Registry v2 API,
+// with bearer tokens issued by the Harbor token service.
+type Client struct {
+	// URL is the base url of the registry and of the token service.
+	URL *url.URL
+
+	HTTPClient *http.Client
+
+	Username string
+	Password string
+
+	UserAgent string
+}
+
+type tokenResponse struct {
+	Token       string `json:"token"`
+	AccessToken string `json:"access_token"`
+}
+
+// Token returns a token granting actions on the repository.
+func (c *Client) Token(ctx context.Context, repository string, actions ...string) (string, error) {
+	query := url.Values{}
+	query.Set("service", DefaultService)
+	query.Set("scope", fmt.Sprintf("repository:%s:%s", repository, strings.Join(actions, ",")))
+
+	tokenURL := c.URL.ResolveReference(&url.URL{Path: TokenPath, RawQuery: query.Encode()})
+
+	request, err := c.newRequest(ctx, http.MethodGet, tokenURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	request.SetBasicAuth(c.Username, c.Password)
+
+	response, err := c.HTTPClient.Do(request)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot get token")
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized:
+		return "", errors.Wrap(ErrUnauthorized, "cannot get token")
+	default:
+		return "", errors.Errorf("cannot get token: %s", response.Status)
+	}
+
+	var token tokenResponse
+
+	err = json.NewDecoder(response.Body).Decode(&token)
+	if err != nil {
+		return "", errors.Wrap(err, "unexpected token response")
+	}
+
+	if token.Token != "" {
+		return token.Token, nil
+	}
+
+	if token.AccessToken != "" {
+		return token.AccessToken, nil
+	}
+
+	return "", errors.Wrap(ErrUnauthorized, "empty token")
+}
+
+// Push uploads the blobs of the image then its manifest with the tag.
+func (c *Client) Push(ctx context.Context, repository, tag string, image *Image) error {
+	token, err := c.Token(ctx, repository, "pull", "push")
+	if err != nil {
+		return err
+	}
+
+	err = c.pushBlob(ctx, token, repository, image.LayerDigest, image.Layer)
+	if err != nil {
+		return errors.Wrap(err, "layer")
+	}
+
+	err = c.pushBlob(ctx, token, repository, image.ConfigDigest, image.Config)
+	if err != nil {
+		return errors.Wrap(err, "config")
+	}
+
+	response, err := c.do(ctx, token, http.MethodPut, c.v2URL(repository, "manifests", tag), ManifestMediaType, image.Manifest, http.StatusCreated)
+	if err != nil {
+		return errors.Wrap(err, "cannot push manifest")
+	}
+	defer response.Body.Close()
+
+	if digest := response.Header.Get("Docker-Content-Digest"); digest != "" && digest != image.ManifestDigest {
+		return errors.Errorf("pushed manifest digest %s, expected %s", digest, image.ManifestDigest)
+	}
+
+	return nil
+}
+
+// Pull downloads the manifest with the tag and the layer, and verifies they match the image.
+func (c *Client) Pull(ctx context.Context, repository, tag string, image *Image) error {
+	token, err := c.Token(ctx, repository, "pull")
+	if err != nil {
+		return err
+	}
+
+	manifest, err := c.get(ctx, token, c.v2URL(repository, "manifests", tag), ManifestMediaType)
+	if err != nil {
+		return errors.Wrap(err, "cannot pull manifest")
+	}
+
+	if digest := Digest(manifest); digest != image.ManifestDigest {
+		return errors.Errorf("pulled manifest digest %s, expected %s", digest, image.ManifestDigest)
+	}
+
+	layer, err := c.get(ctx, token, c.v2URL(repository, "blobs", image.LayerDigest), "")
+	if err != nil {
+		return errors.Wrap(err, "cannot pull layer")
+	}
+
+	if digest := Digest(layer); digest != image.LayerDigest {
+		return errors.Errorf("pulled layer digest %s, expected %s", digest, image.LayerDigest)
+	}
+
+	return nil
+}
+
+// pushBlob uploads the blob in a single request, unless it already exists.
+func (c *Client) pushBlob(ctx context.Context, token, repository, digest string, data []byte) error {
+	response, err := c.do(ctx, token, http.MethodHead, c.v2URL(repository, "blobs", digest), "", nil, http.StatusOK, http.StatusNotFound)
+	if err != nil {
+		return errors.Wrap(err, "cannot check blob")
+	}
+
+	response.Body.Close()
+
+	if response.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	response, err = c.do(ctx, token, http.MethodPost, c.v2URL(repository, "blobs", "uploads")+"/", "", nil, http.StatusAccepted)
+	if err != nil {
+		return errors.Wrap(err, "cannot start upload")
+	}
+
+	response.Body.Close()
+
+	location, err := response.Location()
+	if err != nil {
+		return errors.Wrap(err, "invalid upload location")
+	}
+
+	query := location.Query()
+	query.Set("digest", digest)
+	location.RawQuery = query.Encode()
+
+	response, err = c.do(ctx, token, http.MethodPut, location.String(), "application/octet-stream", data, http.StatusCreated)
+	if err != nil {
+		return errors.Wrap(err, "cannot upload blob")
+	}
+
+	response.Body.Close()
+
+	return nil
+}
+
+func (c *Client) get(ctx context.Context, token, u, accept string) ([]byte, error) {
+	request, err := c.newRequest(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	if accept != "" {
+		request.Header.Set("Accept", accept)
+	}
+
+	response, err := c.HTTPClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, getStatusError(response)
+	}
+
+	data, err := ioutil.ReadAll(response.Body)
+
+	return data, errors.Wrap(err, "cannot read response")
+}
+
+// do sends the request and checks the status code of the response, whose body must be closed.
+func (c *Client) do(ctx context.Context, token, method, u, contentType string, body []byte, statusCodes ...int) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	request, err := c.newRequest(ctx, method, u, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+
+	response, err := c.HTTPClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, statusCode := range statusCodes {
+		if response.StatusCode == statusCode {
+			return response, nil
+		}
+	}
+
+	defer response.Body.Close()
+
+	return nil, getStatusError(response)
+}
+
+func (c *Client) newRequest(ctx context.Context, method, u string, body io.Reader) (*http.Request, error) {
+	request, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create request")
+	}
+
+	if c.UserAgent != "" {
+		request.Header.Set("User-Agent", c.UserAgent)
+	}
+
+	return request.WithContext(ctx), nil
+}
+
+func (c *Client) v2URL(repository, kind, reference string) string {
+	return c.URL.ResolveReference(&url.URL{Path: fmt.Sprintf("/v2/%s/%s/%s", repository, kind, reference)}).String()
+}
+
+// registryErrors is the body of Registry v2 API errors.
+type registryErrors struct {
+	Errors []struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func getStatusError(response *http.Response) error {
+	var body registryErrors
+
+	_ = json.NewDecoder(io.LimitReader(response.Body, 1<<16)).Decode(&body) // nolint:gomnd
+
+	message := response.Status
+
+	for _, e := range body.Errors {
+		message = fmt.Sprintf("%s: %s %s", message, e.Code, e.Message)
+	}
+
+	if response.StatusCode == http.StatusUnauthorized {
+		return errors.Wrap(ErrUnauthorized, message)
+	}
+
+	return errors.New(message)
+}
@@ -0,0 +1,137 @@
+package registry
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	ManifestMediaType = "application/vnd.docker.distribution.manifest.v2+json"
+	ConfigMediaType   = "application/vnd.docker.container.image.v1+json"
+	LayerMediaType    = "application/vnd.docker.image.rootfs.diff.tar.gzip"
+
+	imageFileName = "probe"
+)
+
+// Image is a generated image with a single layer.
+type Image struct {
+	Config       []byte
+	ConfigDigest string
+
+	Layer       []byte
+	LayerDigest string
+
+	Manifest       []byte
+	ManifestDigest string
+}
+
+type descriptor struct {
+	MediaType string `json:"mediaType"`
+	Size      int    `json:"size"`
+	Digest    string `json:"digest"`
+}
+
+type manifest struct {
+	SchemaVersion int          `json:"schemaVersion"`
+	MediaType     string       `json:"mediaType"`
+	Config        descriptor   `json:"config"`
+	Layers        []descriptor `json:"layers"`
+}
+
+// Digest returns the sha256 digest of data.
+func Digest(data []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+}
+
+// NewImage generates an image whose layer contains a single file with content,
+// so images generated with different contents have different digests.
+func NewImage(content []byte, created time.Time) (*Image, error) {
+	var diff bytes.Buffer
+
+	tw := tar.NewWriter(&diff)
+
+	err := tw.WriteHeader(&tar.Header{
+		Name:    imageFileName,
+		Mode:    0644, // nolint:gomnd
+		Size:    int64(len(content)),
+		ModTime: created,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot write layer")
+	}
+
+	_, err = tw.Write(content)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot write layer")
+	}
+
+	err = tw.Close()
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot write layer")
+	}
+
+	var compressed bytes.Buffer
+
+	gz := gzip.NewWriter(&compressed)
+
+	_, err = gz.Write(diff.Bytes())
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot compress layer")
+	}
+
+	err = gz.Close()
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot compress layer")
+	}
+
+	image := &Image{
+		Layer:       compressed.Bytes(),
+		LayerDigest: Digest(compressed.Bytes()),
+	}
+
+	image.Config, err = json.Marshal(map[string]interface{}{
+		"architecture": "amd64",
+		"os":           "linux",
+		"created":      created.UTC().Format(time.RFC3339),
+		"config":       map[string]interface{}{},
+		"rootfs": map[string]interface{}{
+			"type": "layers",
+			// Layers are identified by their uncompressed digest in the config
+			"diff_ids": []string{Digest(diff.Bytes())},
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot marshal config")
+	}
+
+	image.ConfigDigest = Digest(image.Config)
+
+	image.Manifest, err = json.Marshal(manifest{
+		SchemaVersion: 2, // nolint:gomnd
+		MediaType:     ManifestMediaType,
+		Config: descriptor{
+			MediaType: ConfigMediaType,
+			Size:      len(image.Config),
+			Digest:    image.ConfigDigest,
+		},
+		Layers: []descriptor{{
+			MediaType: LayerMediaType,
+			Size:      len(image.Layer),
+			Digest:    image.LayerDigest,
+		}},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot marshal manifest")
+	}
+
+	image.ManifestDigest = Digest(image.Manifest)
+
+	return image, nil
+}
@@ -0,0 +1,181 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+)
+
+func TestRegistry(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecsWithDefaultAndCustomReporters(t, "Registry Suite", []Reporter{envtest.NewlineReporter{}})
+}
+
+// fakeRegistry stores blobs and manifests in memory.
+type fakeRegistry struct {
+	lock      sync.Mutex
+	blobs     map[string][]byte
+	manifests map[string][]byte
+	corrupt   bool
+}
+
+func (f *fakeRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if r.URL.Path == TokenPath {
+		username, password, _ := r.BasicAuth()
+		if username != "robot$probe" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]string{"token": "token"})
+
+		return
+	}
+
+	if r.Header.Get("Authorization") != "Bearer token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/v2/probe/test/")
+
+	switch {
+	case r.Method == http.MethodHead && strings.HasPrefix(path, "blobs/"):
+		if _, ok := f.blobs[strings.TrimPrefix(path, "blobs/")]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "blobs/"):
+		blob, ok := f.blobs[strings.TrimPrefix(path, "blobs/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if f.corrupt {
+			blob = []byte("corrupted")
+		}
+
+		_, _ = w.Write(blob)
+	case r.Method == http.MethodPost && path == "blobs/uploads/":
+		w.Header().Set("Location", "/v2/probe/test/blobs/uploads/1?state=a")
+		w.WriteHeader(http.StatusAccepted)
+	case r.Method == http.MethodPut && path == "blobs/uploads/1":
+		Expect(r.URL.Query().Get("state")).To(Equal("a"))
+
+		data, _ := ioutil.ReadAll(r.Body)
+		f.blobs[r.URL.Query().Get("digest")] = data
+
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPut && strings.HasPrefix(path, "manifests/"):
+		data, _ := ioutil.ReadAll(r.Body)
+		f.manifests[strings.TrimPrefix(path, "manifests/")] = data
+
+		w.Header().Set("Docker-Content-Digest", Digest(data))
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "manifests/"):
+		manifest, ok := f.manifests[strings.TrimPrefix(path, "manifests/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[{"code":"MANIFEST_UNKNOWN","message":"manifest unknown"}]}`))
+
+			return
+		}
+
+		_, _ = w.Write(manifest)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+var _ = Describe("Registry", func() {
+	var registry *fakeRegistry
+	var server *httptest.Server
+	var client *Client
+	var image *Image
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+
+		registry = &fakeRegistry{
+			blobs:     map[string][]byte{},
+			manifests: map[string][]byte{},
+		}
+
+		server = httptest.NewServer(registry)
+
+		u, err := url.Parse(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+
+		client = &Client{
+			URL:        u,
+			HTTPClient: server.Client(),
+			Username:   "robot$probe",
+			Password:   "secret",
+		}
+
+		image, err = NewImage([]byte("test"), time.Unix(0, 0))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Should generate different images from different contents", func() {
+		other, err := NewImage([]byte("other"), time.Unix(0, 0))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(other.ManifestDigest).ToNot(Equal(image.ManifestDigest))
+
+		same, err := NewImage([]byte("test"), time.Unix(0, 0))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(same.ManifestDigest).To(Equal(image.ManifestDigest))
+	})
+
+	It("Should push and pull back the image", func() {
+		Expect(client.Push(ctx, "probe/test", "latest", image)).To(Succeed())
+		Expect(registry.blobs).To(HaveKeyWithValue(image.LayerDigest, image.Layer))
+		Expect(registry.blobs).To(HaveKeyWithValue(image.ConfigDigest, image.Config))
+
+		Expect(client.Pull(ctx, "probe/test", "latest", image)).To(Succeed())
+	})
+
+	It("Should detect corrupted layers", func() {
+		Expect(client.Push(ctx, "probe/test", "latest", image)).To(Succeed())
+
+		registry.corrupt = true
+
+		err := client.Pull(ctx, "probe/test", "latest", image)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("pulled layer digest"))
+	})
+
+	It("Should report registry errors", func() {
+		err := client.Pull(ctx, "probe/test", "missing", image)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("MANIFEST_UNKNOWN manifest unknown"))
+	})
+
+	It("Should report rejected credentials", func() {
+		client.Password = "invalid"
+
+		err := client.Push(ctx, "probe/test", "latest", image)
+		Expect(errors.Cause(err)).To(Equal(ErrUnauthorized))
+	})
+})
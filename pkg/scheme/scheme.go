@@ -4,6 +4,7 @@ import (
 	"context"
 
 	certv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha2"
+	routev1 "github.com/openshift/api/route/v1"
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -24,6 +25,11 @@ func New(ctx context.Context) (*runtime.Scheme, error) {
 		return nil, errors.Wrap(err, "unable to configure certificate-manager scheme")
 	}
 
+	err = routev1.AddToScheme(scheme)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to configure OpenShift route scheme")
+	}
+
 	err = goharborv1alpha1.AddToScheme(scheme)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to configure OVH scheme")
@@ -7,7 +7,6 @@ import (
 	kit_log "github.com/go-kit/kit/log"
 	jaeger "github.com/jaegertracing/jaeger-lib/client/log/go-kit"
 	"github.com/opentracing/opentracing-go"
-	"github.com/ovh/configstore"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	jaeger_client "github.com/uber/jaeger-client-go"
@@ -30,31 +29,28 @@ func Init(jaegerConfig *jaeger_cnf.Configuration, logger jaeger_client.Logger, m
 	return con, err
 }
 
-func New(ctx context.Context, name, version string) (io.Closer, error) {
-	var jaegerConfig *jaeger_cnf.Configuration
-
+// New initializes the global tracer, configured from the JAEGER_* environment variables when jaegerConfig is nil.
+func New(ctx context.Context, name, version string, jaegerConfig *jaeger_cnf.Configuration) (io.Closer, error) {
 	log := logger.Get(ctx)
 	traceLogger := ctrl.Log.WithName("tracing").WithName("jaeger")
 
-	item, err := configstore.Filter().
-		Slice("jaeger").
-		Unmarshal(func() interface{} { return &jaeger_cnf.Configuration{} }).
-		GetFirstItem()
-	if err == nil {
-		config, err := item.Unmarshaled()
-		if err != nil {
-			return nil, errors.Wrap(err, "invalid configuration")
-		}
+	if jaegerConfig == nil {
+		var err error
 
-		jaegerConfig = config.(*jaeger_cnf.Configuration)
-	} else {
 		jaegerConfig, err = jaeger_cnf.FromEnv()
 		if err != nil {
 			return nil, errors.Wrap(err, "unable to configure from env")
 		}
+	} else {
+		config := *jaegerConfig
+		jaegerConfig = &config
+	}
+
+	if jaegerConfig.Reporter == nil {
+		jaegerConfig.Reporter = &jaeger_cnf.ReporterConfig{}
 	}
 
-	jaegerConfig.Tags = append(jaegerConfig.Tags, opentracing.Tag{
+	jaegerConfig.Tags = append(append([]opentracing.Tag(nil), jaegerConfig.Tags...), opentracing.Tag{
 		Key:   "version",
 		Value: version,
 	})